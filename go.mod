@@ -11,15 +11,22 @@ require (
 	github.com/go-playground/locales v0.14.1
 	github.com/go-playground/universal-translator v0.18.0
 	github.com/go-playground/validator/v10 v10.11.0
+	github.com/go-sql-driver/mysql v1.7.0
 	github.com/imdario/mergo v0.3.13
 	github.com/jackc/fake v0.0.0-20150926172116-812a484cc733 // indirect
+	github.com/jackc/pgconn v1.12.1
+	github.com/jackc/pgx/v4 v4.16.1
+	github.com/microsoft/go-mssqldb v0.19.0
 	github.com/newrelic/go-agent/v3/integrations/nrmysql v1.2.2
 	github.com/newrelic/go-agent/v3/integrations/nrpgx v1.0.0
 	github.com/smartystreets/goconvey v1.6.4
-	github.com/stretchr/testify v1.8.0
-	golang.org/x/crypto v0.0.0-20221005025214-4161e89ecf1b
+	github.com/stretchr/objx v0.5.2 // indirect
+	github.com/stretchr/testify v1.8.4
+	github.com/xuri/excelize/v2 v2.9.0
+	golang.org/x/crypto v0.28.0
 	gorm.io/driver/mysql v1.4.7
 	gorm.io/driver/postgres v1.3.8
+	gorm.io/driver/sqlite v1.4.4
 	gorm.io/driver/sqlserver v1.4.2
 	gorm.io/gorm v1.24.6
 )
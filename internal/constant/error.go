@@ -5,4 +5,17 @@ import "errors"
 var (
 	ErrEmailAlreadyExist       = errors.New("email already exist")
 	ErrMobilePhoneAlreadyExist = errors.New("mobile phone already exist")
+	ErrVersionMismatch         = errors.New("version mismatch")
+	ErrImportJobNotFound       = errors.New("import job not found")
+	ErrMergeIntoItself         = errors.New("customer can't be merged into itself")
+	ErrDefaultAddressRequired  = errors.New("customer must have a default address")
+	ErrLoyaltyReferenceExists  = errors.New("loyalty points already earned by the reference")
+	ErrInsufficientPoints      = errors.New("insufficient loyalty points")
+	ErrSegmentNameExists       = errors.New("segment name already exist")
+	ErrSegmentNotFound         = errors.New("segment not found")
+	ErrSegmentNotStatic        = errors.New("segment members are evaluated from its rule")
+	ErrSegmentNotDynamic       = errors.New("segment has no rule to evaluate")
+	ErrWalletReferenceExists   = errors.New("wallet entry already recorded for the reference")
+	ErrInsufficientBalance     = errors.New("insufficient wallet balance")
+	ErrInvalidConsentChannel   = errors.New("invalid consent channel")
 )
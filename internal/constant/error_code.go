@@ -1,10 +1,17 @@
 package constant
 
 const (
-	DataAlreadyExistErrorCode = "DATA_ALREADY_EXIST"
-	DataNotFoundErrorCode     = "DATA_NOT_FOUND"
-	DataValidationErrorCode   = "DATA_VALIDATION_ERROR"
-	InvalidJsonErrorCode      = "INVALID_JSON"
-	InvalidPathParamErrorCode = "INVALID_PATH_PARAM"
-	ServerErrorCode           = "SERVER_ERROR"
+	ConflictErrorCode             = "CONFLICT"
+	DataAlreadyExistErrorCode     = "DATA_ALREADY_EXIST"
+	DataNotFoundErrorCode         = "DATA_NOT_FOUND"
+	DataValidationErrorCode       = "DATA_VALIDATION_ERROR"
+	ForbiddenErrorCode            = "FORBIDDEN"
+	InvalidFileErrorCode          = "INVALID_FILE"
+	InvalidJsonErrorCode          = "INVALID_JSON"
+	InvalidPathParamErrorCode     = "INVALID_PATH_PARAM"
+	InvalidQueryParamErrorCode    = "INVALID_QUERY_PARAM"
+	PreconditionFailedErrorCode   = "PRECONDITION_FAILED"
+	PreconditionRequiredErrorCode = "PRECONDITION_REQUIRED"
+	ServerErrorCode               = "SERVER_ERROR"
+	UnauthorizedErrorCode         = "UNAUTHORIZED"
 )
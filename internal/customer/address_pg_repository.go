@@ -0,0 +1,18 @@
+package customer
+
+import (
+	"context"
+	"github.com/alpakih/point-of-sales/internal/domain"
+)
+
+// AddressPgRepository stores the addresses of the customers, the methods are
+// scoped to the customer owning the addresses.
+type AddressPgRepository interface {
+	LockCustomer(ctx context.Context, customerID int) error
+	Create(ctx context.Context, entity *domain.CustomerAddress) error
+	Update(ctx context.Context, entity domain.CustomerAddress) error
+	FindAddressByID(ctx context.Context, customerID, id int) (domain.CustomerAddress, error)
+	FindAddresses(ctx context.Context, customerID int) ([]domain.CustomerAddress, error)
+	ClearDefault(ctx context.Context, customerID int) error
+	Delete(ctx context.Context, customerID, id int) error
+}
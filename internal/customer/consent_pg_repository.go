@@ -0,0 +1,15 @@
+package customer
+
+import (
+	"context"
+	"github.com/alpakih/point-of-sales/internal/domain"
+)
+
+// ConsentPgRepository stores the marketing consent changes of the customers,
+// changes are appended and never changed.
+type ConsentPgRepository interface {
+	LockCustomer(ctx context.Context, customerID int) error
+	FindConsents(ctx context.Context, customerID int) ([]domain.CustomerConsent, error)
+	Append(ctx context.Context, consents []domain.CustomerConsent) error
+	FindConsentedCustomerIDs(ctx context.Context, channel string, customerIDs []int) ([]int, error)
+}
@@ -0,0 +1,181 @@
+package http
+
+import (
+	"errors"
+	"github.com/alpakih/point-of-sales/internal/constant"
+	"github.com/alpakih/point-of-sales/internal/customer"
+	"github.com/alpakih/point-of-sales/pkg/beegoresp"
+	"github.com/alpakih/point-of-sales/pkg/validator"
+	beego "github.com/beego/beego/v2/server/web"
+	"github.com/beego/i18n"
+	"gorm.io/gorm"
+	"net/http"
+)
+
+// CustomerAddressHandler handles the addresses of the customers.
+type CustomerAddressHandler struct {
+	customerController
+	CustomerAddressUseCase customer.AddressUseCase
+}
+
+func NewCustomerAddressHandler(addressUseCase customer.AddressUseCase) {
+	handler := &CustomerAddressHandler{
+		CustomerAddressUseCase: addressUseCase,
+	}
+	beego.Router("/api/v1/customer/:id/addresses", handler, "get:GetAddresses")
+	beego.Router("/api/v1/customer/:id/addresses", handler, "post:StoreAddress")
+	beego.Router("/api/v1/customer/:id/addresses/:addressId", handler, "get:GetAddressByID")
+	beego.Router("/api/v1/customer/:id/addresses/:addressId", handler, "put:UpdateAddress")
+	beego.Router("/api/v1/customer/:id/addresses/:addressId", handler, "delete:DeleteAddress")
+}
+
+// GetAddresses returns the addresses of the customer, the default address first.
+func (h *CustomerAddressHandler) GetAddresses() {
+	customerID, ok := h.paramID(":id")
+	if !ok {
+		return
+	}
+
+	if addresses, err := h.CustomerAddressUseCase.GetAddresses(h.Ctx.Request.Context(), customerID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			h.ResponseError(h.Ctx, http.StatusNotFound, constant.DataNotFoundErrorCode, i18n.Tr(h.Lang, "message.errorDataNotFound"))
+			return
+		}
+		h.ResponseError(h.Ctx, http.StatusInternalServerError, constant.ServerErrorCode, i18n.Tr(h.Lang, "message.errorServer"))
+		return
+	} else {
+		h.Ok(h.Ctx, addresses)
+		return
+	}
+}
+
+func (h *CustomerAddressHandler) GetAddressByID() {
+	customerID, ok := h.paramID(":id")
+	if !ok {
+		return
+	}
+	id, ok := h.paramID(":addressId")
+	if !ok {
+		return
+	}
+
+	if address, err := h.CustomerAddressUseCase.GetAddressByID(h.Ctx.Request.Context(), customerID, id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			h.ResponseError(h.Ctx, http.StatusNotFound, constant.DataNotFoundErrorCode, i18n.Tr(h.Lang, "message.errorDataNotFound"))
+			return
+		}
+		h.ResponseError(h.Ctx, http.StatusInternalServerError, constant.ServerErrorCode, i18n.Tr(h.Lang, "message.errorServer"))
+		return
+	} else {
+		h.Ok(h.Ctx, address)
+		return
+	}
+}
+
+// StoreAddress adds an address to the customer, the first address is the default
+// address regardless of is_default.
+func (h *CustomerAddressHandler) StoreAddress() {
+	var request customer.AddressRequest
+
+	customerID, ok := h.paramID(":id")
+	if !ok {
+		return
+	}
+
+	if err := h.BindJSON(&request); err != nil {
+		if h.responseInvalidJSON(err) {
+			return
+		}
+		h.ResponseError(h.Ctx, http.StatusInternalServerError, constant.ServerErrorCode, i18n.Tr(h.Lang, "message.errorServer"))
+		return
+	}
+
+	if err := validator.Validate.ValidateStruct(request); err != nil {
+		h.ResponseValidationError(h.Ctx, http.StatusUnprocessableEntity, constant.DataValidationErrorCode, i18n.Tr(h.Lang, "message.errorDataValidation"), err)
+		return
+	}
+
+	if address, err := h.CustomerAddressUseCase.StoreAddress(h.Ctx.Request.Context(), customerID, request); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			h.ResponseError(h.Ctx, http.StatusNotFound, constant.DataNotFoundErrorCode, i18n.Tr(h.Lang, "message.errorDataNotFound"))
+			return
+		}
+		h.ResponseError(h.Ctx, http.StatusInternalServerError, constant.ServerErrorCode, i18n.Tr(h.Lang, "message.errorServer"))
+		return
+	} else {
+		h.Ok(h.Ctx, address)
+		return
+	}
+}
+
+// UpdateAddress replaces an address of the customer, the default address stays
+// the default until another address is made the default.
+func (h *CustomerAddressHandler) UpdateAddress() {
+	var request customer.AddressRequest
+
+	customerID, ok := h.paramID(":id")
+	if !ok {
+		return
+	}
+	id, ok := h.paramID(":addressId")
+	if !ok {
+		return
+	}
+
+	if err := h.BindJSON(&request); err != nil {
+		if h.responseInvalidJSON(err) {
+			return
+		}
+		h.ResponseError(h.Ctx, http.StatusInternalServerError, constant.ServerErrorCode, i18n.Tr(h.Lang, "message.errorServer"))
+		return
+	}
+
+	if err := validator.Validate.ValidateStruct(request); err != nil {
+		h.ResponseValidationError(h.Ctx, http.StatusUnprocessableEntity, constant.DataValidationErrorCode, i18n.Tr(h.Lang, "message.errorDataValidation"), err)
+		return
+	}
+
+	if address, err := h.CustomerAddressUseCase.UpdateAddress(h.Ctx.Request.Context(), customerID, id, request); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			h.ResponseError(h.Ctx, http.StatusNotFound, constant.DataNotFoundErrorCode, i18n.Tr(h.Lang, "message.errorDataNotFound"))
+			return
+		}
+		if errors.Is(err, constant.ErrDefaultAddressRequired) {
+			h.ResponseError(h.Ctx, http.StatusUnprocessableEntity, constant.DataValidationErrorCode, i18n.Tr(h.Lang, "message.errorDataValidation"), beegoresp.DetailErrors{
+				Target:      "is_default",
+				Reason:      "required",
+				Description: i18n.Tr(h.Lang, "message.errorDefaultAddressRequired"),
+			})
+			return
+		}
+		h.ResponseError(h.Ctx, http.StatusInternalServerError, constant.ServerErrorCode, i18n.Tr(h.Lang, "message.errorServer"))
+		return
+	} else {
+		h.Ok(h.Ctx, address)
+		return
+	}
+}
+
+// DeleteAddress deletes an address of the customer, the oldest remaining address
+// becomes the default when the default address is deleted.
+func (h *CustomerAddressHandler) DeleteAddress() {
+	customerID, ok := h.paramID(":id")
+	if !ok {
+		return
+	}
+	id, ok := h.paramID(":addressId")
+	if !ok {
+		return
+	}
+
+	if err := h.CustomerAddressUseCase.DeleteAddress(h.Ctx.Request.Context(), customerID, id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			h.ResponseError(h.Ctx, http.StatusNotFound, constant.DataNotFoundErrorCode, i18n.Tr(h.Lang, "message.errorDataNotFound"))
+			return
+		}
+		h.ResponseError(h.Ctx, http.StatusInternalServerError, constant.ServerErrorCode, i18n.Tr(h.Lang, "message.errorServer"))
+		return
+	}
+	h.Ok(h.Ctx, nil)
+	return
+}
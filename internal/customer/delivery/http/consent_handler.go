@@ -0,0 +1,97 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"github.com/alpakih/point-of-sales/internal/constant"
+	"github.com/alpakih/point-of-sales/internal/customer"
+	"github.com/alpakih/point-of-sales/internal/domain"
+	"github.com/alpakih/point-of-sales/pkg/beegoresp"
+	"github.com/alpakih/point-of-sales/pkg/validator"
+	beego "github.com/beego/beego/v2/server/web"
+	"github.com/beego/i18n"
+	"gorm.io/gorm"
+	"net/http"
+	"strings"
+)
+
+// CustomerConsentHandler handles the marketing consents of the customers.
+type CustomerConsentHandler struct {
+	customerController
+	CustomerConsentUseCase customer.ConsentUseCase
+}
+
+func NewCustomerConsentHandler(consentUseCase customer.ConsentUseCase) {
+	handler := &CustomerConsentHandler{
+		CustomerConsentUseCase: consentUseCase,
+	}
+	beego.Router("/api/v1/customer/:id/consents", handler, "get:GetConsents")
+	beego.Router("/api/v1/customer/:id/consents", handler, "put:UpdateConsents")
+}
+
+// GetConsents returns the marketing consents of the customer on every channel
+// and their history.
+func (h *CustomerConsentHandler) GetConsents() {
+	customerID, ok := h.paramID(":id")
+	if !ok {
+		return
+	}
+
+	if consents, err := h.CustomerConsentUseCase.GetConsents(h.Ctx.Request.Context(), customerID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			h.ResponseError(h.Ctx, http.StatusNotFound, constant.DataNotFoundErrorCode, i18n.Tr(h.Lang, "message.errorDataNotFound"))
+			return
+		}
+		h.ResponseError(h.Ctx, http.StatusInternalServerError, constant.ServerErrorCode, i18n.Tr(h.Lang, "message.errorServer"))
+		return
+	} else {
+		h.Ok(h.Ctx, consents)
+		return
+	}
+}
+
+// UpdateConsents grants or withdraws the marketing consents of the customer on
+// the channels of the request, the other channels are left unchanged.
+func (h *CustomerConsentHandler) UpdateConsents() {
+	var request customer.ConsentRequest
+
+	customerID, ok := h.paramID(":id")
+	if !ok {
+		return
+	}
+
+	if err := h.BindJSON(&request); err != nil {
+		if h.responseInvalidJSON(err) {
+			return
+		}
+		h.ResponseError(h.Ctx, http.StatusInternalServerError, constant.ServerErrorCode, i18n.Tr(h.Lang, "message.errorServer"))
+		return
+	}
+
+	if err := validator.Validate.ValidateStruct(request); err != nil {
+		h.ResponseValidationError(h.Ctx, http.StatusUnprocessableEntity, constant.DataValidationErrorCode, i18n.Tr(h.Lang, "message.errorDataValidation"), err)
+		return
+	}
+	for k, v := range request.Consents {
+		if !domain.IsConsentChannel(v.Channel) {
+			h.ResponseError(h.Ctx, http.StatusUnprocessableEntity, constant.DataValidationErrorCode, i18n.Tr(h.Lang, "message.errorDataValidation"), beegoresp.DetailErrors{
+				Target:      fmt.Sprintf("consents[%d].channel", k),
+				Reason:      "oneof",
+				Description: i18n.Tr(h.Lang, "message.errorConsentChannelNotAllowed", v.Channel, strings.Join(domain.ConsentChannels, ", ")),
+			})
+			return
+		}
+	}
+
+	if consents, err := h.CustomerConsentUseCase.UpdateConsents(h.Ctx.Request.Context(), customerID, request); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			h.ResponseError(h.Ctx, http.StatusNotFound, constant.DataNotFoundErrorCode, i18n.Tr(h.Lang, "message.errorDataNotFound"))
+			return
+		}
+		h.ResponseError(h.Ctx, http.StatusInternalServerError, constant.ServerErrorCode, i18n.Tr(h.Lang, "message.errorServer"))
+		return
+	} else {
+		h.Ok(h.Ctx, consents)
+		return
+	}
+}
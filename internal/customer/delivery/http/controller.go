@@ -0,0 +1,153 @@
+package http
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/alpakih/point-of-sales/internal/constant"
+	"github.com/alpakih/point-of-sales/internal/domain"
+	"github.com/alpakih/point-of-sales/pkg/beegoresp"
+	"github.com/alpakih/point-of-sales/pkg/database"
+	"github.com/alpakih/point-of-sales/pkg/utils"
+	beego "github.com/beego/beego/v2/server/web"
+	"github.com/beego/i18n"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// adminAPIKeyHeader header carrying the API key of the admin endpoints
+const adminAPIKeyHeader = "X-Api-Key"
+
+// customerController is embedded by the customer controllers, it reads the
+// language of the request and writes the responses shared by them.
+type customerController struct {
+	beego.Controller
+	i18n.Locale
+	beegoresp.ApiResponse
+}
+
+func (h *customerController) Prepare() {
+	h.Lang = utils.GetLangVersion(h.Ctx)
+}
+
+// authorizeAdmin checks the admin API key of the request against adminAPIKey,
+// writing the 401 or 403 response when it is missing or doesn't match.
+func (h *customerController) authorizeAdmin(adminAPIKey string) bool {
+	apiKey := h.Ctx.Input.Header(adminAPIKeyHeader)
+	if apiKey == "" {
+		h.ResponseError(h.Ctx, http.StatusUnauthorized, constant.UnauthorizedErrorCode, i18n.Tr(h.Lang, "message.errorMissingApiKey"))
+		return false
+	}
+	if adminAPIKey == "" {
+		h.ResponseError(h.Ctx, http.StatusForbidden, constant.ForbiddenErrorCode, i18n.Tr(h.Lang, "message.errorRequestForbidden"))
+		return false
+	}
+	if subtle.ConstantTimeCompare([]byte(apiKey), []byte(adminAPIKey)) != 1 {
+		h.ResponseError(h.Ctx, http.StatusUnauthorized, constant.UnauthorizedErrorCode, i18n.Tr(h.Lang, "message.errorInvalidApiKey"))
+		return false
+	}
+	return true
+}
+
+// adminKeyID identifies the admin API key of the request by the start of its
+// SHA-256 hash, the key itself isn't recorded.
+func (h *customerController) adminKeyID() string {
+	sum := sha256.Sum256([]byte(h.Ctx.Input.Header(adminAPIKeyHeader)))
+	return fmt.Sprintf("key-%x", sum[:6])
+}
+
+// paramID reads the id of a path parameter, writing the 400 response when it
+// isn't an integer.
+func (h *customerController) paramID(name string) (int, bool) {
+	id, err := strconv.Atoi(h.Ctx.Input.Param(name))
+	if err != nil {
+		if errors.Is(err, strconv.ErrRange) {
+			h.ResponseError(h.Ctx, http.StatusBadRequest, constant.InvalidPathParamErrorCode, i18n.Tr(h.Lang, "message.errorUrlParamOutOfRange"))
+			return 0, false
+		}
+		h.ResponseError(h.Ctx, http.StatusBadRequest, constant.InvalidPathParamErrorCode, i18n.Tr(h.Lang, "message.errorInvalidUrlParam"))
+		return 0, false
+	}
+	return id, true
+}
+
+// responseInvalidJSON writes the 400 response of a malformed request body, it
+// returns false for any other error.
+func (h *customerController) responseInvalidJSON(err error) bool {
+	var (
+		syntaxError           *json.SyntaxError
+		unmarshalTypeError    *json.UnmarshalTypeError
+		invalidUnmarshalError *json.InvalidUnmarshalError
+	)
+
+	switch {
+	case errors.As(err, &syntaxError):
+		h.ResponseError(h.Ctx, http.StatusBadRequest, constant.InvalidJsonErrorCode, i18n.Tr(h.Lang, "message.errorJsonSyntax", syntaxError.Offset))
+	case errors.As(err, &unmarshalTypeError):
+		h.ResponseError(h.Ctx, http.StatusBadRequest, constant.InvalidJsonErrorCode, i18n.Tr(h.Lang, "message.errorUnmarshalType", unmarshalTypeError.Field, unmarshalTypeError.Type))
+	case errors.As(err, &invalidUnmarshalError):
+		h.ResponseError(h.Ctx, http.StatusBadRequest, constant.InvalidJsonErrorCode, i18n.Tr(h.Lang, "message.errorUnmarshal"))
+	default:
+		return false
+	}
+	return true
+}
+
+var filterErrorMessages = map[string]string{
+	database.FilterReasonUnknownField:    "message.errorFilterUnknownField",
+	database.FilterReasonUnknownOperator: "message.errorFilterUnknownOperator",
+	database.FilterReasonInvalidValue:    "message.errorFilterInvalidValue",
+}
+
+// responseInvalidQuery writes the 400 response of an invalid sort, filter,
+// cursor, fields or include query parameter, it returns false for any other error.
+func (h *customerController) responseInvalidQuery(err error) bool {
+	var sortError *database.SortError
+	var filterError *database.FilterError
+	var fieldsetError *utils.FieldsetError
+	switch {
+	case errors.As(err, &sortError):
+		h.ResponseError(h.Ctx, http.StatusBadRequest, constant.InvalidQueryParamErrorCode, i18n.Tr(h.Lang, "message.errorInvalidUrlQueryParam"),
+			beegoresp.DetailErrors{
+				Target:      "orderBy",
+				Reason:      "oneof",
+				Description: i18n.Tr(h.Lang, "message.errorSortFieldNotAllowed", sortError.Field, strings.Join(sortError.Allowed, ", ")),
+			})
+	case errors.As(err, &filterError):
+		h.ResponseError(h.Ctx, http.StatusBadRequest, constant.InvalidQueryParamErrorCode, i18n.Tr(h.Lang, "message.errorInvalidUrlQueryParam"),
+			beegoresp.DetailErrors{
+				Target:      fmt.Sprintf("filter[%s][%s]", filterError.Field, filterError.Operator),
+				Reason:      filterError.Reason,
+				Description: i18n.Tr(h.Lang, filterErrorMessages[filterError.Reason], filterError.Field, filterError.Operator),
+			})
+	case errors.As(err, &fieldsetError):
+		h.ResponseError(h.Ctx, http.StatusBadRequest, constant.InvalidQueryParamErrorCode, i18n.Tr(h.Lang, "message.errorInvalidUrlQueryParam"),
+			beegoresp.DetailErrors{
+				Target:      fieldsetError.Param,
+				Reason:      "oneof",
+				Description: i18n.Tr(h.Lang, "message.errorFieldsetNotAllowed", fieldsetError.Value, strings.Join(fieldsetError.Allowed, ", ")),
+			})
+	case errors.Is(err, database.ErrInvalidCursor):
+		h.ResponseError(h.Ctx, http.StatusBadRequest, constant.InvalidQueryParamErrorCode, i18n.Tr(h.Lang, "message.errorInvalidUrlQueryParam"))
+	case errors.Is(err, constant.ErrSegmentNotFound):
+		h.ResponseError(h.Ctx, http.StatusBadRequest, constant.InvalidQueryParamErrorCode, i18n.Tr(h.Lang, "message.errorInvalidUrlQueryParam"),
+			beegoresp.DetailErrors{
+				Target:      "segment",
+				Reason:      "exists",
+				Description: i18n.Tr(h.Lang, "message.errorSegmentNotFound", h.Ctx.Input.Query("segment")),
+			})
+	case errors.Is(err, constant.ErrInvalidConsentChannel):
+		h.ResponseError(h.Ctx, http.StatusBadRequest, constant.InvalidQueryParamErrorCode, i18n.Tr(h.Lang, "message.errorInvalidUrlQueryParam"),
+			beegoresp.DetailErrors{
+				Target:      "consent",
+				Reason:      "oneof",
+				Description: i18n.Tr(h.Lang, "message.errorConsentChannelNotAllowed", h.Ctx.Input.Query("consent"), strings.Join(domain.ConsentChannels, ", ")),
+			})
+	default:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,128 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"github.com/alpakih/point-of-sales/internal/constant"
+	"github.com/alpakih/point-of-sales/internal/customer"
+	"github.com/alpakih/point-of-sales/pkg/beegoresp"
+	"github.com/alpakih/point-of-sales/pkg/spreadsheet"
+	"github.com/beego/beego/v2/core/logs"
+	beego "github.com/beego/beego/v2/server/web"
+	"github.com/beego/i18n"
+	"net/http"
+	"strconv"
+)
+
+// CustomerExportHandler handles the export of the customers.
+type CustomerExportHandler struct {
+	customerController
+	CustomerUseCase customer.UseCase
+	// AdminAPIKey key required by the ?with_deleted=true export, it is forbidden
+	// when it is empty
+	AdminAPIKey string
+}
+
+func NewCustomerExportHandler(useCase customer.UseCase, adminAPIKey string) {
+	handler := &CustomerExportHandler{
+		CustomerUseCase: useCase,
+		AdminAPIKey:     adminAPIKey,
+	}
+	beego.Router("/api/v1/customers/export", handler, "get:ExportCustomers")
+}
+
+// ExportCustomers downloads the customers as a ?format=csv|xlsx|ndjson file,
+// searched, filtered and ordered like GetCustomers. The columns are selected with
+// ?fields=, the CSV and XLSX headers are in the language of the request. Rows are
+// sent as they are read, an error after the first row truncates the file.
+func (h *CustomerExportHandler) ExportCustomers() {
+	format := h.Ctx.Input.Query("format")
+	if format == "" {
+		format = spreadsheet.FormatCSV
+	}
+	if spreadsheet.ContentType(format) == "" {
+		h.ResponseError(h.Ctx, http.StatusBadRequest, constant.InvalidQueryParamErrorCode, i18n.Tr(h.Lang, "message.errorInvalidUrlQueryParam"), beegoresp.DetailErrors{
+			Target:      "format",
+			Reason:      "oneof",
+			Description: i18n.Tr(h.Lang, "message.errorExportUnsupportedFormat"),
+		})
+		return
+	}
+
+	listQuery, err := customer.GetListQueryFromCtx(h.Ctx)
+	if err != nil {
+		if errors.Is(err, strconv.ErrSyntax) {
+			h.ResponseError(h.Ctx, http.StatusBadRequest, constant.InvalidPathParamErrorCode, i18n.Tr(h.Lang, "message.errorInvalidQueryParam"))
+			return
+		}
+		if errors.Is(err, strconv.ErrRange) {
+			h.ResponseError(h.Ctx, http.StatusBadRequest, constant.InvalidPathParamErrorCode, i18n.Tr(h.Lang, "message.errorQueryParamOutOfRange"))
+			return
+		}
+		if h.responseInvalidQuery(err) {
+			return
+		}
+		h.ResponseError(h.Ctx, http.StatusInternalServerError, constant.ServerErrorCode, i18n.Tr(h.Lang, "message.errorServer"))
+		return
+	}
+	if listQuery.GetWithDeleted() && !h.authorizeAdmin(h.AdminAPIKey) {
+		return
+	}
+
+	columns := listQuery.GetFields()
+	if len(columns) == 0 {
+		columns = customer.ResponseFieldset.Fields()
+	}
+	var labels = make([]string, len(columns))
+	for i, column := range columns {
+		labels[i] = i18n.Tr(h.Lang, "customerExport."+column)
+	}
+
+	var writer spreadsheet.Writer
+	start := func() error {
+		h.Ctx.Output.Header("Content-Type", spreadsheet.ContentType(format))
+		h.Ctx.Output.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"customers.%s\"", format))
+		h.Ctx.Output.SetStatus(http.StatusOK)
+		writer, err = spreadsheet.NewWriter(h.Ctx.ResponseWriter, format, columns, labels)
+		return err
+	}
+
+	err = h.CustomerUseCase.ExportCustomers(h.Ctx.Request.Context(), *listQuery, func(data []customer.Response) error {
+		if writer == nil {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+		for _, response := range data {
+			if err := writer.Write(customer.ResponseFieldset.Values(response, columns)); err != nil {
+				return err
+			}
+		}
+		if err := writer.Flush(); err != nil {
+			return err
+		}
+		h.Ctx.ResponseWriter.Flush()
+		return nil
+	})
+	if err != nil {
+		if writer != nil {
+			logs.Error("customer export interrupted: %v", err)
+			return
+		}
+		if h.responseInvalidQuery(err) {
+			return
+		}
+		h.ResponseError(h.Ctx, http.StatusInternalServerError, constant.ServerErrorCode, i18n.Tr(h.Lang, "message.errorServer"))
+		return
+	}
+
+	if writer == nil {
+		if err := start(); err != nil {
+			logs.Error("customer export failed: %v", err)
+			return
+		}
+	}
+	if err := writer.Close(); err != nil {
+		logs.Error("customer export failed: %v", err)
+	}
+}
@@ -1,7 +1,6 @@
 package http
 
 import (
-	"context"
 	"encoding/json"
 	"errors"
 	"github.com/alpakih/point-of-sales/internal/constant"
@@ -11,53 +10,42 @@ import (
 	"github.com/alpakih/point-of-sales/pkg/validator"
 	beego "github.com/beego/beego/v2/server/web"
 	"github.com/beego/i18n"
+	validatorGo "github.com/go-playground/validator/v10"
 	"gorm.io/gorm"
 	"net/http"
 	"strconv"
 )
 
 type CustomerHandler struct {
-	beego.Controller
-	i18n.Locale
-	beegoresp.ApiResponse
+	customerController
 	CustomerUseCase customer.UseCase
+	// AdminAPIKey key required by the purge and ?with_deleted=true listing, these
+	// are forbidden when it is empty
+	AdminAPIKey string
 }
 
-func NewCustomerHandler(useCase customer.UseCase) {
+func NewCustomerHandler(useCase customer.UseCase, adminAPIKey string) {
 	handler := &CustomerHandler{
 		CustomerUseCase: useCase,
+		AdminAPIKey:     adminAPIKey,
 	}
 	beego.Router("/api/v1/customer", handler, "post:StoreCustomer")
 	beego.Router("/api/v1/customer/:id", handler, "get:GetCustomerByID")
 	beego.Router("/api/v1/customer/:id", handler, "put:UpdateCustomer")
+	beego.Router("/api/v1/customer/:id", handler, "patch:PatchCustomer")
 	beego.Router("/api/v1/customer/:id", handler, "delete:DeleteCustomer")
+	beego.Router("/api/v1/customer/:id/restore", handler, "post:RestoreCustomer")
+	beego.Router("/api/v1/customer/:id/purge", handler, "delete:PurgeCustomer")
+	beego.Router("/api/v1/customer/:id/duplicates", handler, "get:GetDuplicateCandidates")
 	beego.Router("/api/v1/customers", handler, "get:GetCustomers")
-}
-
-func (h *CustomerHandler) Prepare() {
-	h.Lang = utils.GetLangVersion(h.Ctx)
+	beego.Router("/api/v1/customers/merge", handler, "post:MergeCustomers")
 }
 
 func (h *CustomerHandler) StoreCustomer() {
 	var request customer.StoreRequest
 
 	if err := h.Bind(&request); err != nil {
-		var (
-			syntaxError           *json.SyntaxError
-			unmarshalTypeError    *json.UnmarshalTypeError
-			invalidUnmarshalError *json.InvalidUnmarshalError
-		)
-
-		if errors.As(err, &syntaxError) {
-			h.ResponseError(h.Ctx, http.StatusBadRequest, constant.InvalidJsonErrorCode, i18n.Tr(h.Lang, "message.errorJsonSyntax", syntaxError.Offset))
-			return
-		}
-		if errors.As(err, &unmarshalTypeError) {
-			h.ResponseError(h.Ctx, http.StatusBadRequest, constant.InvalidJsonErrorCode, i18n.Tr(h.Lang, "message.errorUnmarshalType", unmarshalTypeError.Field, unmarshalTypeError.Type))
-			return
-		}
-		if errors.As(err, &invalidUnmarshalError) {
-			h.ResponseError(h.Ctx, http.StatusBadRequest, constant.InvalidJsonErrorCode, i18n.Tr(h.Lang, "message.errorUnmarshal"))
+		if h.responseInvalidJSON(err) {
 			return
 		}
 		h.ResponseError(h.Ctx, http.StatusInternalServerError, constant.ServerErrorCode, i18n.Tr(h.Lang, "message.errorServer"))
@@ -98,59 +86,100 @@ func (h *CustomerHandler) StoreCustomer() {
 func (h *CustomerHandler) UpdateCustomer() {
 	var request customer.UpdateRequest
 
-	id, err := strconv.Atoi(h.Ctx.Input.Param(":id"))
-	if err != nil {
-		if errors.Is(err, strconv.ErrSyntax) {
-			h.ResponseError(h.Ctx, http.StatusBadRequest, constant.InvalidPathParamErrorCode, i18n.Tr(h.Lang, "message.errorInvalidUrlParam"))
-			return
-		}
-		if errors.Is(err, strconv.ErrRange) {
-			h.ResponseError(h.Ctx, http.StatusBadRequest, constant.InvalidPathParamErrorCode, i18n.Tr(h.Lang, "message.errorUrlParamOutOfRange"))
+	id, ok := h.paramID(":id")
+	if !ok {
+		return
+	}
+
+	version, ok := h.ifMatchVersion()
+	if !ok {
+		return
+	}
+
+	if err := h.BindJSON(&request); err != nil {
+		if h.responseInvalidJSON(err) {
 			return
 		}
 		h.ResponseError(h.Ctx, http.StatusInternalServerError, constant.ServerErrorCode, i18n.Tr(h.Lang, "message.errorServer"))
 		return
 	}
 
-	if err := h.BindJSON(&request); err != nil {
-		var (
-			syntaxError           *json.SyntaxError
-			unmarshalTypeError    *json.UnmarshalTypeError
-			invalidUnmarshalError *json.InvalidUnmarshalError
-		)
-
-		if errors.As(err, &syntaxError) {
-			h.ResponseError(h.Ctx, http.StatusBadRequest, constant.InvalidJsonErrorCode, i18n.Tr(h.Lang, "message.errorJsonSyntax", syntaxError.Offset))
+	if err := validator.Validate.ValidateStruct(request); err != nil {
+		h.ResponseValidationError(h.Ctx, http.StatusUnprocessableEntity, constant.DataValidationErrorCode, i18n.Tr(h.Lang, "message.errorDataValidation"), err)
+		return
+	}
+
+	if response, err := h.CustomerUseCase.UpdateCustomer(h.Ctx.Request.Context(), request, id, version); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			h.ResponseError(h.Ctx, http.StatusNotFound, constant.DataNotFoundErrorCode, i18n.Tr(h.Lang, "message.errorDataNotFound"))
 			return
 		}
-		if errors.As(err, &unmarshalTypeError) {
-			h.ResponseError(h.Ctx, http.StatusBadRequest, constant.InvalidJsonErrorCode, i18n.Tr(h.Lang, "message.errorUnmarshalType", unmarshalTypeError.Field, unmarshalTypeError.Type))
+		if errors.Is(err, constant.ErrVersionMismatch) {
+			h.ResponseError(h.Ctx, http.StatusPreconditionFailed, constant.PreconditionFailedErrorCode, i18n.Tr(h.Lang, "message.errorPreconditionFailed"))
 			return
 		}
-		if errors.As(err, &invalidUnmarshalError) {
-			h.ResponseError(h.Ctx, http.StatusBadRequest, constant.InvalidJsonErrorCode, i18n.Tr(h.Lang, "message.errorUnmarshal"))
+
+		if errors.Is(err, constant.ErrEmailAlreadyExist) {
+			h.ResponseError(h.Ctx, http.StatusUnprocessableEntity, constant.DataValidationErrorCode, i18n.Tr(h.Lang, "message.errorDataValidation"), beegoresp.DetailErrors{
+				Target:      "email",
+				Reason:      "duplicate",
+				Description: i18n.Tr(h.Lang, "message.errorEmailAlreadyExist", request.Email),
+			})
 			return
 		}
+		if errors.Is(err, constant.ErrMobilePhoneAlreadyExist) {
+			h.ResponseError(h.Ctx, http.StatusUnprocessableEntity, constant.DataValidationErrorCode, i18n.Tr(h.Lang, "message.errorDataValidation"), beegoresp.DetailErrors{
+				Target:      "mobile_phone",
+				Reason:      "duplicate",
+				Description: i18n.Tr(h.Lang, "message.errorMobilePhoneAlreadyExist", request.MobilePhone),
+			})
+			return
+		}
+
 		h.ResponseError(h.Ctx, http.StatusInternalServerError, constant.ServerErrorCode, i18n.Tr(h.Lang, "message.errorServer"))
 		return
+	} else {
+		h.Ctx.Output.Header("ETag", utils.BuildETag(response.Version))
+		h.Ok(h.Ctx, response)
+		return
 	}
+}
 
-	if err := validator.Validate.ValidateStruct(request); err != nil {
-		h.ResponseValidationError(h.Ctx, http.StatusUnprocessableEntity, constant.DataValidationErrorCode, i18n.Tr(h.Lang, "message.errorDataValidation"), err)
+// PatchCustomer updates the customer with a JSON Merge Patch (RFC 7396), members
+// set to null are removed from the customer document.
+func (h *CustomerHandler) PatchCustomer() {
+	id, ok := h.paramID(":id")
+	if !ok {
+		return
+	}
+
+	version, ok := h.ifMatchVersion()
+	if !ok {
 		return
 	}
 
-	if err := h.CustomerUseCase.UpdateCustomer(h.Ctx.Request.Context(), request, id); err != nil {
+	if response, err := h.CustomerUseCase.PatchCustomer(h.Ctx.Request.Context(), h.Ctx.Input.RequestBody, id, version); err != nil {
+		var validationErrors validatorGo.ValidationErrors
+		if errors.As(err, &validationErrors) {
+			h.ResponseValidationError(h.Ctx, http.StatusUnprocessableEntity, constant.DataValidationErrorCode, i18n.Tr(h.Lang, "message.errorDataValidation"), validationErrors)
+			return
+		}
+		if h.responseInvalidJSON(err) {
+			return
+		}
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			h.ResponseError(h.Ctx, http.StatusNotFound, constant.DataNotFoundErrorCode, i18n.Tr(h.Lang, "message.errorDataNotFound"))
 			return
 		}
-
+		if errors.Is(err, constant.ErrVersionMismatch) {
+			h.ResponseError(h.Ctx, http.StatusPreconditionFailed, constant.PreconditionFailedErrorCode, i18n.Tr(h.Lang, "message.errorPreconditionFailed"))
+			return
+		}
 		if errors.Is(err, constant.ErrEmailAlreadyExist) {
 			h.ResponseError(h.Ctx, http.StatusUnprocessableEntity, constant.DataValidationErrorCode, i18n.Tr(h.Lang, "message.errorDataValidation"), beegoresp.DetailErrors{
 				Target:      "email",
 				Reason:      "duplicate",
-				Description: i18n.Tr(h.Lang, "message.errorEmailAlreadyExist", request.Email),
+				Description: i18n.Tr(h.Lang, "message.errorEmailAlreadyExist", patchValue(h.Ctx.Input.RequestBody, "email")),
 			})
 			return
 		}
@@ -158,21 +187,23 @@ func (h *CustomerHandler) UpdateCustomer() {
 			h.ResponseError(h.Ctx, http.StatusUnprocessableEntity, constant.DataValidationErrorCode, i18n.Tr(h.Lang, "message.errorDataValidation"), beegoresp.DetailErrors{
 				Target:      "mobile_phone",
 				Reason:      "duplicate",
-				Description: i18n.Tr(h.Lang, "message.errorMobilePhoneAlreadyExist", request.MobilePhone),
+				Description: i18n.Tr(h.Lang, "message.errorMobilePhoneAlreadyExist", patchValue(h.Ctx.Input.RequestBody, "mobile_phone")),
 			})
 			return
 		}
 
 		h.ResponseError(h.Ctx, http.StatusInternalServerError, constant.ServerErrorCode, i18n.Tr(h.Lang, "message.errorServer"))
 		return
+	} else {
+		h.Ctx.Output.Header("ETag", utils.BuildETag(response.Version))
+		h.Ok(h.Ctx, response)
+		return
 	}
-	h.Ok(h.Ctx, request)
-	return
 }
 
 func (h *CustomerHandler) GetCustomers() {
 
-	paginationQuery, err := utils.GetPaginationFromCtx(h.Ctx)
+	listQuery, err := customer.GetListQueryFromCtx(h.Ctx)
 	if err != nil {
 		if errors.Is(err, strconv.ErrSyntax) {
 			h.ResponseError(h.Ctx, http.StatusBadRequest, constant.InvalidPathParamErrorCode, i18n.Tr(h.Lang, "message.errorInvalidQueryParam"))
@@ -182,70 +213,137 @@ func (h *CustomerHandler) GetCustomers() {
 			h.ResponseError(h.Ctx, http.StatusBadRequest, constant.InvalidPathParamErrorCode, i18n.Tr(h.Lang, "message.errorQueryParamOutOfRange"))
 			return
 		}
+		if h.responseInvalidQuery(err) {
+			return
+		}
 		h.ResponseError(h.Ctx, http.StatusInternalServerError, constant.ServerErrorCode, i18n.Tr(h.Lang, "message.errorServer"))
 		return
 	}
 
-	if result, err := h.CustomerUseCase.GetCustomers(
-		context.WithValue(h.Ctx.Request.Context(), "requestCtx", h.Ctx.Request),
-		paginationQuery.GetPage(),
-		paginationQuery.GetSize(),
-		paginationQuery.GetSearch(),
-		paginationQuery.GetOrderBy()); err != nil {
+	if listQuery.GetWithDeleted() && !h.authorizeAdmin(h.AdminAPIKey) {
+		return
+	}
+
+	if result, err := h.CustomerUseCase.GetCustomers(h.Ctx.Request.Context(), *listQuery); err != nil {
+		if h.responseInvalidQuery(err) {
+			return
+		}
 		h.ResponseError(h.Ctx, http.StatusInternalServerError, constant.ServerErrorCode, i18n.Tr(h.Lang, "message.errorServer"))
 		return
 	} else {
-		h.OkWithPagination(h.Ctx, result.Pagination, result.Data)
+		h.OkWithPagination(h.Ctx, result.Pagination, customer.NewCustomerMapper().ToSparseResponses(result.Data, listQuery.FieldsetQuery))
 		return
 	}
 }
 
 func (h *CustomerHandler) GetCustomerByID() {
 
-	id, err := strconv.Atoi(h.Ctx.Input.Param(":id"))
-	if err != nil {
-		if errors.Is(err, strconv.ErrSyntax) {
-			h.ResponseError(h.Ctx, http.StatusBadRequest, constant.InvalidPathParamErrorCode, i18n.Tr(h.Lang, "message.errorInvalidUrlParam"))
+	id, ok := h.paramID(":id")
+	if !ok {
+		return
+	}
+
+	fieldset := utils.GetFieldsetFromCtx(h.Ctx)
+	if response, err := h.CustomerUseCase.GetCustomerByID(h.Ctx.Request.Context(), id, fieldset); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			h.ResponseError(h.Ctx, http.StatusNotFound, constant.DataNotFoundErrorCode, i18n.Tr(h.Lang, "message.errorDataNotFound"))
 			return
 		}
-		if errors.Is(err, strconv.ErrRange) {
-			h.ResponseError(h.Ctx, http.StatusBadRequest, constant.InvalidPathParamErrorCode, i18n.Tr(h.Lang, "message.errorUrlParamOutOfRange"))
+		if h.responseInvalidQuery(err) {
 			return
 		}
 		h.ResponseError(h.Ctx, http.StatusInternalServerError, constant.ServerErrorCode, i18n.Tr(h.Lang, "message.errorServer"))
 		return
+	} else {
+		sparse := customer.NewCustomerMapper().ToSparseResponse(*response, fieldset)
+		etag := utils.BuildETag(response.Version)
+		if len(fieldset.Fields) > 0 || len(fieldset.Include) > 0 {
+			if etag, err = utils.BuildRepresentationETag(response.Version, sparse); err != nil {
+				h.ResponseError(h.Ctx, http.StatusInternalServerError, constant.ServerErrorCode, i18n.Tr(h.Lang, "message.errorServer"))
+				return
+			}
+		}
+		h.Ctx.Output.Header("ETag", etag)
+		if utils.MatchETag(h.Ctx.Input.Header("If-None-Match"), etag) {
+			h.Ctx.ResponseWriter.WriteHeader(http.StatusNotModified)
+			return
+		}
+		h.Ok(h.Ctx, sparse)
+		return
 	}
+}
+
+func (h *CustomerHandler) DeleteCustomer() {
 
-	if response, err := h.CustomerUseCase.GetCustomerByID(h.Ctx.Request.Context(), id); err != nil {
+	id, ok := h.paramID(":id")
+	if !ok {
+		return
+	}
+
+	if err := h.CustomerUseCase.DeleteCustomer(h.Ctx.Request.Context(), id); err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			h.ResponseError(h.Ctx, http.StatusNotFound, constant.DataNotFoundErrorCode, i18n.Tr(h.Lang, "message.errorDataNotFound"))
 			return
 		}
 		h.ResponseError(h.Ctx, http.StatusInternalServerError, constant.ServerErrorCode, i18n.Tr(h.Lang, "message.errorServer"))
 		return
-	} else {
-		h.Ok(h.Ctx, response)
-		return
 	}
+	h.Ok(h.Ctx, nil)
+	return
 }
 
-func (h *CustomerHandler) DeleteCustomer() {
+// RestoreCustomer restores a soft-deleted customer, it fails with 409 when its
+// email or mobile phone has been registered by another customer meanwhile.
+func (h *CustomerHandler) RestoreCustomer() {
 
-	id, err := strconv.Atoi(h.Ctx.Input.Param(":id"))
-	if err != nil {
-		if errors.Is(err, strconv.ErrSyntax) {
-			h.ResponseError(h.Ctx, http.StatusBadRequest, constant.InvalidPathParamErrorCode, i18n.Tr(h.Lang, "message.errorInvalidUrlParam"))
+	id, ok := h.paramID(":id")
+	if !ok {
+		return
+	}
+
+	if response, err := h.CustomerUseCase.RestoreCustomer(h.Ctx.Request.Context(), id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			h.ResponseError(h.Ctx, http.StatusNotFound, constant.DataNotFoundErrorCode, i18n.Tr(h.Lang, "message.errorDataNotFound"))
 			return
 		}
-		if errors.Is(err, strconv.ErrRange) {
-			h.ResponseError(h.Ctx, http.StatusBadRequest, constant.InvalidPathParamErrorCode, i18n.Tr(h.Lang, "message.errorUrlParamOutOfRange"))
+		if errors.Is(err, constant.ErrEmailAlreadyExist) {
+			h.ResponseError(h.Ctx, http.StatusConflict, constant.DataAlreadyExistErrorCode, i18n.Tr(h.Lang, "message.errorRestoreConflict"), beegoresp.DetailErrors{
+				Target:      "email",
+				Reason:      "duplicate",
+				Description: i18n.Tr(h.Lang, "message.errorRestoreEmailConflict"),
+			})
+			return
+		}
+		if errors.Is(err, constant.ErrMobilePhoneAlreadyExist) {
+			h.ResponseError(h.Ctx, http.StatusConflict, constant.DataAlreadyExistErrorCode, i18n.Tr(h.Lang, "message.errorRestoreConflict"), beegoresp.DetailErrors{
+				Target:      "mobile_phone",
+				Reason:      "duplicate",
+				Description: i18n.Tr(h.Lang, "message.errorRestoreMobilePhoneConflict"),
+			})
 			return
 		}
 		h.ResponseError(h.Ctx, http.StatusInternalServerError, constant.ServerErrorCode, i18n.Tr(h.Lang, "message.errorServer"))
 		return
+	} else {
+		h.Ctx.Output.Header("ETag", utils.BuildETag(response.Version))
+		h.Ok(h.Ctx, response)
+		return
 	}
+}
 
-	if err := h.CustomerUseCase.DeleteCustomer(h.Ctx.Request.Context(), id); err != nil {
+// PurgeCustomer permanently deletes a customer, it requires the admin API key.
+func (h *CustomerHandler) PurgeCustomer() {
+
+	id, ok := h.paramID(":id")
+	if !ok {
+		return
+	}
+
+	if !h.authorizeAdmin(h.AdminAPIKey) {
+		return
+	}
+
+	if err := h.CustomerUseCase.PurgeCustomer(h.Ctx.Request.Context(), id); err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			h.ResponseError(h.Ctx, http.StatusNotFound, constant.DataNotFoundErrorCode, i18n.Tr(h.Lang, "message.errorDataNotFound"))
 			return
@@ -256,3 +354,28 @@ func (h *CustomerHandler) DeleteCustomer() {
 	h.Ok(h.Ctx, nil)
 	return
 }
+
+// ifMatchVersion reads the resource version of the If-Match header, writing the
+// 428 or 412 response when it is missing or invalid. If-Match: * skips the
+// version check with utils.AnyVersion.
+func (h *CustomerHandler) ifMatchVersion() (int, bool) {
+	ifMatch := h.Ctx.Input.Header("If-Match")
+	if ifMatch == "" {
+		h.ResponseError(h.Ctx, http.StatusPreconditionRequired, constant.PreconditionRequiredErrorCode, i18n.Tr(h.Lang, "message.errorPreconditionRequired"))
+		return 0, false
+	}
+	version, err := utils.ParseETag(ifMatch)
+	if err != nil {
+		h.ResponseError(h.Ctx, http.StatusPreconditionFailed, constant.PreconditionFailedErrorCode, i18n.Tr(h.Lang, "message.errorPreconditionFailed"))
+		return 0, false
+	}
+	return version, true
+}
+
+// patchValue returns a string member of a merge patch for the error messages.
+func patchValue(patch []byte, name string) string {
+	var members map[string]interface{}
+	_ = json.Unmarshal(patch, &members)
+	value, _ := members[name].(string)
+	return value
+}
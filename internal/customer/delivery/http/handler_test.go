@@ -1,12 +1,16 @@
 package http
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"github.com/alpakih/point-of-sales/internal/customer"
 	"github.com/alpakih/point-of-sales/internal/customer/mocks"
+	"github.com/alpakih/point-of-sales/internal/customer/repository/pg"
+	"github.com/alpakih/point-of-sales/internal/customer/usecase"
+	"github.com/alpakih/point-of-sales/internal/domain"
+	"github.com/alpakih/point-of-sales/pkg/database"
 	beego "github.com/beego/beego/v2/server/web"
-	"github.com/beego/i18n"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"net/http"
@@ -31,7 +35,7 @@ func TestCustomerHandler_StoreCustomer(t *testing.T) {
 	mockDataCustomer := customer.StoreRequest{
 		Name:        "Test",
 		Email:       "email@test.com",
-		MobilePhone: "087666777656",
+		MobilePhone: "087766777656",
 		Password:    "123123",
 	}
 
@@ -53,7 +57,6 @@ func TestCustomerHandler_StoreCustomer(t *testing.T) {
 		h := beego.NewControllerRegister()
 
 		handler := &CustomerHandler{
-			Locale:          i18n.Locale{Lang: "id"},
 			CustomerUseCase: mockUCase,
 		}
 
@@ -65,3 +68,181 @@ func TestCustomerHandler_StoreCustomer(t *testing.T) {
 		mockUCase.AssertExpectations(t)
 	}
 }
+
+func TestCustomerHandler_StoreCustomerInMemory(t *testing.T) {
+	db, err := database.NewInMemory(&domain.Customer{})
+	assert.NoError(t, err)
+	defer db.Close()
+	assert.NoError(t, pg.MigrateIndexes(context.TODO(), db.Conn()))
+
+	h := beego.NewControllerRegister()
+
+	handler := &CustomerHandler{
+		CustomerUseCase: usecase.NewCustomerUseCase(pg.NewCustomerPgRepository(db.Conn()), database.NewTxManager(db.Conn())),
+	}
+
+	h.Add("/api/v1/customer", handler, beego.WithRouterMethods(handler, "post:StoreCustomer"))
+	h.Add("/api/v1/customer/:id", handler, beego.WithRouterMethods(handler, "get:GetCustomerByID"))
+
+	store := func(body string) *httptest.ResponseRecorder {
+		r, err := http.NewRequest("POST", "/api/v1/customer", strings.NewReader(body))
+		assert.NoError(t, err)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	w := store(`{"name":"Alice","email":"alice@test.com","mobile_phone":"0812-3456-7890","password":"123123"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var entity domain.Customer
+	assert.NoError(t, db.Conn().First(&entity, "email = ?", "alice@test.com").Error)
+	assert.Equal(t, "+6281234567890", entity.MobilePhone)
+
+	// the same mobile phone written otherwise is a duplicate
+	w = store(`{"name":"Alice","email":"alice2@test.com","mobile_phone":"+62 812 3456 7890","password":"123123"}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Contains(t, w.Body.String(), "mobile_phone")
+
+	r, err := http.NewRequest("GET", fmt.Sprintf("/api/v1/customer/%d", entity.ID), nil)
+	assert.NoError(t, err)
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"mobilePhone":"+6281234567890"`)
+}
+
+func TestCustomerHandler_GetCustomerByIDETag(t *testing.T) {
+	mockUCase := new(mocks.UseCase)
+	mockUCase.On("GetCustomerByID", mock.Anything, 1, mock.AnythingOfType("utils.FieldsetQuery")).
+		Return(&customer.Response{ID: 1, Name: "Alice", Version: 3}, nil).Times(4)
+	mockUCase.On("GetCustomerByID", mock.Anything, 1, mock.AnythingOfType("utils.FieldsetQuery")).
+		Return(&customer.Response{ID: 1, Name: "Alice", Version: 3, Addresses: &[]customer.AddressResponse{{ID: 1, Label: "Home"}}}, nil).Twice()
+
+	h := beego.NewControllerRegister()
+
+	handler := &CustomerHandler{
+		CustomerUseCase: mockUCase,
+	}
+
+	h.Add("/api/v1/customer/:id", handler, beego.WithRouterMethods(handler, "get:GetCustomerByID"))
+
+	get := func(query, ifNoneMatch string) *httptest.ResponseRecorder {
+		r, err := http.NewRequest("GET", "/api/v1/customer/1"+query, nil)
+		assert.NoError(t, err)
+		if ifNoneMatch != "" {
+			r.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	w := get("", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"3"`, w.Header().Get("ETag"))
+
+	// the tag of a sparse representation does not validate the full one
+	w = get("?fields=name", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	sparseETag := w.Header().Get("ETag")
+	assert.True(t, strings.HasPrefix(sparseETag, `W/"3-`))
+	w = get("", sparseETag)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// an address added without bumping the version changes the tag of ?include=addresses
+	w = get("?include=addresses", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	includeETag := w.Header().Get("ETag")
+	w = get("?include=addresses", includeETag)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotEqual(t, includeETag, w.Header().Get("ETag"))
+	w = get("?include=addresses", w.Header().Get("ETag"))
+	assert.Equal(t, http.StatusNotModified, w.Code)
+
+	mockUCase.AssertExpectations(t)
+}
+
+func TestCustomerHandler_GetDuplicateCandidates(t *testing.T) {
+	mockUCase := new(mocks.UseCase)
+
+	for _, id := range []string{"abc", "99999999999999999999"} {
+		r, err := http.NewRequest("GET", "/api/v1/customer/"+id+"/duplicates", nil)
+		assert.NoError(t, err)
+
+		w := httptest.NewRecorder()
+
+		h := beego.NewControllerRegister()
+
+		handler := &CustomerHandler{
+			CustomerUseCase: mockUCase,
+		}
+
+		h.Add("/api/v1/customer/:id/duplicates", handler, beego.WithRouterMethods(handler, "get:GetDuplicateCandidates"))
+
+		h.ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	}
+	mockUCase.AssertExpectations(t)
+}
+
+func TestCustomerHandler_ErasePersonalData(t *testing.T) {
+	mockPrivacyUCase := new(mocks.PrivacyUseCase)
+	mockPrivacyUCase.On("ErasePersonalData", mock.Anything, 1, customer.ErasureRequest{PerformedBy: "A-01", AuthorizedBy: "key-2bb80d537b1d"}).
+		Return(&customer.ErasureResponse{ID: 10, CustomerID: 1, PerformedBy: "A-01", AuthorizedBy: "key-2bb80d537b1d"}, nil).Once()
+
+	r, err := http.NewRequest("POST", "/api/v1/customer/1/erasure", strings.NewReader(`{"performed_by":"A-01"}`))
+	assert.NoError(t, err)
+	r.Header.Set(adminAPIKeyHeader, "secret")
+
+	w := httptest.NewRecorder()
+
+	h := beego.NewControllerRegister()
+
+	handler := &CustomerPrivacyHandler{
+		CustomerPrivacyUseCase: mockPrivacyUCase,
+		AdminAPIKey:            "secret",
+	}
+
+	h.Add("/api/v1/customer/:id/erasure", handler, beego.WithRouterMethods(handler, "post:ErasePersonalData"))
+
+	h.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"authorizedBy":"key-2bb80d537b1d"`)
+	mockPrivacyUCase.AssertExpectations(t)
+}
+
+func TestCustomerHandler_UpdateConsents(t *testing.T) {
+	mockConsentUCase := new(mocks.ConsentUseCase)
+
+	r, err := http.NewRequest("PUT", "/api/v1/customer/1/consents",
+		strings.NewReader(`{"source":"pos","consents":[{"channel":"email","granted":true},{"channel":"fax","granted":true}]}`))
+	assert.NoError(t, err)
+
+	w := httptest.NewRecorder()
+
+	h := beego.NewControllerRegister()
+
+	handler := &CustomerConsentHandler{
+		CustomerConsentUseCase: mockConsentUCase,
+	}
+
+	h.Add("/api/v1/customer/:id/consents", handler, beego.WithRouterMethods(handler, "put:UpdateConsents"))
+
+	h.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Contains(t, w.Body.String(), "consents[1].channel")
+
+	// a channel listed twice is rejected
+	r, err = http.NewRequest("PUT", "/api/v1/customer/1/consents",
+		strings.NewReader(`{"source":"pos","consents":[{"channel":"sms","granted":true},{"channel":"sms","granted":false}]}`))
+	assert.NoError(t, err)
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	mockConsentUCase.AssertNotCalled(t, "UpdateConsents", mock.Anything, mock.Anything, mock.Anything)
+}
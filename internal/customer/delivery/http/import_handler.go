@@ -0,0 +1,163 @@
+package http
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"github.com/alpakih/point-of-sales/internal/constant"
+	"github.com/alpakih/point-of-sales/internal/customer"
+	"github.com/alpakih/point-of-sales/pkg/beegoresp"
+	"github.com/alpakih/point-of-sales/pkg/spreadsheet"
+	beego "github.com/beego/beego/v2/server/web"
+	"github.com/beego/i18n"
+	validatorGo "github.com/go-playground/validator/v10"
+	"net/http"
+	"strconv"
+)
+
+// CustomerImportHandler handles the import jobs of customers.
+type CustomerImportHandler struct {
+	customerController
+	CustomerImportUseCase customer.ImportUseCase
+}
+
+func NewCustomerImportHandler(importUseCase customer.ImportUseCase) {
+	handler := &CustomerImportHandler{
+		CustomerImportUseCase: importUseCase,
+	}
+	beego.Router("/api/v1/customers/import", handler, "post:ImportCustomers")
+	beego.Router("/api/v1/customers/import/:id", handler, "get:GetImportJob")
+	beego.Router("/api/v1/customers/import/:id/report", handler, "get:GetImportReport")
+}
+
+// ImportCustomers starts the import of the customers of a CSV or XLSX file sent
+// in the multipart field "file", the format is taken from ?format= or the file
+// extension. The rows are validated without being written with ?dry_run=true.
+func (h *CustomerImportHandler) ImportCustomers() {
+	var dryRun bool
+	if dryRunQuery := h.Ctx.Input.Query("dry_run"); dryRunQuery != "" {
+		value, err := strconv.ParseBool(dryRunQuery)
+		if err != nil {
+			h.ResponseError(h.Ctx, http.StatusBadRequest, constant.InvalidQueryParamErrorCode, i18n.Tr(h.Lang, "message.errorInvalidUrlQueryParam"))
+			return
+		}
+		dryRun = value
+	}
+
+	file, header, err := h.GetFile("file")
+	if err != nil {
+		h.ResponseError(h.Ctx, http.StatusBadRequest, constant.InvalidFileErrorCode, i18n.Tr(h.Lang, "message.errorImportFileRequired"))
+		return
+	}
+	defer file.Close()
+
+	format := h.Ctx.Input.Query("format")
+	if format == "" {
+		format = spreadsheet.FormatFromFilename(header.Filename)
+	}
+	reader, err := spreadsheet.NewReader(file, format)
+	if err != nil {
+		if errors.Is(err, spreadsheet.ErrUnsupportedFormat) {
+			h.ResponseError(h.Ctx, http.StatusBadRequest, constant.InvalidFileErrorCode, i18n.Tr(h.Lang, "message.errorImportUnsupportedFormat"))
+			return
+		}
+		h.ResponseError(h.Ctx, http.StatusBadRequest, constant.InvalidFileErrorCode, i18n.Tr(h.Lang, "message.errorImportInvalidFile"))
+		return
+	}
+	defer reader.Close()
+
+	records, err := spreadsheet.ReadRecords(reader, customer.ImportColumns...)
+	if err != nil {
+		var missingColumnError *spreadsheet.MissingColumnError
+		if errors.As(err, &missingColumnError) {
+			h.ResponseError(h.Ctx, http.StatusBadRequest, constant.InvalidFileErrorCode, i18n.Tr(h.Lang, "message.errorImportInvalidFile"), beegoresp.DetailErrors{
+				Target:      missingColumnError.Column,
+				Reason:      "required",
+				Description: i18n.Tr(h.Lang, "message.errorImportMissingColumn", missingColumnError.Column),
+			})
+			return
+		}
+		h.ResponseError(h.Ctx, http.StatusBadRequest, constant.InvalidFileErrorCode, i18n.Tr(h.Lang, "message.errorImportInvalidFile"))
+		return
+	}
+
+	rows := customer.NewCustomerMapper().ToImportRows(records)
+	if job, err := h.CustomerImportUseCase.ImportCustomers(h.Ctx.Request.Context(), rows, dryRun); err != nil {
+		h.ResponseError(h.Ctx, http.StatusInternalServerError, constant.ServerErrorCode, i18n.Tr(h.Lang, "message.errorServer"))
+		return
+	} else {
+		h.Ctx.Output.Header("Location", "/api/v1/customers/import/"+job.ID)
+		h.Accepted(h.Ctx, job)
+		return
+	}
+}
+
+// GetImportJob returns the progress of an import.
+func (h *CustomerImportHandler) GetImportJob() {
+	if job, err := h.CustomerImportUseCase.GetImportJob(h.Ctx.Request.Context(), h.Ctx.Input.Param(":id")); err != nil {
+		if errors.Is(err, constant.ErrImportJobNotFound) {
+			h.ResponseError(h.Ctx, http.StatusNotFound, constant.DataNotFoundErrorCode, i18n.Tr(h.Lang, "message.errorDataNotFound"))
+			return
+		}
+		h.ResponseError(h.Ctx, http.StatusInternalServerError, constant.ServerErrorCode, i18n.Tr(h.Lang, "message.errorServer"))
+		return
+	} else {
+		h.Ok(h.Ctx, job)
+		return
+	}
+}
+
+// GetImportReport downloads the errors of the rows processed so far as a CSV file
+// with one line per error, in the language of the request.
+func (h *CustomerImportHandler) GetImportReport() {
+	job, err := h.CustomerImportUseCase.GetImportJob(h.Ctx.Request.Context(), h.Ctx.Input.Param(":id"))
+	if err != nil {
+		if errors.Is(err, constant.ErrImportJobNotFound) {
+			h.ResponseError(h.Ctx, http.StatusNotFound, constant.DataNotFoundErrorCode, i18n.Tr(h.Lang, "message.errorDataNotFound"))
+			return
+		}
+		h.ResponseError(h.Ctx, http.StatusInternalServerError, constant.ServerErrorCode, i18n.Tr(h.Lang, "message.errorServer"))
+		return
+	}
+
+	h.Ctx.Output.Header("Content-Type", "text/csv; charset=utf-8")
+	h.Ctx.Output.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"customer-import-%s-report.csv\"", job.ID))
+	h.Ctx.Output.SetStatus(http.StatusOK)
+
+	writer := csv.NewWriter(h.Ctx.ResponseWriter)
+	_ = writer.Write([]string{"row", "target", "reason", "description"})
+	for _, rowError := range job.Errors {
+		for _, detail := range importErrorDetails(h.Lang, rowError) {
+			_ = writer.Write([]string{strconv.Itoa(rowError.Row), detail.Target, detail.Reason, detail.Description})
+		}
+	}
+	writer.Flush()
+}
+
+// importErrorDetails localizes the error of an import row like the responses of
+// StoreCustomer.
+func importErrorDetails(lang string, rowError customer.ImportRowError) []beegoresp.DetailErrors {
+	var validationErrors validatorGo.ValidationErrors
+	switch {
+	case errors.As(rowError.Err, &validationErrors):
+		return beegoresp.ValidationDetails(lang, validationErrors)
+	case errors.Is(rowError.Err, constant.ErrEmailAlreadyExist):
+		return []beegoresp.DetailErrors{{
+			Target:      "email",
+			Reason:      "duplicate",
+			Description: i18n.Tr(lang, "message.errorEmailAlreadyExist", rowError.Email),
+		}}
+	case errors.Is(rowError.Err, constant.ErrMobilePhoneAlreadyExist):
+		return []beegoresp.DetailErrors{{
+			Target:      "mobile_phone",
+			Reason:      "duplicate",
+			Description: i18n.Tr(lang, "message.errorMobilePhoneAlreadyExist", rowError.MobilePhone),
+		}}
+	default:
+		return []beegoresp.DetailErrors{{
+			Target:      "row",
+			Reason:      "error",
+			Description: i18n.Tr(lang, "message.errorServer"),
+		}}
+	}
+}
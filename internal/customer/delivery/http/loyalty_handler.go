@@ -0,0 +1,174 @@
+package http
+
+import (
+	"errors"
+	"github.com/alpakih/point-of-sales/internal/constant"
+	"github.com/alpakih/point-of-sales/internal/customer"
+	"github.com/alpakih/point-of-sales/pkg/beegoresp"
+	"github.com/alpakih/point-of-sales/pkg/validator"
+	beego "github.com/beego/beego/v2/server/web"
+	"github.com/beego/i18n"
+	"gorm.io/gorm"
+	"net/http"
+)
+
+// CustomerLoyaltyHandler handles the loyalty points of the customers.
+type CustomerLoyaltyHandler struct {
+	customerController
+	CustomerLoyaltyUseCase customer.LoyaltyUseCase
+}
+
+func NewCustomerLoyaltyHandler(loyaltyUseCase customer.LoyaltyUseCase) {
+	handler := &CustomerLoyaltyHandler{
+		CustomerLoyaltyUseCase: loyaltyUseCase,
+	}
+	beego.Router("/api/v1/customer/:id/loyalty", handler, "get:GetLoyalty")
+	beego.Router("/api/v1/customer/:id/loyalty/earn", handler, "post:EarnPoints")
+	beego.Router("/api/v1/customer/:id/loyalty/redeem", handler, "post:RedeemPoints")
+	beego.Router("/api/v1/customer/:id/loyalty/reversals", handler, "post:ReversePoints")
+}
+
+// GetLoyalty returns the loyalty points balance of the customer and its ledger,
+// the latest entry first.
+func (h *CustomerLoyaltyHandler) GetLoyalty() {
+	customerID, ok := h.paramID(":id")
+	if !ok {
+		return
+	}
+
+	if loyalty, err := h.CustomerLoyaltyUseCase.GetLoyalty(h.Ctx.Request.Context(), customerID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			h.ResponseError(h.Ctx, http.StatusNotFound, constant.DataNotFoundErrorCode, i18n.Tr(h.Lang, "message.errorDataNotFound"))
+			return
+		}
+		h.ResponseError(h.Ctx, http.StatusInternalServerError, constant.ServerErrorCode, i18n.Tr(h.Lang, "message.errorServer"))
+		return
+	} else {
+		h.Ok(h.Ctx, loyalty)
+		return
+	}
+}
+
+// EarnPoints credits the loyalty points earned by a sale of the customer, once
+// per sale.
+func (h *CustomerLoyaltyHandler) EarnPoints() {
+	var request customer.EarnRequest
+
+	customerID, ok := h.paramID(":id")
+	if !ok {
+		return
+	}
+
+	if err := h.BindJSON(&request); err != nil {
+		if h.responseInvalidJSON(err) {
+			return
+		}
+		h.ResponseError(h.Ctx, http.StatusInternalServerError, constant.ServerErrorCode, i18n.Tr(h.Lang, "message.errorServer"))
+		return
+	}
+
+	if err := validator.Validate.ValidateStruct(request); err != nil {
+		h.ResponseValidationError(h.Ctx, http.StatusUnprocessableEntity, constant.DataValidationErrorCode, i18n.Tr(h.Lang, "message.errorDataValidation"), err)
+		return
+	}
+
+	if entry, err := h.CustomerLoyaltyUseCase.EarnPoints(h.Ctx.Request.Context(), customerID, request); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			h.ResponseError(h.Ctx, http.StatusNotFound, constant.DataNotFoundErrorCode, i18n.Tr(h.Lang, "message.errorDataNotFound"))
+			return
+		}
+		if errors.Is(err, constant.ErrLoyaltyReferenceExists) {
+			h.ResponseError(h.Ctx, http.StatusConflict, constant.ConflictErrorCode, i18n.Tr(h.Lang, "message.errorDataValidation"), beegoresp.DetailErrors{
+				Target:      "reference",
+				Reason:      "unique",
+				Description: i18n.Tr(h.Lang, "message.errorLoyaltyReferenceExists", request.Reference),
+			})
+			return
+		}
+		h.ResponseError(h.Ctx, http.StatusInternalServerError, constant.ServerErrorCode, i18n.Tr(h.Lang, "message.errorServer"))
+		return
+	} else {
+		h.Ok(h.Ctx, entry)
+		return
+	}
+}
+
+// RedeemPoints debits the loyalty points tendered at the checkout of a sale and
+// returns the amount they pay for.
+func (h *CustomerLoyaltyHandler) RedeemPoints() {
+	var request customer.RedeemRequest
+
+	customerID, ok := h.paramID(":id")
+	if !ok {
+		return
+	}
+
+	if err := h.BindJSON(&request); err != nil {
+		if h.responseInvalidJSON(err) {
+			return
+		}
+		h.ResponseError(h.Ctx, http.StatusInternalServerError, constant.ServerErrorCode, i18n.Tr(h.Lang, "message.errorServer"))
+		return
+	}
+
+	if err := validator.Validate.ValidateStruct(request); err != nil {
+		h.ResponseValidationError(h.Ctx, http.StatusUnprocessableEntity, constant.DataValidationErrorCode, i18n.Tr(h.Lang, "message.errorDataValidation"), err)
+		return
+	}
+
+	if redemption, err := h.CustomerLoyaltyUseCase.RedeemPoints(h.Ctx.Request.Context(), customerID, request); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			h.ResponseError(h.Ctx, http.StatusNotFound, constant.DataNotFoundErrorCode, i18n.Tr(h.Lang, "message.errorDataNotFound"))
+			return
+		}
+		if errors.Is(err, constant.ErrInsufficientPoints) {
+			h.ResponseError(h.Ctx, http.StatusUnprocessableEntity, constant.DataValidationErrorCode, i18n.Tr(h.Lang, "message.errorDataValidation"), beegoresp.DetailErrors{
+				Target:      "points",
+				Reason:      "max",
+				Description: i18n.Tr(h.Lang, "message.errorInsufficientPoints"),
+			})
+			return
+		}
+		h.ResponseError(h.Ctx, http.StatusInternalServerError, constant.ServerErrorCode, i18n.Tr(h.Lang, "message.errorServer"))
+		return
+	} else {
+		h.Ok(h.Ctx, redemption)
+		return
+	}
+}
+
+// ReversePoints reverses the loyalty points earned and redeemed by a refunded
+// sale of the customer.
+func (h *CustomerLoyaltyHandler) ReversePoints() {
+	var request customer.ReversalRequest
+
+	customerID, ok := h.paramID(":id")
+	if !ok {
+		return
+	}
+
+	if err := h.BindJSON(&request); err != nil {
+		if h.responseInvalidJSON(err) {
+			return
+		}
+		h.ResponseError(h.Ctx, http.StatusInternalServerError, constant.ServerErrorCode, i18n.Tr(h.Lang, "message.errorServer"))
+		return
+	}
+
+	if err := validator.Validate.ValidateStruct(request); err != nil {
+		h.ResponseValidationError(h.Ctx, http.StatusUnprocessableEntity, constant.DataValidationErrorCode, i18n.Tr(h.Lang, "message.errorDataValidation"), err)
+		return
+	}
+
+	if reversals, err := h.CustomerLoyaltyUseCase.ReversePoints(h.Ctx.Request.Context(), customerID, request); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			h.ResponseError(h.Ctx, http.StatusNotFound, constant.DataNotFoundErrorCode, i18n.Tr(h.Lang, "message.errorDataNotFound"))
+			return
+		}
+		h.ResponseError(h.Ctx, http.StatusInternalServerError, constant.ServerErrorCode, i18n.Tr(h.Lang, "message.errorServer"))
+		return
+	} else {
+		h.Ok(h.Ctx, reversals)
+		return
+	}
+}
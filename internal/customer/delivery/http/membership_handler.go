@@ -0,0 +1,45 @@
+package http
+
+import (
+	"errors"
+	"github.com/alpakih/point-of-sales/internal/constant"
+	"github.com/alpakih/point-of-sales/internal/customer"
+	beego "github.com/beego/beego/v2/server/web"
+	"github.com/beego/i18n"
+	"gorm.io/gorm"
+	"net/http"
+)
+
+// CustomerMembershipHandler handles the membership tiers of the customers.
+type CustomerMembershipHandler struct {
+	customerController
+	CustomerMembershipUseCase customer.MembershipUseCase
+}
+
+func NewCustomerMembershipHandler(membershipUseCase customer.MembershipUseCase) {
+	handler := &CustomerMembershipHandler{
+		CustomerMembershipUseCase: membershipUseCase,
+	}
+	beego.Router("/api/v1/customer/:id/membership", handler, "get:GetMembership")
+}
+
+// GetMembership returns the membership tier of the customer, its benefits, its
+// rolling spend and points and the changes of its tier.
+func (h *CustomerMembershipHandler) GetMembership() {
+	customerID, ok := h.paramID(":id")
+	if !ok {
+		return
+	}
+
+	if membership, err := h.CustomerMembershipUseCase.GetMembership(h.Ctx.Request.Context(), customerID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			h.ResponseError(h.Ctx, http.StatusNotFound, constant.DataNotFoundErrorCode, i18n.Tr(h.Lang, "message.errorDataNotFound"))
+			return
+		}
+		h.ResponseError(h.Ctx, http.StatusInternalServerError, constant.ServerErrorCode, i18n.Tr(h.Lang, "message.errorServer"))
+		return
+	} else {
+		h.Ok(h.Ctx, membership)
+		return
+	}
+}
@@ -0,0 +1,79 @@
+package http
+
+import (
+	"errors"
+	"github.com/alpakih/point-of-sales/internal/constant"
+	"github.com/alpakih/point-of-sales/internal/customer"
+	"github.com/alpakih/point-of-sales/pkg/beegoresp"
+	"github.com/alpakih/point-of-sales/pkg/utils"
+	"github.com/alpakih/point-of-sales/pkg/validator"
+	"github.com/beego/i18n"
+	"gorm.io/gorm"
+	"net/http"
+)
+
+// GetDuplicateCandidates returns the other customers which may be the same person
+// as the customer, candidates to be merged into it.
+func (h *CustomerHandler) GetDuplicateCandidates() {
+
+	id, ok := h.paramID(":id")
+	if !ok {
+		return
+	}
+
+	if candidates, err := h.CustomerUseCase.FindDuplicateCandidates(h.Ctx.Request.Context(), id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			h.ResponseError(h.Ctx, http.StatusNotFound, constant.DataNotFoundErrorCode, i18n.Tr(h.Lang, "message.errorDataNotFound"))
+			return
+		}
+		h.ResponseError(h.Ctx, http.StatusInternalServerError, constant.ServerErrorCode, i18n.Tr(h.Lang, "message.errorServer"))
+		return
+	} else {
+		h.Ok(h.Ctx, candidates)
+		return
+	}
+}
+
+// MergeCustomers merges duplicate customers into the survivor customer, their
+// records are moved to the survivor and they are soft-deleted.
+func (h *CustomerHandler) MergeCustomers() {
+	var request customer.MergeRequest
+
+	if err := h.BindJSON(&request); err != nil {
+		if h.responseInvalidJSON(err) {
+			return
+		}
+		h.ResponseError(h.Ctx, http.StatusInternalServerError, constant.ServerErrorCode, i18n.Tr(h.Lang, "message.errorServer"))
+		return
+	}
+
+	if err := validator.Validate.ValidateStruct(request); err != nil {
+		h.ResponseValidationError(h.Ctx, http.StatusUnprocessableEntity, constant.DataValidationErrorCode, i18n.Tr(h.Lang, "message.errorDataValidation"), err)
+		return
+	}
+
+	if response, err := h.CustomerUseCase.MergeCustomers(h.Ctx.Request.Context(), request); err != nil {
+		if errors.Is(err, constant.ErrMergeIntoItself) {
+			h.ResponseError(h.Ctx, http.StatusUnprocessableEntity, constant.DataValidationErrorCode, i18n.Tr(h.Lang, "message.errorDataValidation"), beegoresp.DetailErrors{
+				Target:      "merged_ids",
+				Reason:      "excluded",
+				Description: i18n.Tr(h.Lang, "message.errorMergeIntoItself", request.SurvivorID),
+			})
+			return
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			h.ResponseError(h.Ctx, http.StatusNotFound, constant.DataNotFoundErrorCode, i18n.Tr(h.Lang, "message.errorDataNotFound"))
+			return
+		}
+		if errors.Is(err, constant.ErrVersionMismatch) {
+			h.ResponseError(h.Ctx, http.StatusConflict, constant.ConflictErrorCode, i18n.Tr(h.Lang, "message.errorMergeConflict"))
+			return
+		}
+		h.ResponseError(h.Ctx, http.StatusInternalServerError, constant.ServerErrorCode, i18n.Tr(h.Lang, "message.errorServer"))
+		return
+	} else {
+		h.Ctx.Output.Header("ETag", utils.BuildETag(response.Version))
+		h.Ok(h.Ctx, response)
+		return
+	}
+}
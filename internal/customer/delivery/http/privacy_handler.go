@@ -0,0 +1,155 @@
+package http
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/alpakih/point-of-sales/internal/constant"
+	"github.com/alpakih/point-of-sales/internal/customer"
+	"github.com/alpakih/point-of-sales/pkg/beegoresp"
+	"github.com/alpakih/point-of-sales/pkg/validator"
+	"github.com/beego/beego/v2/core/logs"
+	beego "github.com/beego/beego/v2/server/web"
+	"github.com/beego/i18n"
+	"gorm.io/gorm"
+	"net/http"
+)
+
+// CustomerPrivacyHandler handles the export and erasure of the personal data of the customers.
+type CustomerPrivacyHandler struct {
+	customerController
+	CustomerPrivacyUseCase customer.PrivacyUseCase
+	// AdminAPIKey key required by the personal data export and erasure, these
+	// are forbidden when it is empty
+	AdminAPIKey string
+}
+
+func NewCustomerPrivacyHandler(privacyUseCase customer.PrivacyUseCase, adminAPIKey string) {
+	handler := &CustomerPrivacyHandler{
+		CustomerPrivacyUseCase: privacyUseCase,
+		AdminAPIKey:            adminAPIKey,
+	}
+	beego.Router("/api/v1/customer/:id/data-export", handler, "get:ExportPersonalData")
+	beego.Router("/api/v1/customer/:id/erasure", handler, "post:ErasePersonalData")
+}
+
+// Formats of the personal data export.
+const (
+	dataExportFormatJSON = "json"
+	dataExportFormatZIP  = "zip"
+)
+
+// ExportPersonalData downloads everything stored about the customer as a
+// ?format=json document or a zip bundle of one JSON file per section, it
+// requires the admin API key.
+func (h *CustomerPrivacyHandler) ExportPersonalData() {
+	customerID, ok := h.paramID(":id")
+	if !ok {
+		return
+	}
+
+	format := h.Ctx.Input.Query("format")
+	if format == "" {
+		format = dataExportFormatJSON
+	}
+	if format != dataExportFormatJSON && format != dataExportFormatZIP {
+		h.ResponseError(h.Ctx, http.StatusBadRequest, constant.InvalidQueryParamErrorCode, i18n.Tr(h.Lang, "message.errorInvalidUrlQueryParam"), beegoresp.DetailErrors{
+			Target:      "format",
+			Reason:      "oneof",
+			Description: i18n.Tr(h.Lang, "message.errorDataExportUnsupportedFormat"),
+		})
+		return
+	}
+
+	if !h.authorizeAdmin(h.AdminAPIKey) {
+		return
+	}
+
+	export, err := h.CustomerPrivacyUseCase.ExportPersonalData(h.Ctx.Request.Context(), customerID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			h.ResponseError(h.Ctx, http.StatusNotFound, constant.DataNotFoundErrorCode, i18n.Tr(h.Lang, "message.errorDataNotFound"))
+			return
+		}
+		h.ResponseError(h.Ctx, http.StatusInternalServerError, constant.ServerErrorCode, i18n.Tr(h.Lang, "message.errorServer"))
+		return
+	}
+
+	if format == dataExportFormatJSON {
+		h.Ctx.Output.Header("Content-Type", "application/json; charset=utf-8")
+	} else {
+		h.Ctx.Output.Header("Content-Type", "application/zip")
+	}
+	h.Ctx.Output.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"customer-%d-data.%s\"", customerID, format))
+	h.Ctx.Output.SetStatus(http.StatusOK)
+
+	if format == dataExportFormatJSON {
+		encoder := json.NewEncoder(h.Ctx.ResponseWriter)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(export); err != nil {
+			logs.Error("customer data export failed: %v", err)
+		}
+		return
+	}
+
+	writer := zip.NewWriter(h.Ctx.ResponseWriter)
+	for _, file := range export.Files() {
+		w, err := writer.CreateHeader(&zip.FileHeader{Name: file.Name, Method: zip.Deflate, Modified: export.ExportedAt})
+		if err != nil {
+			logs.Error("customer data export failed: %v", err)
+			return
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(file.Data); err != nil {
+			logs.Error("customer data export failed: %v", err)
+			return
+		}
+	}
+	if err := writer.Close(); err != nil {
+		logs.Error("customer data export failed: %v", err)
+	}
+}
+
+// ErasePersonalData anonymizes the personal data of the customer and
+// soft-deletes it, its sales are kept. It requires the admin API key, the key
+// is recorded with the erasure.
+func (h *CustomerPrivacyHandler) ErasePersonalData() {
+	var request customer.ErasureRequest
+
+	customerID, ok := h.paramID(":id")
+	if !ok {
+		return
+	}
+
+	if !h.authorizeAdmin(h.AdminAPIKey) {
+		return
+	}
+
+	if err := h.BindJSON(&request); err != nil {
+		if h.responseInvalidJSON(err) {
+			return
+		}
+		h.ResponseError(h.Ctx, http.StatusInternalServerError, constant.ServerErrorCode, i18n.Tr(h.Lang, "message.errorServer"))
+		return
+	}
+
+	if err := validator.Validate.ValidateStruct(request); err != nil {
+		h.ResponseValidationError(h.Ctx, http.StatusUnprocessableEntity, constant.DataValidationErrorCode, i18n.Tr(h.Lang, "message.errorDataValidation"), err)
+		return
+	}
+	request.AuthorizedBy = h.adminKeyID()
+
+	if erasure, err := h.CustomerPrivacyUseCase.ErasePersonalData(h.Ctx.Request.Context(), customerID, request); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			h.ResponseError(h.Ctx, http.StatusNotFound, constant.DataNotFoundErrorCode, i18n.Tr(h.Lang, "message.errorDataNotFound"))
+			return
+		}
+		h.ResponseError(h.Ctx, http.StatusInternalServerError, constant.ServerErrorCode, i18n.Tr(h.Lang, "message.errorServer"))
+		return
+	} else {
+		h.Ok(h.Ctx, erasure)
+		return
+	}
+}
@@ -0,0 +1,88 @@
+package http
+
+import (
+	"errors"
+	"github.com/alpakih/point-of-sales/internal/constant"
+	"github.com/alpakih/point-of-sales/internal/customer"
+	"github.com/alpakih/point-of-sales/pkg/utils"
+	beego "github.com/beego/beego/v2/server/web"
+	"github.com/beego/i18n"
+	"gorm.io/gorm"
+	"net/http"
+	"strconv"
+)
+
+// CustomerSaleHandler handles the sales history of the customers.
+type CustomerSaleHandler struct {
+	customerController
+	CustomerSaleUseCase customer.SaleUseCase
+}
+
+func NewCustomerSaleHandler(saleUseCase customer.SaleUseCase) {
+	handler := &CustomerSaleHandler{
+		CustomerSaleUseCase: saleUseCase,
+	}
+	beego.Router("/api/v1/customer/:id/sales", handler, "get:GetSales")
+	beego.Router("/api/v1/customer/:id/summary", handler, "get:GetSummary")
+}
+
+// GetSales returns the page of the sales of the customer, the latest first.
+func (h *CustomerSaleHandler) GetSales() {
+	customerID, ok := h.paramID(":id")
+	if !ok {
+		return
+	}
+
+	paginationQuery, err := utils.GetPaginationFromCtx(h.Ctx)
+	if err != nil {
+		if errors.Is(err, strconv.ErrSyntax) {
+			h.ResponseError(h.Ctx, http.StatusBadRequest, constant.InvalidPathParamErrorCode, i18n.Tr(h.Lang, "message.errorInvalidQueryParam"))
+			return
+		}
+		if errors.Is(err, strconv.ErrRange) {
+			h.ResponseError(h.Ctx, http.StatusBadRequest, constant.InvalidPathParamErrorCode, i18n.Tr(h.Lang, "message.errorQueryParamOutOfRange"))
+			return
+		}
+		if h.responseInvalidQuery(err) {
+			return
+		}
+		h.ResponseError(h.Ctx, http.StatusInternalServerError, constant.ServerErrorCode, i18n.Tr(h.Lang, "message.errorServer"))
+		return
+	}
+
+	if result, err := h.CustomerSaleUseCase.GetSales(h.Ctx.Request.Context(), customerID, *paginationQuery); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			h.ResponseError(h.Ctx, http.StatusNotFound, constant.DataNotFoundErrorCode, i18n.Tr(h.Lang, "message.errorDataNotFound"))
+			return
+		}
+		if h.responseInvalidQuery(err) {
+			return
+		}
+		h.ResponseError(h.Ctx, http.StatusInternalServerError, constant.ServerErrorCode, i18n.Tr(h.Lang, "message.errorServer"))
+		return
+	} else {
+		h.OkWithPagination(h.Ctx, result.Pagination, result.Data)
+		return
+	}
+}
+
+// GetSummary returns the number of visits, the spend, the average basket, the
+// last visit, the favourite products and the lifetime value of the customer.
+func (h *CustomerSaleHandler) GetSummary() {
+	customerID, ok := h.paramID(":id")
+	if !ok {
+		return
+	}
+
+	if summary, err := h.CustomerSaleUseCase.GetSummary(h.Ctx.Request.Context(), customerID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			h.ResponseError(h.Ctx, http.StatusNotFound, constant.DataNotFoundErrorCode, i18n.Tr(h.Lang, "message.errorDataNotFound"))
+			return
+		}
+		h.ResponseError(h.Ctx, http.StatusInternalServerError, constant.ServerErrorCode, i18n.Tr(h.Lang, "message.errorServer"))
+		return
+	} else {
+		h.Ok(h.Ctx, summary)
+		return
+	}
+}
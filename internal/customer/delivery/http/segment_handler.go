@@ -0,0 +1,255 @@
+package http
+
+import (
+	"errors"
+	"github.com/alpakih/point-of-sales/internal/constant"
+	"github.com/alpakih/point-of-sales/internal/customer"
+	"github.com/alpakih/point-of-sales/pkg/beegoresp"
+	"github.com/alpakih/point-of-sales/pkg/validator"
+	beego "github.com/beego/beego/v2/server/web"
+	"github.com/beego/i18n"
+	"gorm.io/gorm"
+	"net/http"
+)
+
+// CustomerSegmentHandler handles the customer segments.
+type CustomerSegmentHandler struct {
+	customerController
+	CustomerSegmentUseCase customer.SegmentUseCase
+}
+
+func NewCustomerSegmentHandler(segmentUseCase customer.SegmentUseCase) {
+	handler := &CustomerSegmentHandler{
+		CustomerSegmentUseCase: segmentUseCase,
+	}
+	beego.Router("/api/v1/customers/segments", handler, "get:GetSegments")
+	beego.Router("/api/v1/customers/segments", handler, "post:StoreSegment")
+	beego.Router("/api/v1/customers/segments/:id", handler, "get:GetSegmentByID")
+	beego.Router("/api/v1/customers/segments/:id", handler, "put:UpdateSegment")
+	beego.Router("/api/v1/customers/segments/:id", handler, "delete:DeleteSegment")
+	beego.Router("/api/v1/customers/segments/:id/evaluate", handler, "post:EvaluateSegment")
+	beego.Router("/api/v1/customers/segments/:id/members", handler, "post:AddSegmentMembers")
+	beego.Router("/api/v1/customers/segments/:id/members/:customerId", handler, "delete:RemoveSegmentMember")
+}
+
+// GetSegments returns the customer segments ordered by name.
+func (h *CustomerSegmentHandler) GetSegments() {
+	if segments, err := h.CustomerSegmentUseCase.GetSegments(h.Ctx.Request.Context()); err != nil {
+		h.ResponseError(h.Ctx, http.StatusInternalServerError, constant.ServerErrorCode, i18n.Tr(h.Lang, "message.errorServer"))
+		return
+	} else {
+		h.Ok(h.Ctx, segments)
+		return
+	}
+}
+
+func (h *CustomerSegmentHandler) GetSegmentByID() {
+	id, ok := h.paramID(":id")
+	if !ok {
+		return
+	}
+
+	if segment, err := h.CustomerSegmentUseCase.GetSegmentByID(h.Ctx.Request.Context(), id); err != nil {
+		if h.responseSegmentError(err) {
+			return
+		}
+		h.ResponseError(h.Ctx, http.StatusInternalServerError, constant.ServerErrorCode, i18n.Tr(h.Lang, "message.errorServer"))
+		return
+	} else {
+		h.Ok(h.Ctx, segment)
+		return
+	}
+}
+
+// StoreSegment creates a static segment or a dynamic segment with its rule, a
+// dynamic segment has no member until it is evaluated.
+func (h *CustomerSegmentHandler) StoreSegment() {
+	var request customer.SegmentRequest
+
+	if err := h.BindJSON(&request); err != nil {
+		if h.responseInvalidJSON(err) {
+			return
+		}
+		h.ResponseError(h.Ctx, http.StatusInternalServerError, constant.ServerErrorCode, i18n.Tr(h.Lang, "message.errorServer"))
+		return
+	}
+
+	if err := validator.Validate.ValidateStruct(request); err != nil {
+		h.ResponseValidationError(h.Ctx, http.StatusUnprocessableEntity, constant.DataValidationErrorCode, i18n.Tr(h.Lang, "message.errorDataValidation"), err)
+		return
+	}
+
+	if segment, err := h.CustomerSegmentUseCase.StoreSegment(h.Ctx.Request.Context(), request); err != nil {
+		if h.responseSegmentError(err) {
+			return
+		}
+		h.ResponseError(h.Ctx, http.StatusInternalServerError, constant.ServerErrorCode, i18n.Tr(h.Lang, "message.errorServer"))
+		return
+	} else {
+		h.Ok(h.Ctx, segment)
+		return
+	}
+}
+
+func (h *CustomerSegmentHandler) UpdateSegment() {
+	var request customer.SegmentRequest
+
+	id, ok := h.paramID(":id")
+	if !ok {
+		return
+	}
+
+	if err := h.BindJSON(&request); err != nil {
+		if h.responseInvalidJSON(err) {
+			return
+		}
+		h.ResponseError(h.Ctx, http.StatusInternalServerError, constant.ServerErrorCode, i18n.Tr(h.Lang, "message.errorServer"))
+		return
+	}
+
+	if err := validator.Validate.ValidateStruct(request); err != nil {
+		h.ResponseValidationError(h.Ctx, http.StatusUnprocessableEntity, constant.DataValidationErrorCode, i18n.Tr(h.Lang, "message.errorDataValidation"), err)
+		return
+	}
+
+	if segment, err := h.CustomerSegmentUseCase.UpdateSegment(h.Ctx.Request.Context(), id, request); err != nil {
+		if h.responseSegmentError(err) {
+			return
+		}
+		h.ResponseError(h.Ctx, http.StatusInternalServerError, constant.ServerErrorCode, i18n.Tr(h.Lang, "message.errorServer"))
+		return
+	} else {
+		h.Ok(h.Ctx, segment)
+		return
+	}
+}
+
+func (h *CustomerSegmentHandler) DeleteSegment() {
+	id, ok := h.paramID(":id")
+	if !ok {
+		return
+	}
+
+	if err := h.CustomerSegmentUseCase.DeleteSegment(h.Ctx.Request.Context(), id); err != nil {
+		if h.responseSegmentError(err) {
+			return
+		}
+		h.ResponseError(h.Ctx, http.StatusInternalServerError, constant.ServerErrorCode, i18n.Tr(h.Lang, "message.errorServer"))
+		return
+	}
+	h.Ok(h.Ctx, nil)
+	return
+}
+
+// EvaluateSegment replaces the members of a dynamic segment by the customers
+// matching its rule now.
+func (h *CustomerSegmentHandler) EvaluateSegment() {
+	id, ok := h.paramID(":id")
+	if !ok {
+		return
+	}
+
+	if segment, err := h.CustomerSegmentUseCase.EvaluateSegment(h.Ctx.Request.Context(), id); err != nil {
+		if h.responseSegmentError(err) {
+			return
+		}
+		h.ResponseError(h.Ctx, http.StatusInternalServerError, constant.ServerErrorCode, i18n.Tr(h.Lang, "message.errorServer"))
+		return
+	} else {
+		h.Ok(h.Ctx, segment)
+		return
+	}
+}
+
+// AddSegmentMembers adds customers to a static segment.
+func (h *CustomerSegmentHandler) AddSegmentMembers() {
+	var request customer.SegmentMembersRequest
+
+	id, ok := h.paramID(":id")
+	if !ok {
+		return
+	}
+
+	if err := h.BindJSON(&request); err != nil {
+		if h.responseInvalidJSON(err) {
+			return
+		}
+		h.ResponseError(h.Ctx, http.StatusInternalServerError, constant.ServerErrorCode, i18n.Tr(h.Lang, "message.errorServer"))
+		return
+	}
+
+	if err := validator.Validate.ValidateStruct(request); err != nil {
+		h.ResponseValidationError(h.Ctx, http.StatusUnprocessableEntity, constant.DataValidationErrorCode, i18n.Tr(h.Lang, "message.errorDataValidation"), err)
+		return
+	}
+
+	if segment, err := h.CustomerSegmentUseCase.AddSegmentMembers(h.Ctx.Request.Context(), id, request); err != nil {
+		if h.responseSegmentError(err) {
+			return
+		}
+		h.ResponseError(h.Ctx, http.StatusInternalServerError, constant.ServerErrorCode, i18n.Tr(h.Lang, "message.errorServer"))
+		return
+	} else {
+		h.Ok(h.Ctx, segment)
+		return
+	}
+}
+
+// RemoveSegmentMember removes a customer from a static segment.
+func (h *CustomerSegmentHandler) RemoveSegmentMember() {
+	id, ok := h.paramID(":id")
+	if !ok {
+		return
+	}
+	customerID, ok := h.paramID(":customerId")
+	if !ok {
+		return
+	}
+
+	if err := h.CustomerSegmentUseCase.RemoveSegmentMember(h.Ctx.Request.Context(), id, customerID); err != nil {
+		if h.responseSegmentError(err) {
+			return
+		}
+		h.ResponseError(h.Ctx, http.StatusInternalServerError, constant.ServerErrorCode, i18n.Tr(h.Lang, "message.errorServer"))
+		return
+	}
+	h.Ok(h.Ctx, nil)
+	return
+}
+
+// responseSegmentError writes the response of the errors of the segment use
+// case, it returns false when err isn't one of them.
+func (h *CustomerSegmentHandler) responseSegmentError(err error) bool {
+	var ruleError *customer.SegmentRuleError
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		h.ResponseError(h.Ctx, http.StatusNotFound, constant.DataNotFoundErrorCode, i18n.Tr(h.Lang, "message.errorDataNotFound"))
+	case errors.Is(err, constant.ErrSegmentNameExists):
+		h.ResponseError(h.Ctx, http.StatusConflict, constant.ConflictErrorCode, i18n.Tr(h.Lang, "message.errorDataValidation"), beegoresp.DetailErrors{
+			Target:      "name",
+			Reason:      "unique",
+			Description: i18n.Tr(h.Lang, "message.errorSegmentNameExists"),
+		})
+	case errors.As(err, &ruleError):
+		h.ResponseError(h.Ctx, http.StatusUnprocessableEntity, constant.DataValidationErrorCode, i18n.Tr(h.Lang, "message.errorDataValidation"), beegoresp.DetailErrors{
+			Target:      "rule",
+			Reason:      "rule",
+			Description: i18n.Tr(h.Lang, "message.errorSegmentRule", ruleError.Reason, ruleError.Position),
+		})
+	case errors.Is(err, constant.ErrSegmentNotStatic):
+		h.ResponseError(h.Ctx, http.StatusUnprocessableEntity, constant.DataValidationErrorCode, i18n.Tr(h.Lang, "message.errorDataValidation"), beegoresp.DetailErrors{
+			Target:      "type",
+			Reason:      "eq",
+			Description: i18n.Tr(h.Lang, "message.errorSegmentNotStatic"),
+		})
+	case errors.Is(err, constant.ErrSegmentNotDynamic):
+		h.ResponseError(h.Ctx, http.StatusUnprocessableEntity, constant.DataValidationErrorCode, i18n.Tr(h.Lang, "message.errorDataValidation"), beegoresp.DetailErrors{
+			Target:      "type",
+			Reason:      "eq",
+			Description: i18n.Tr(h.Lang, "message.errorSegmentNotDynamic"),
+		})
+	default:
+		return false
+	}
+	return true
+}
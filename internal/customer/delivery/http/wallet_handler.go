@@ -0,0 +1,219 @@
+package http
+
+import (
+	"errors"
+	"github.com/alpakih/point-of-sales/internal/constant"
+	"github.com/alpakih/point-of-sales/internal/customer"
+	"github.com/alpakih/point-of-sales/pkg/beegoresp"
+	"github.com/alpakih/point-of-sales/pkg/validator"
+	beego "github.com/beego/beego/v2/server/web"
+	"github.com/beego/i18n"
+	"gorm.io/gorm"
+	"net/http"
+)
+
+// CustomerWalletHandler handles the store credit wallets of the customers.
+type CustomerWalletHandler struct {
+	customerController
+	CustomerWalletUseCase customer.WalletUseCase
+	// AdminAPIKey key required by the wallet adjustments, these are forbidden
+	// when it is empty
+	AdminAPIKey string
+}
+
+func NewCustomerWalletHandler(walletUseCase customer.WalletUseCase, adminAPIKey string) {
+	handler := &CustomerWalletHandler{
+		CustomerWalletUseCase: walletUseCase,
+		AdminAPIKey:           adminAPIKey,
+	}
+	beego.Router("/api/v1/customer/:id/wallet", handler, "get:GetWallet")
+	beego.Router("/api/v1/customer/:id/wallet/topups", handler, "post:TopUpWallet")
+	beego.Router("/api/v1/customer/:id/wallet/spend", handler, "post:SpendWallet")
+	beego.Router("/api/v1/customer/:id/wallet/refunds", handler, "post:RefundToWallet")
+	beego.Router("/api/v1/customer/:id/wallet/adjustments", handler, "post:AdjustWallet")
+}
+
+// GetWallet returns the store credit balance of the customer and its ledger.
+func (h *CustomerWalletHandler) GetWallet() {
+	customerID, ok := h.paramID(":id")
+	if !ok {
+		return
+	}
+
+	if wallet, err := h.CustomerWalletUseCase.GetWallet(h.Ctx.Request.Context(), customerID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			h.ResponseError(h.Ctx, http.StatusNotFound, constant.DataNotFoundErrorCode, i18n.Tr(h.Lang, "message.errorDataNotFound"))
+			return
+		}
+		h.ResponseError(h.Ctx, http.StatusInternalServerError, constant.ServerErrorCode, i18n.Tr(h.Lang, "message.errorServer"))
+		return
+	} else {
+		h.Ok(h.Ctx, wallet)
+		return
+	}
+}
+
+// TopUpWallet credits a top-up paid by the customer to its wallet.
+func (h *CustomerWalletHandler) TopUpWallet() {
+	var request customer.WalletRequest
+
+	customerID, ok := h.paramID(":id")
+	if !ok {
+		return
+	}
+
+	if err := h.BindJSON(&request); err != nil {
+		if h.responseInvalidJSON(err) {
+			return
+		}
+		h.ResponseError(h.Ctx, http.StatusInternalServerError, constant.ServerErrorCode, i18n.Tr(h.Lang, "message.errorServer"))
+		return
+	}
+
+	if err := validator.Validate.ValidateStruct(request); err != nil {
+		h.ResponseValidationError(h.Ctx, http.StatusUnprocessableEntity, constant.DataValidationErrorCode, i18n.Tr(h.Lang, "message.errorDataValidation"), err)
+		return
+	}
+
+	if entry, err := h.CustomerWalletUseCase.TopUp(h.Ctx.Request.Context(), customerID, request); err != nil {
+		if h.responseWalletError(err) {
+			return
+		}
+		h.ResponseError(h.Ctx, http.StatusInternalServerError, constant.ServerErrorCode, i18n.Tr(h.Lang, "message.errorServer"))
+		return
+	} else {
+		h.Ok(h.Ctx, entry)
+		return
+	}
+}
+
+// SpendWallet debits the wallet of the customer tendered as payment of a sale.
+func (h *CustomerWalletHandler) SpendWallet() {
+	var request customer.WalletRequest
+
+	customerID, ok := h.paramID(":id")
+	if !ok {
+		return
+	}
+
+	if err := h.BindJSON(&request); err != nil {
+		if h.responseInvalidJSON(err) {
+			return
+		}
+		h.ResponseError(h.Ctx, http.StatusInternalServerError, constant.ServerErrorCode, i18n.Tr(h.Lang, "message.errorServer"))
+		return
+	}
+
+	if err := validator.Validate.ValidateStruct(request); err != nil {
+		h.ResponseValidationError(h.Ctx, http.StatusUnprocessableEntity, constant.DataValidationErrorCode, i18n.Tr(h.Lang, "message.errorDataValidation"), err)
+		return
+	}
+
+	if entry, err := h.CustomerWalletUseCase.Spend(h.Ctx.Request.Context(), customerID, request); err != nil {
+		if h.responseWalletError(err) {
+			return
+		}
+		h.ResponseError(h.Ctx, http.StatusInternalServerError, constant.ServerErrorCode, i18n.Tr(h.Lang, "message.errorServer"))
+		return
+	} else {
+		h.Ok(h.Ctx, entry)
+		return
+	}
+}
+
+// RefundToWallet credits a refunded sale to the wallet of the customer.
+func (h *CustomerWalletHandler) RefundToWallet() {
+	var request customer.WalletRequest
+
+	customerID, ok := h.paramID(":id")
+	if !ok {
+		return
+	}
+
+	if err := h.BindJSON(&request); err != nil {
+		if h.responseInvalidJSON(err) {
+			return
+		}
+		h.ResponseError(h.Ctx, http.StatusInternalServerError, constant.ServerErrorCode, i18n.Tr(h.Lang, "message.errorServer"))
+		return
+	}
+
+	if err := validator.Validate.ValidateStruct(request); err != nil {
+		h.ResponseValidationError(h.Ctx, http.StatusUnprocessableEntity, constant.DataValidationErrorCode, i18n.Tr(h.Lang, "message.errorDataValidation"), err)
+		return
+	}
+
+	if entry, err := h.CustomerWalletUseCase.Refund(h.Ctx.Request.Context(), customerID, request); err != nil {
+		if h.responseWalletError(err) {
+			return
+		}
+		h.ResponseError(h.Ctx, http.StatusInternalServerError, constant.ServerErrorCode, i18n.Tr(h.Lang, "message.errorServer"))
+		return
+	} else {
+		h.Ok(h.Ctx, entry)
+		return
+	}
+}
+
+// AdjustWallet adjusts the wallet of the customer manually, it requires the
+// admin API key.
+func (h *CustomerWalletHandler) AdjustWallet() {
+	var request customer.WalletAdjustmentRequest
+
+	customerID, ok := h.paramID(":id")
+	if !ok {
+		return
+	}
+
+	if !h.authorizeAdmin(h.AdminAPIKey) {
+		return
+	}
+
+	if err := h.BindJSON(&request); err != nil {
+		if h.responseInvalidJSON(err) {
+			return
+		}
+		h.ResponseError(h.Ctx, http.StatusInternalServerError, constant.ServerErrorCode, i18n.Tr(h.Lang, "message.errorServer"))
+		return
+	}
+
+	if err := validator.Validate.ValidateStruct(request); err != nil {
+		h.ResponseValidationError(h.Ctx, http.StatusUnprocessableEntity, constant.DataValidationErrorCode, i18n.Tr(h.Lang, "message.errorDataValidation"), err)
+		return
+	}
+
+	if entry, err := h.CustomerWalletUseCase.Adjust(h.Ctx.Request.Context(), customerID, request); err != nil {
+		if h.responseWalletError(err) {
+			return
+		}
+		h.ResponseError(h.Ctx, http.StatusInternalServerError, constant.ServerErrorCode, i18n.Tr(h.Lang, "message.errorServer"))
+		return
+	} else {
+		h.Ok(h.Ctx, entry)
+		return
+	}
+}
+
+// responseWalletError writes the response of the errors of the wallet use case,
+// it returns false when err isn't one of them.
+func (h *CustomerWalletHandler) responseWalletError(err error) bool {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		h.ResponseError(h.Ctx, http.StatusNotFound, constant.DataNotFoundErrorCode, i18n.Tr(h.Lang, "message.errorDataNotFound"))
+	case errors.Is(err, constant.ErrWalletReferenceExists):
+		h.ResponseError(h.Ctx, http.StatusConflict, constant.ConflictErrorCode, i18n.Tr(h.Lang, "message.errorDataValidation"), beegoresp.DetailErrors{
+			Target:      "reference",
+			Reason:      "unique",
+			Description: i18n.Tr(h.Lang, "message.errorWalletReferenceExists"),
+		})
+	case errors.Is(err, constant.ErrInsufficientBalance):
+		h.ResponseError(h.Ctx, http.StatusUnprocessableEntity, constant.DataValidationErrorCode, i18n.Tr(h.Lang, "message.errorDataValidation"), beegoresp.DetailErrors{
+			Target:      "amount",
+			Reason:      "max",
+			Description: i18n.Tr(h.Lang, "message.errorInsufficientBalance"),
+		})
+	default:
+		return false
+	}
+	return true
+}
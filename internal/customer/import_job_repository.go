@@ -0,0 +1,8 @@
+package customer
+
+import "context"
+
+type ImportJobRepository interface {
+	Save(ctx context.Context, job ImportJob) error
+	FindImportJobByID(ctx context.Context, id string) (ImportJob, error)
+}
@@ -0,0 +1,68 @@
+package customer
+
+import (
+	"github.com/alpakih/point-of-sales/pkg/utils"
+	"github.com/beego/beego/v2/server/web/context"
+	"strconv"
+)
+
+// ListQuery query of the customer listing and export, the pagination query and
+// the filters specific to the customers.
+type ListQuery struct {
+	utils.PaginationQuery
+	// WithDeleted includes the soft-deleted customers in the listing
+	WithDeleted bool `json:"withDeleted,omitempty"`
+	// Segment name of the segment the listed customers are members of, empty for all
+	Segment string `json:"segment,omitempty"`
+	// Consent channel the listed customers consented to marketing on, empty for all
+	Consent string `json:"consent,omitempty"`
+}
+
+func (q *ListQuery) SetWithDeleted(withDeletedQuery string) error {
+	if withDeletedQuery == "" {
+		q.WithDeleted = false
+		return nil
+	}
+	withDeleted, err := strconv.ParseBool(withDeletedQuery)
+	if err != nil {
+		return err
+	}
+	q.WithDeleted = withDeleted
+
+	return nil
+}
+
+func (q *ListQuery) GetWithDeleted() bool {
+	return q.WithDeleted
+}
+
+func (q *ListQuery) SetSegment(segmentQuery string) {
+	q.Segment = segmentQuery
+}
+
+func (q *ListQuery) GetSegment() string {
+	return q.Segment
+}
+
+func (q *ListQuery) SetConsent(consentQuery string) {
+	q.Consent = consentQuery
+}
+
+func (q *ListQuery) GetConsent() string {
+	return q.Consent
+}
+
+func GetListQueryFromCtx(c *context.Context) (*ListQuery, error) {
+	paginationQuery, err := utils.GetPaginationFromCtx(c)
+	if err != nil {
+		return nil, err
+	}
+	q := &ListQuery{PaginationQuery: *paginationQuery}
+	if err := q.SetWithDeleted(c.Input.Query("with_deleted")); err != nil {
+		return nil, err
+	}
+	q.SetSegment(c.Input.Query("segment"))
+	q.SetConsent(c.Input.Query("consent"))
+
+	return q, nil
+}
@@ -0,0 +1,179 @@
+package customer
+
+import (
+	"github.com/alpakih/point-of-sales/internal/domain"
+	"sort"
+	"strings"
+	"time"
+)
+
+// LoyaltyRule earning, redemption and expiry of the loyalty points.
+type LoyaltyRule struct {
+	// AmountPerPoint amount spent to earn a point, 10000 earns 1 point per Rp10.000
+	AmountPerPoint int64
+	// PointValue amount a redeemed point pays for at checkout
+	PointValue int64
+	// ExcludedCategories categories of products not earning points
+	ExcludedCategories []string
+	// ExpiryMonths months the earned points can be redeemed for
+	ExpiryMonths int
+	// Tiers membership tiers multiplying the earned points
+	Tiers MembershipTiers
+}
+
+// Default values of LoyaltyRule.
+const (
+	DefaultLoyaltyAmountPerPoint = 10000
+	DefaultLoyaltyPointValue     = 100
+	DefaultLoyaltyExpiryMonths   = 12
+)
+
+// EarnedPoints points earned by the lines of a sale of a customer of the tier,
+// the amount of the lines of the excluded categories doesn't count.
+func (r LoyaltyRule) EarnedPoints(lines []EarnLine, tier MembershipTier) int {
+	if r.AmountPerPoint <= 0 {
+		return 0
+	}
+	var amount int64
+	for _, line := range lines {
+		if r.excluded(line.Category) {
+			continue
+		}
+		amount += line.Amount
+	}
+	return int(float64(amount/r.AmountPerPoint) * tier.Multiplier)
+}
+
+// ExpiresAt expiry time of the points credited at t.
+func (r LoyaltyRule) ExpiresAt(t time.Time) time.Time {
+	return t.AddDate(0, r.ExpiryMonths, 0)
+}
+
+func (r LoyaltyRule) excluded(category string) bool {
+	for _, excluded := range r.ExcludedCategories {
+		if strings.EqualFold(strings.TrimSpace(excluded), strings.TrimSpace(category)) {
+			return true
+		}
+	}
+	return false
+}
+
+// LoyaltyLot points credited by an entry of the loyalty ledger and the points
+// left of them.
+type LoyaltyLot struct {
+	EntryID   int
+	Points    int
+	Remaining int
+	ExpiresAt time.Time
+}
+
+// LoyaltyLedger state of the loyalty ledger of a customer replayed by
+// ReplayLoyaltyLedger.
+type LoyaltyLedger struct {
+	// Lots credited points in the order they were credited
+	Lots []LoyaltyLot
+	// Deficit points debited beyond the credited points, like the reversal of
+	// points already redeemed, it is settled by the next credited points
+	Deficit int
+}
+
+// ReplayLoyaltyLedger replays the entries of the loyalty ledger of a customer,
+// in the order of their ids. Debit entries take the points from the lot they
+// are related to first, then from the lots expiring first that weren't expired
+// when the entry was appended.
+func ReplayLoyaltyLedger(entries []domain.LoyaltyEntry) LoyaltyLedger {
+	var ledger LoyaltyLedger
+	var index = make(map[int]int)
+
+	for _, entry := range entries {
+		if entry.Points > 0 {
+			var lot = LoyaltyLot{EntryID: entry.ID, Points: entry.Points, Remaining: entry.Points}
+			if entry.ExpiresAt != nil {
+				lot.ExpiresAt = *entry.ExpiresAt
+			}
+			settled := minInt(ledger.Deficit, lot.Remaining)
+			ledger.Deficit -= settled
+			lot.Remaining -= settled
+			index[entry.ID] = len(ledger.Lots)
+			ledger.Lots = append(ledger.Lots, lot)
+			continue
+		}
+
+		points := -entry.Points
+		if entry.RelatedEntryID != nil {
+			if i, ok := index[*entry.RelatedEntryID]; ok {
+				taken := minInt(points, ledger.Lots[i].Remaining)
+				ledger.Lots[i].Remaining -= taken
+				points -= taken
+			}
+		}
+		for _, i := range ledger.available(entry.CreatedAt) {
+			if points == 0 {
+				break
+			}
+			taken := minInt(points, ledger.Lots[i].Remaining)
+			ledger.Lots[i].Remaining -= taken
+			points -= taken
+		}
+		ledger.Deficit += points
+	}
+	return ledger
+}
+
+// available indexes of the lots with points left not expired at t, the lots
+// expiring first first.
+func (l LoyaltyLedger) available(t time.Time) []int {
+	var indexes []int
+	for i, lot := range l.Lots {
+		if lot.Remaining > 0 && lot.ExpiresAt.After(t) {
+			indexes = append(indexes, i)
+		}
+	}
+	sort.SliceStable(indexes, func(i, j int) bool {
+		return l.Lots[indexes[i]].ExpiresAt.Before(l.Lots[indexes[j]].ExpiresAt)
+	})
+	return indexes
+}
+
+// Balance points which can be redeemed at t.
+func (l LoyaltyLedger) Balance(t time.Time) int {
+	var balance = -l.Deficit
+	for _, i := range l.available(t) {
+		balance += l.Lots[i].Remaining
+	}
+	return balance
+}
+
+// NextExpiry points of the balance at t expiring first and when they expire,
+// nil when there are none.
+func (l LoyaltyLedger) NextExpiry(t time.Time) *LoyaltyExpiry {
+	indexes := l.available(t)
+	if len(indexes) == 0 {
+		return nil
+	}
+	var expiry = LoyaltyExpiry{ExpiresAt: l.Lots[indexes[0]].ExpiresAt}
+	for _, i := range indexes {
+		if l.Lots[i].ExpiresAt.Equal(expiry.ExpiresAt) {
+			expiry.Points += l.Lots[i].Remaining
+		}
+	}
+	return &expiry
+}
+
+// Expired lots with points left expired at t.
+func (l LoyaltyLedger) Expired(t time.Time) []LoyaltyLot {
+	var lots []LoyaltyLot
+	for _, lot := range l.Lots {
+		if lot.Remaining > 0 && !lot.ExpiresAt.After(t) {
+			lots = append(lots, lot)
+		}
+	}
+	return lots
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,16 @@
+package customer
+
+import (
+	"context"
+	"github.com/alpakih/point-of-sales/internal/domain"
+	"time"
+)
+
+// LoyaltyPgRepository stores the loyalty ledgers of the customers, entries are
+// appended and never changed.
+type LoyaltyPgRepository interface {
+	LockCustomer(ctx context.Context, customerID int) error
+	Append(ctx context.Context, entries []domain.LoyaltyEntry) ([]domain.LoyaltyEntry, error)
+	FindEntries(ctx context.Context, customerID int) ([]domain.LoyaltyEntry, error)
+	FindCustomerIDsWithExpiringPoints(ctx context.Context, from, to time.Time) ([]int, error)
+}
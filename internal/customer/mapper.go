@@ -3,8 +3,12 @@ package customer
 import (
 	"github.com/alpakih/point-of-sales/internal/domain"
 	"github.com/alpakih/point-of-sales/pkg/database"
+	"github.com/alpakih/point-of-sales/pkg/phone"
+	"github.com/alpakih/point-of-sales/pkg/spreadsheet"
 	"github.com/alpakih/point-of-sales/pkg/utils"
+	"sort"
 	"strings"
+	"time"
 )
 
 type Mapper struct {
@@ -15,29 +19,355 @@ func NewCustomerMapper() *Mapper {
 }
 
 func (m *Mapper) ToCustomerResponse(customer domain.Customer) Response {
-	return Response{
+	response := Response{
 		ID:          customer.ID,
 		Name:        customer.Name,
 		Email:       customer.Email,
 		MobilePhone: customer.MobilePhone,
+		Version:     customer.Version,
+	}
+	if customer.DeletedAt.Valid {
+		response.DeletedAt = &customer.DeletedAt.Time
+	}
+	if customer.Addresses != nil {
+		addresses := m.ToAddressResponses(customer.Addresses)
+		response.Addresses = &addresses
+	}
+	if customer.LoyaltyEntries != nil {
+		response.Loyalty = m.ToLoyaltyBalanceResponse(customer.LoyaltyEntries, time.Now())
+	}
+	return response
+}
+
+// ToLoyaltyBalanceResponse maps the balance at t of a loyalty ledger, the entries
+// are replayed in the order they were appended whatever order they were read in.
+func (m *Mapper) ToLoyaltyBalanceResponse(entries []domain.LoyaltyEntry, t time.Time) *LoyaltyBalanceResponse {
+	var ordered = make([]domain.LoyaltyEntry, len(entries))
+	copy(ordered, entries)
+	sort.Slice(ordered, func(i, j int) bool {
+		return ordered[i].ID < ordered[j].ID
+	})
+	ledger := ReplayLoyaltyLedger(ordered)
+	return &LoyaltyBalanceResponse{
+		Balance:    ledger.Balance(t),
+		NextExpiry: ledger.NextExpiry(t),
+	}
+}
+
+func (m *Mapper) ToAddressResponse(address domain.CustomerAddress) AddressResponse {
+	return AddressResponse{
+		ID:         address.ID,
+		Label:      address.Label,
+		Recipient:  address.Recipient,
+		Phone:      address.Phone,
+		Street:     address.Street,
+		Village:    address.Village,
+		District:   address.District,
+		City:       address.City,
+		Province:   address.Province,
+		PostalCode: address.PostalCode,
+		IsDefault:  address.IsDefault,
 	}
 }
 
+// ToAddressResponses maps the addresses of a customer, the default address first
+// and the others in the order they were added.
+func (m *Mapper) ToAddressResponses(addresses []domain.CustomerAddress) []AddressResponse {
+	var data = make([]AddressResponse, len(addresses))
+	for k, v := range addresses {
+		data[k] = m.ToAddressResponse(v)
+	}
+	sort.SliceStable(data, func(i, j int) bool {
+		if data[i].IsDefault != data[j].IsDefault {
+			return data[i].IsDefault
+		}
+		return data[i].ID < data[j].ID
+	})
+	return data
+}
+
+func (m *Mapper) AddressRequestToEntity(request AddressRequest, customerID, id int) domain.CustomerAddress {
+	return domain.CustomerAddress{
+		ID:         id,
+		CustomerID: customerID,
+		Label:      request.Label,
+		Recipient:  request.Recipient,
+		Phone:      phone.Format(request.Phone),
+		Street:     request.Street,
+		Village:    request.Village,
+		District:   request.District,
+		City:       request.City,
+		Province:   request.Province,
+		PostalCode: request.PostalCode,
+		IsDefault:  request.IsDefault,
+	}
+}
+
+func (m *Mapper) ToLoyaltyEntryResponse(entry domain.LoyaltyEntry) LoyaltyEntryResponse {
+	return LoyaltyEntryResponse{
+		ID:             entry.ID,
+		Type:           entry.Type,
+		Points:         entry.Points,
+		Amount:         entry.Amount,
+		Reference:      entry.Reference,
+		RelatedEntryID: entry.RelatedEntryID,
+		ExpiresAt:      entry.ExpiresAt,
+		CreatedAt:      entry.CreatedAt,
+	}
+}
+
+func (m *Mapper) ToWalletEntryResponse(entry domain.WalletEntry) WalletEntryResponse {
+	return WalletEntryResponse{
+		ID:        entry.ID,
+		Type:      entry.Type,
+		Amount:    entry.Amount,
+		Balance:   entry.Balance,
+		Reference: entry.Reference,
+		Reason:    entry.Reason,
+		StaffID:   entry.StaffID,
+		CreatedAt: entry.CreatedAt,
+	}
+}
+
+// ToWalletResponse maps a wallet ledger, read in the order of its ids, the
+// latest entry first.
+func (m *Mapper) ToWalletResponse(entries []domain.WalletEntry) WalletResponse {
+	var result = WalletResponse{History: make([]WalletEntryResponse, len(entries))}
+	for k, v := range entries {
+		result.History[len(entries)-1-k] = m.ToWalletEntryResponse(v)
+	}
+	if len(entries) > 0 {
+		result.Balance = entries[len(entries)-1].Balance
+	}
+	return result
+}
+
+func (m *Mapper) ToConsentResponse(consent domain.CustomerConsent) ConsentResponse {
+	return ConsentResponse{
+		Channel:   consent.Channel,
+		Granted:   consent.Granted,
+		Source:    consent.Source,
+		CreatedAt: consent.CreatedAt,
+	}
+}
+
+// ToConsentsResponse maps the consent changes, read in the order they were
+// appended, to the consent on every channel and the changes the latest first.
+func (m *Mapper) ToConsentsResponse(consents []domain.CustomerConsent) ConsentsResponse {
+	var result = ConsentsResponse{
+		Consents: make([]ConsentStatus, len(domain.ConsentChannels)),
+		History:  make([]ConsentResponse, len(consents)),
+	}
+	for k, v := range domain.ConsentChannels {
+		result.Consents[k] = ConsentStatus{Channel: v}
+	}
+	for k, v := range consents {
+		result.History[len(consents)-1-k] = m.ToConsentResponse(v)
+		for i := range result.Consents {
+			if result.Consents[i].Channel == v.Channel {
+				createdAt := v.CreatedAt
+				result.Consents[i] = ConsentStatus{Channel: v.Channel, Granted: v.Granted, Source: v.Source, UpdatedAt: &createdAt}
+			}
+		}
+	}
+	return result
+}
+
+// ToSaleResponse maps an earn entry with its lines and related entries, the
+// reversals of the related entries are its refund.
+func (m *Mapper) ToSaleResponse(entry domain.LoyaltyEntry) SaleResponse {
+	var lines = make([]SaleLineResponse, len(entry.Lines))
+	for k, v := range entry.Lines {
+		lines[k] = SaleLineResponse{
+			Product:  v.Product,
+			Category: v.Category,
+			Quantity: v.Quantity,
+			Amount:   v.Amount,
+		}
+	}
+	var refunded int64
+	for _, v := range entry.RelatedEntries {
+		if v.Type == domain.LoyaltyEntryReversal {
+			refunded -= v.Amount
+		}
+	}
+	return SaleResponse{
+		ID:             entry.ID,
+		Reference:      entry.Reference,
+		Amount:         entry.Amount,
+		RefundedAmount: refunded,
+		Points:         entry.Points,
+		Lines:          lines,
+		CreatedAt:      entry.CreatedAt,
+	}
+}
+
+func (m *Mapper) ToSalePaginationResponse(paginator *database.Paginator) SalePaginationResponse {
+	var paginationResponse SalePaginationResponse
+	if list, ok := paginator.Records.(*[]domain.LoyaltyEntry); ok {
+		var data = make([]SaleResponse, len(*list))
+		for k, v := range *list {
+			data[k] = m.ToSaleResponse(v)
+		}
+		links := utils.BuildPaginationLinks(paginator.Links.First, paginator.Links.Prev, paginator.Links.Next, paginator.Links.Last)
+		if paginator.IsCursorMode() {
+			paginationResponse = SalePaginationResponse{
+				Pagination: utils.BuildCursorPaginationInfo(
+					paginator.MaxPage,
+					paginator.Total,
+					paginator.PageSize,
+					paginator.Cursors.Next,
+					paginator.Cursors.Prev,
+					links),
+				Data: data,
+			}
+		} else {
+			paginationResponse = SalePaginationResponse{
+				Pagination: utils.BuildPaginationInfo(
+					paginator.MaxPage,
+					paginator.Total,
+					paginator.PageSize,
+					paginator.CurrentPage,
+					links),
+				Data: data,
+			}
+		}
+	}
+	return paginationResponse
+}
+
+// ToLoyaltyEntryResponses maps the entries of a loyalty ledger, read in the
+// order of their ids, the latest entry first.
+func (m *Mapper) ToLoyaltyEntryResponses(entries []domain.LoyaltyEntry) []LoyaltyEntryResponse {
+	var data = make([]LoyaltyEntryResponse, len(entries))
+	for k, v := range entries {
+		data[len(entries)-1-k] = m.ToLoyaltyEntryResponse(v)
+	}
+	return data
+}
+
+func (m *Mapper) ToErasureResponse(erasure domain.CustomerErasure) ErasureResponse {
+	return ErasureResponse{
+		ID:           erasure.ID,
+		CustomerID:   erasure.CustomerID,
+		PerformedBy:  erasure.PerformedBy,
+		AuthorizedBy: erasure.AuthorizedBy,
+		Reason:       erasure.Reason,
+		CreatedAt:    erasure.CreatedAt,
+	}
+}
+
+// ToDataExport maps the personal data of a customer, read in the order of the
+// ids of the records, the latest record first.
+func (m *Mapper) ToDataExport(data PersonalData, exportedAt time.Time) DataExport {
+	export := DataExport{
+		ExportedAt: exportedAt,
+		Profile: ProfileResponse{
+			Response:  m.ToCustomerResponse(data.Customer),
+			CreatedAt: data.Customer.CreatedAt,
+			UpdatedAt: data.Customer.UpdatedAt,
+		},
+		Sales:       []SaleResponse{},
+		Loyalty:     m.ToLoyaltyEntryResponses(data.LoyaltyEntries),
+		Wallet:      m.ToWalletResponse(data.WalletEntries),
+		TierChanges: m.ToTierChangeResponses(data.TierChanges),
+		Segments:    make([]string, len(data.Segments)),
+		Consents:    m.ToConsentsResponse(data.Consents),
+		Merges:      make([]MergeRecordResponse, len(data.Merges)),
+		Erasures:    make([]ErasureResponse, len(data.Erasures)),
+	}
+	for k := len(data.LoyaltyEntries) - 1; k >= 0; k-- {
+		if entry := data.LoyaltyEntries[k]; entry.Type == domain.LoyaltyEntryEarn && entry.Amount != 0 {
+			export.Sales = append(export.Sales, m.ToSaleResponse(entry))
+		}
+	}
+	for k, v := range data.Segments {
+		export.Segments[k] = v.Name
+	}
+	for k, v := range data.Merges {
+		export.Merges[len(data.Merges)-1-k] = MergeRecordResponse{
+			SurvivorID:  v.SurvivorID,
+			MergedID:    v.MergedID,
+			Name:        v.Name,
+			Email:       v.Email,
+			MobilePhone: v.MobilePhone,
+			CreatedAt:   v.CreatedAt,
+		}
+	}
+	for k, v := range data.Erasures {
+		export.Erasures[len(data.Erasures)-1-k] = m.ToErasureResponse(v)
+	}
+	return export
+}
+
+func (m *Mapper) ToTierChangeResponses(changes []domain.CustomerTierChange) []TierChangeResponse {
+	var data = make([]TierChangeResponse, len(changes))
+	for k, v := range changes {
+		data[k] = TierChangeResponse{
+			Tier:         v.Tier,
+			PreviousTier: v.PreviousTier,
+			Spend:        v.Spend,
+			Points:       v.Points,
+			CreatedAt:    v.CreatedAt,
+		}
+	}
+	return data
+}
+
+func (m *Mapper) ToSegmentResponse(segment domain.CustomerSegment) SegmentResponse {
+	return SegmentResponse{
+		ID:          segment.ID,
+		Name:        segment.Name,
+		Description: segment.Description,
+		Type:        segment.Type,
+		Rule:        segment.Rule,
+		MemberCount: segment.MemberCount,
+		EvaluatedAt: segment.EvaluatedAt,
+		CreatedAt:   segment.CreatedAt,
+		UpdatedAt:   segment.UpdatedAt,
+	}
+}
+
+func (m *Mapper) SegmentRequestToEntity(request SegmentRequest, id int) domain.CustomerSegment {
+	return domain.CustomerSegment{
+		ID:          id,
+		Name:        request.Name,
+		Description: request.Description,
+		Type:        request.Type,
+		Rule:        request.Rule,
+	}
+}
+
+// ToSparseResponse restricts the response to the fields requested with ?fields=.
+func (m *Mapper) ToSparseResponse(response Response, query utils.FieldsetQuery) interface{} {
+	return ResponseFieldset.Select(response, query)
+}
+
+// ToSparseResponses restricts every response to the fields requested with ?fields=.
+func (m *Mapper) ToSparseResponses(responses []Response, query utils.FieldsetQuery) []interface{} {
+	var data = make([]interface{}, len(responses))
+	for k, v := range responses {
+		data[k] = m.ToSparseResponse(v, query)
+	}
+	return data
+}
+
 func (m *Mapper) CustomerStoreRequestToEntity(request StoreRequest) domain.Customer {
 	return domain.Customer{
 		Name:        request.Name,
 		Email:       request.Email,
-		MobilePhone: request.MobilePhone,
+		MobilePhone: phone.Format(request.MobilePhone),
 		Password:    request.Password,
 	}
 }
 
-func (m *Mapper) CustomerUpdateRequestToEntity(request UpdateRequest, id int) domain.Customer {
+func (m *Mapper) CustomerUpdateRequestToEntity(request UpdateRequest, id, version int) domain.Customer {
 	var entity domain.Customer
 	entity.ID = id
+	entity.Version = version
 	entity.Name = request.Name
 	entity.Email = request.Email
-	entity.MobilePhone = request.MobilePhone
+	entity.MobilePhone = phone.Format(request.MobilePhone)
 	if !strings.EqualFold(request.Password, "") {
 		entity.Password = request.Password
 	}
@@ -45,28 +375,89 @@ func (m *Mapper) CustomerUpdateRequestToEntity(request UpdateRequest, id int) do
 	return entity
 }
 
+// ToPatchRequest returns the document of the customer the merge patch is applied
+// to, the password is never part of it.
+func (m *Mapper) ToPatchRequest(customer domain.Customer) PatchRequest {
+	return PatchRequest{
+		Name:        customer.Name,
+		Email:       customer.Email,
+		MobilePhone: customer.MobilePhone,
+	}
+}
+
+// CustomerPatchRequestToEntity applies the patched document to the customer and
+// returns the columns whose value changed.
+func (m *Mapper) CustomerPatchRequestToEntity(request PatchRequest, entity domain.Customer) (domain.Customer, []string) {
+	var columns []string
+	if request.Name != entity.Name {
+		entity.Name = request.Name
+		columns = append(columns, "name")
+	}
+	if request.Email != entity.Email {
+		entity.Email = request.Email
+		columns = append(columns, "email")
+	}
+	if mobilePhone := phone.Format(request.MobilePhone); mobilePhone != entity.MobilePhone {
+		entity.MobilePhone = mobilePhone
+		columns = append(columns, "mobile_phone")
+	}
+	if !strings.EqualFold(request.Password, "") {
+		entity.Password = request.Password
+		columns = append(columns, "password")
+	}
+
+	return entity, columns
+}
+
 func (m *Mapper) ToCustomerPaginationResponse(paginator *database.Paginator) PaginationResponse {
 	var paginationResponse PaginationResponse
 	if list, ok := paginator.Records.(*[]domain.Customer); ok {
 		var data = make([]Response, len(*list))
 		for k, v := range *list {
-			data[k] = Response{
-				ID:          v.ID,
-				Name:        v.Name,
-				Email:       v.Email,
-				MobilePhone: v.MobilePhone,
-			}
+			data[k] = m.ToCustomerResponse(v)
 		}
-		paginationResponse = PaginationResponse{
-			Pagination: utils.BuildPaginationInfo(
-				paginator.MaxPage,
-				paginator.Total,
-				paginator.PageSize,
-				paginator.CurrentPage,
-				utils.BuildPaginationLinks(paginator.Links.First, paginator.Links.Prev, paginator.Links.Next, paginator.Links.Last)),
-			Data: data,
+		links := utils.BuildPaginationLinks(paginator.Links.First, paginator.Links.Prev, paginator.Links.Next, paginator.Links.Last)
+		if paginator.IsCursorMode() {
+			paginationResponse = PaginationResponse{
+				Pagination: utils.BuildCursorPaginationInfo(
+					paginator.MaxPage,
+					paginator.Total,
+					paginator.PageSize,
+					paginator.Cursors.Next,
+					paginator.Cursors.Prev,
+					links),
+				Data: data,
+			}
+		} else {
+			paginationResponse = PaginationResponse{
+				Pagination: utils.BuildPaginationInfo(
+					paginator.MaxPage,
+					paginator.Total,
+					paginator.PageSize,
+					paginator.CurrentPage,
+					links),
+				Data: data,
+			}
 		}
 	}
 
 	return paginationResponse
 }
+
+// ToImportRows maps the records of an import file, read with the ImportColumns,
+// to the store requests of the customers.
+func (m *Mapper) ToImportRows(records []spreadsheet.Record) []ImportRow {
+	var rows = make([]ImportRow, len(records))
+	for k, v := range records {
+		rows[k] = ImportRow{
+			Row: v.Row,
+			Request: StoreRequest{
+				Name:        v.Values["name"],
+				Email:       v.Values["email"],
+				MobilePhone: v.Values["mobile_phone"],
+				Password:    v.Values["password"],
+			},
+		}
+	}
+	return rows
+}
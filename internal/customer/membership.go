@@ -0,0 +1,107 @@
+package customer
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultMembershipTiers membership tiers parsed by ParseMembershipTiers when
+// none are configured.
+const DefaultMembershipTiers = "silver:0:0:1:0|gold:5000000:500:1.25:5|platinum:20000000:2000:1.5:10"
+
+// MembershipTier membership tier a customer qualifies for by its spend or the
+// points it earned over the last 12 months.
+type MembershipTier struct {
+	Name string
+	// MinSpend spend qualifying for the tier, zero when the tier isn't reached by spend
+	MinSpend int64
+	// MinPoints earned points qualifying for the tier, zero when the tier isn't
+	// reached by points
+	MinPoints int
+	// Multiplier multiplier of the points earned by the customers of the tier
+	Multiplier float64
+	// Discount discount in percent granted at checkout to the customers of the tier
+	Discount float64
+}
+
+// MembershipTiers membership tiers from the lowest to the highest, the lowest
+// tier is the tier of every customer not qualifying for a higher tier.
+type MembershipTiers []MembershipTier
+
+// ParseMembershipTiers parses tiers separated by |, written
+// name:minSpend:minPoints:multiplier:discount. The tiers are ordered by their
+// MinSpend then MinPoints.
+func ParseMembershipTiers(s string) (MembershipTiers, error) {
+	var tiers MembershipTiers
+	for _, value := range strings.Split(s, "|") {
+		parts := strings.Split(strings.TrimSpace(value), ":")
+		if len(parts) != 5 || parts[0] == "" {
+			return nil, fmt.Errorf("membership tier %q: want name:minSpend:minPoints:multiplier:discount", value)
+		}
+		var tier = MembershipTier{Name: parts[0]}
+		var err error
+		if tier.MinSpend, err = strconv.ParseInt(parts[1], 10, 64); err != nil {
+			return nil, fmt.Errorf("membership tier %q: %w", value, err)
+		}
+		if tier.MinPoints, err = strconv.Atoi(parts[2]); err != nil {
+			return nil, fmt.Errorf("membership tier %q: %w", value, err)
+		}
+		if tier.Multiplier, err = strconv.ParseFloat(parts[3], 64); err != nil {
+			return nil, fmt.Errorf("membership tier %q: %w", value, err)
+		}
+		if tier.Discount, err = strconv.ParseFloat(parts[4], 64); err != nil {
+			return nil, fmt.Errorf("membership tier %q: %w", value, err)
+		}
+		if tiers.Find(tier.Name) != nil {
+			return nil, fmt.Errorf("membership tier %q: duplicate name", value)
+		}
+		tiers = append(tiers, tier)
+	}
+	sort.SliceStable(tiers, func(i, j int) bool {
+		if tiers[i].MinSpend != tiers[j].MinSpend {
+			return tiers[i].MinSpend < tiers[j].MinSpend
+		}
+		return tiers[i].MinPoints < tiers[j].MinPoints
+	})
+	return tiers, nil
+}
+
+// Base lowest tier, the zero tier when there are no tiers.
+func (t MembershipTiers) Base() MembershipTier {
+	if len(t) == 0 {
+		return MembershipTier{Multiplier: 1}
+	}
+	return t[0]
+}
+
+// Find returns the tier named name, nil when there is none.
+func (t MembershipTiers) Find(name string) *MembershipTier {
+	for i := range t {
+		if t[i].Name == name {
+			return &t[i]
+		}
+	}
+	return nil
+}
+
+// Current tier named name, the lowest tier when there is none like a tier
+// no longer configured.
+func (t MembershipTiers) Current(name string) MembershipTier {
+	if tier := t.Find(name); tier != nil {
+		return *tier
+	}
+	return t.Base()
+}
+
+// Qualify returns the highest tier reached by the spend or the points.
+func (t MembershipTiers) Qualify(spend int64, points int) MembershipTier {
+	var result = t.Base()
+	for _, tier := range t {
+		if (tier.MinSpend > 0 && spend >= tier.MinSpend) || (tier.MinPoints > 0 && points >= tier.MinPoints) {
+			result = tier
+		}
+	}
+	return result
+}
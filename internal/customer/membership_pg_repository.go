@@ -0,0 +1,17 @@
+package customer
+
+import (
+	"context"
+	"github.com/alpakih/point-of-sales/internal/domain"
+	"time"
+)
+
+// MembershipPgRepository stores the membership tier changes of the customers and
+// reads their activity from the loyalty ledgers. The customerIDs arguments
+// restrict the customers read, every active customer is read when nil.
+type MembershipPgRepository interface {
+	FindActivities(ctx context.Context, from, to time.Time, customerIDs []int) ([]MembershipActivity, error)
+	FindCurrentTiers(ctx context.Context, customerIDs []int) ([]domain.CustomerTierChange, error)
+	FindTierChanges(ctx context.Context, customerID int) ([]domain.CustomerTierChange, error)
+	AppendTierChanges(ctx context.Context, changes []domain.CustomerTierChange) error
+}
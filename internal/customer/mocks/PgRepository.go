@@ -5,6 +5,8 @@ package mocks
 import (
 	context "context"
 
+	customer "github.com/alpakih/point-of-sales/internal/customer"
+
 	database "github.com/alpakih/point-of-sales/pkg/database"
 
 	domain "github.com/alpakih/point-of-sales/internal/domain"
@@ -69,13 +71,13 @@ func (_m *PgRepository) Delete(ctx context.Context, id int) error {
 	return r0
 }
 
-// FindCustomers provides a mock function with given fields: ctx, page, size, search, order
-func (_m *PgRepository) FindCustomers(ctx context.Context, page int, size int, search string, order string) (*database.Paginator, error) {
-	ret := _m.Called(ctx, page, size, search, order)
+// FindCustomers provides a mock function with given fields: ctx, query
+func (_m *PgRepository) FindCustomers(ctx context.Context, query customer.ListQuery) (*database.Paginator, error) {
+	ret := _m.Called(ctx, query)
 
 	var r0 *database.Paginator
-	if rf, ok := ret.Get(0).(func(context.Context, int, int, string, string) *database.Paginator); ok {
-		r0 = rf(ctx, page, size, search, order)
+	if rf, ok := ret.Get(0).(func(context.Context, customer.ListQuery) *database.Paginator); ok {
+		r0 = rf(ctx, query)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*database.Paginator)
@@ -83,8 +85,8 @@ func (_m *PgRepository) FindCustomers(ctx context.Context, page int, size int, s
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, int, int, string, string) error); ok {
-		r1 = rf(ctx, page, size, search, order)
+	if rf, ok := ret.Get(1).(func(context.Context, customer.ListQuery) error); ok {
+		r1 = rf(ctx, query)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -92,8 +94,22 @@ func (_m *PgRepository) FindCustomers(ctx context.Context, page int, size int, s
 	return r0, r1
 }
 
-// FindOneCustomerByID provides a mock function with given fields: ctx, id
-func (_m *PgRepository) FindOneCustomerByID(ctx context.Context, id int) (domain.Customer, error) {
+// FindCustomersInBatches provides a mock function with given fields: ctx, query, batchSize, fn
+func (_m *PgRepository) FindCustomersInBatches(ctx context.Context, query customer.ListQuery, batchSize int, fn func([]domain.Customer) error) error {
+	ret := _m.Called(ctx, query, batchSize, fn)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, customer.ListQuery, int, func([]domain.Customer) error) error); ok {
+		r0 = rf(ctx, query, batchSize, fn)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// FindDeletedCustomerByID provides a mock function with given fields: ctx, id
+func (_m *PgRepository) FindDeletedCustomerByID(ctx context.Context, id int) (domain.Customer, error) {
 	ret := _m.Called(ctx, id)
 
 	var r0 domain.Customer
@@ -113,13 +129,113 @@ func (_m *PgRepository) FindOneCustomerByID(ctx context.Context, id int) (domain
 	return r0, r1
 }
 
-// Update provides a mock function with given fields: ctx, entity
-func (_m *PgRepository) Update(ctx context.Context, entity domain.Customer) error {
-	ret := _m.Called(ctx, entity)
+// FindDuplicateCandidates provides a mock function with given fields: ctx, entity, limit
+func (_m *PgRepository) FindDuplicateCandidates(ctx context.Context, entity domain.Customer, limit int) ([]domain.Customer, error) {
+	ret := _m.Called(ctx, entity, limit)
+
+	var r0 []domain.Customer
+	if rf, ok := ret.Get(0).(func(context.Context, domain.Customer, int) []domain.Customer); ok {
+		r0 = rf(ctx, entity, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Customer)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, domain.Customer, int) error); ok {
+		r1 = rf(ctx, entity, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindOneCustomerByID provides a mock function with given fields: ctx, id, preloads
+func (_m *PgRepository) FindOneCustomerByID(ctx context.Context, id int, preloads ...string) (domain.Customer, error) {
+	_va := make([]interface{}, len(preloads))
+	for _i := range preloads {
+		_va[_i] = preloads[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, ctx, id)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	var r0 domain.Customer
+	if rf, ok := ret.Get(0).(func(context.Context, int, ...string) domain.Customer); ok {
+		r0 = rf(ctx, id, preloads...)
+	} else {
+		r0 = ret.Get(0).(domain.Customer)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int, ...string) error); ok {
+		r1 = rf(ctx, id, preloads...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Merge provides a mock function with given fields: ctx, survivor, customers
+func (_m *PgRepository) Merge(ctx context.Context, survivor domain.Customer, customers []domain.Customer) error {
+	ret := _m.Called(ctx, survivor, customers)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, domain.Customer) error); ok {
-		r0 = rf(ctx, entity)
+	if rf, ok := ret.Get(0).(func(context.Context, domain.Customer, []domain.Customer) error); ok {
+		r0 = rf(ctx, survivor, customers)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Purge provides a mock function with given fields: ctx, id
+func (_m *PgRepository) Purge(ctx context.Context, id int) error {
+	ret := _m.Called(ctx, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Restore provides a mock function with given fields: ctx, id
+func (_m *PgRepository) Restore(ctx context.Context, id int) error {
+	ret := _m.Called(ctx, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Update provides a mock function with given fields: ctx, entity, columns
+func (_m *PgRepository) Update(ctx context.Context, entity domain.Customer, columns ...string) error {
+	_va := make([]interface{}, len(columns))
+	for _i := range columns {
+		_va[_i] = columns[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, ctx, entity)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.Customer, ...string) error); ok {
+		r0 = rf(ctx, entity, columns...)
 	} else {
 		r0 = ret.Error(0)
 	}
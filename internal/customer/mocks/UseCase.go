@@ -7,6 +7,8 @@ import (
 
 	customer "github.com/alpakih/point-of-sales/internal/customer"
 	mock "github.com/stretchr/testify/mock"
+
+	utils "github.com/alpakih/point-of-sales/pkg/utils"
 )
 
 // UseCase is an autogenerated mock type for the UseCase type
@@ -28,16 +30,30 @@ func (_m *UseCase) DeleteCustomer(ctx context.Context, id int) error {
 	return r0
 }
 
-// GetCustomerByID provides a mock function with given fields: ctx, id
-func (_m *UseCase) GetCustomerByID(ctx context.Context, id int) (*customer.Response, error) {
+// ExportCustomers provides a mock function with given fields: ctx, query, fn
+func (_m *UseCase) ExportCustomers(ctx context.Context, query customer.ListQuery, fn func([]customer.Response) error) error {
+	ret := _m.Called(ctx, query, fn)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, customer.ListQuery, func([]customer.Response) error) error); ok {
+		r0 = rf(ctx, query, fn)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// FindDuplicateCandidates provides a mock function with given fields: ctx, id
+func (_m *UseCase) FindDuplicateCandidates(ctx context.Context, id int) ([]customer.DuplicateCandidate, error) {
 	ret := _m.Called(ctx, id)
 
-	var r0 *customer.Response
-	if rf, ok := ret.Get(0).(func(context.Context, int) *customer.Response); ok {
+	var r0 []customer.DuplicateCandidate
+	if rf, ok := ret.Get(0).(func(context.Context, int) []customer.DuplicateCandidate); ok {
 		r0 = rf(ctx, id)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*customer.Response)
+			r0 = ret.Get(0).([]customer.DuplicateCandidate)
 		}
 	}
 
@@ -51,13 +67,36 @@ func (_m *UseCase) GetCustomerByID(ctx context.Context, id int) (*customer.Respo
 	return r0, r1
 }
 
-// GetCustomers provides a mock function with given fields: ctx, page, size, search, order
-func (_m *UseCase) GetCustomers(ctx context.Context, page int, size int, search string, order string) (*customer.PaginationResponse, error) {
-	ret := _m.Called(ctx, page, size, search, order)
+// GetCustomerByID provides a mock function with given fields: ctx, id, fieldset
+func (_m *UseCase) GetCustomerByID(ctx context.Context, id int, fieldset utils.FieldsetQuery) (*customer.Response, error) {
+	ret := _m.Called(ctx, id, fieldset)
+
+	var r0 *customer.Response
+	if rf, ok := ret.Get(0).(func(context.Context, int, utils.FieldsetQuery) *customer.Response); ok {
+		r0 = rf(ctx, id, fieldset)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*customer.Response)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int, utils.FieldsetQuery) error); ok {
+		r1 = rf(ctx, id, fieldset)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetCustomers provides a mock function with given fields: ctx, query
+func (_m *UseCase) GetCustomers(ctx context.Context, query customer.ListQuery) (*customer.PaginationResponse, error) {
+	ret := _m.Called(ctx, query)
 
 	var r0 *customer.PaginationResponse
-	if rf, ok := ret.Get(0).(func(context.Context, int, int, string, string) *customer.PaginationResponse); ok {
-		r0 = rf(ctx, page, size, search, order)
+	if rf, ok := ret.Get(0).(func(context.Context, customer.ListQuery) *customer.PaginationResponse); ok {
+		r0 = rf(ctx, query)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*customer.PaginationResponse)
@@ -65,8 +104,91 @@ func (_m *UseCase) GetCustomers(ctx context.Context, page int, size int, search
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, int, int, string, string) error); ok {
-		r1 = rf(ctx, page, size, search, order)
+	if rf, ok := ret.Get(1).(func(context.Context, customer.ListQuery) error); ok {
+		r1 = rf(ctx, query)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MergeCustomers provides a mock function with given fields: ctx, request
+func (_m *UseCase) MergeCustomers(ctx context.Context, request customer.MergeRequest) (*customer.Response, error) {
+	ret := _m.Called(ctx, request)
+
+	var r0 *customer.Response
+	if rf, ok := ret.Get(0).(func(context.Context, customer.MergeRequest) *customer.Response); ok {
+		r0 = rf(ctx, request)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*customer.Response)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, customer.MergeRequest) error); ok {
+		r1 = rf(ctx, request)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PatchCustomer provides a mock function with given fields: ctx, patch, id, version
+func (_m *UseCase) PatchCustomer(ctx context.Context, patch []byte, id int, version int) (*customer.Response, error) {
+	ret := _m.Called(ctx, patch, id, version)
+
+	var r0 *customer.Response
+	if rf, ok := ret.Get(0).(func(context.Context, []byte, int, int) *customer.Response); ok {
+		r0 = rf(ctx, patch, id, version)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*customer.Response)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, []byte, int, int) error); ok {
+		r1 = rf(ctx, patch, id, version)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PurgeCustomer provides a mock function with given fields: ctx, id
+func (_m *UseCase) PurgeCustomer(ctx context.Context, id int) error {
+	ret := _m.Called(ctx, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RestoreCustomer provides a mock function with given fields: ctx, id
+func (_m *UseCase) RestoreCustomer(ctx context.Context, id int) (*customer.Response, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 *customer.Response
+	if rf, ok := ret.Get(0).(func(context.Context, int) *customer.Response); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*customer.Response)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, id)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -97,16 +219,25 @@ func (_m *UseCase) StoreCustomer(ctx context.Context, request customer.StoreRequ
 	return r0, r1
 }
 
-// UpdateCustomer provides a mock function with given fields: ctx, entity, id
-func (_m *UseCase) UpdateCustomer(ctx context.Context, entity customer.UpdateRequest, id int) error {
-	ret := _m.Called(ctx, entity, id)
+// UpdateCustomer provides a mock function with given fields: ctx, entity, id, version
+func (_m *UseCase) UpdateCustomer(ctx context.Context, entity customer.UpdateRequest, id int, version int) (*customer.Response, error) {
+	ret := _m.Called(ctx, entity, id, version)
 
-	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, customer.UpdateRequest, int) error); ok {
-		r0 = rf(ctx, entity, id)
+	var r0 *customer.Response
+	if rf, ok := ret.Get(0).(func(context.Context, customer.UpdateRequest, int, int) *customer.Response); ok {
+		r0 = rf(ctx, entity, id, version)
 	} else {
-		r0 = ret.Error(0)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*customer.Response)
+		}
 	}
 
-	return r0
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, customer.UpdateRequest, int, int) error); ok {
+		r1 = rf(ctx, entity, id, version)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
@@ -1,29 +1,471 @@
 package customer
 
-import "github.com/alpakih/point-of-sales/pkg/utils"
+import (
+	"github.com/alpakih/point-of-sales/pkg/utils"
+	"time"
+)
 
 type StoreRequest struct {
 	Name        string `json:"name" validate:"required"`
 	Email       string `json:"email" validate:"required,email"`
-	MobilePhone string `json:"mobile_phone" validate:"required,min=9,max=14"`
+	MobilePhone string `json:"mobile_phone" validate:"required,mobile_phone"`
 	Password    string `json:"password" validate:"required"`
 }
 
 type UpdateRequest struct {
 	Name        string `json:"name" validate:"required"`
 	Email       string `json:"email" validate:"required"`
-	MobilePhone string `json:"mobile_phone" validate:"required,min=9,max=14"`
+	MobilePhone string `json:"mobile_phone" validate:"required,mobile_phone"`
 	Password    string `json:"password"`
 }
 
+// PatchRequest customer document a JSON Merge Patch is applied to, the members
+// removed by a null are left empty and must pass validation. An empty password
+// keeps the current password.
+type PatchRequest struct {
+	Name        string `json:"name" validate:"required"`
+	Email       string `json:"email" validate:"required,email"`
+	MobilePhone string `json:"mobile_phone" validate:"required,mobile_phone"`
+	Password    string `json:"password,omitempty"`
+}
+
 type Response struct {
 	ID          int    `json:"id"`
 	Name        string `json:"name"`
 	Email       string `json:"email"`
 	MobilePhone string `json:"mobilePhone"`
+	Version     int    `json:"version"`
+	// DeletedAt deletion time of a soft-deleted customer listed with ?with_deleted=true
+	DeletedAt *time.Time `json:"deletedAt,omitempty"`
+	// Addresses addresses of the customer requested with ?include=addresses
+	Addresses *[]AddressResponse `json:"addresses,omitempty"`
+	// Loyalty loyalty points balance of the customer requested with ?include=loyalty
+	Loyalty *LoyaltyBalanceResponse `json:"loyalty,omitempty"`
+}
+
+// ResponseFieldset fields and includes of Response allowed in ?fields= and
+// ?include=, includes map to the preloaded associations of domain.Customer.
+var ResponseFieldset = utils.NewFieldset(Response{}, map[string]string{
+	"addresses": "Addresses",
+	"loyalty":   "LoyaltyEntries",
+})
+
+// AddressRequest address of a customer, setting IsDefault makes it the default
+// address in place of the current one.
+type AddressRequest struct {
+	Label      string `json:"label" validate:"required,max=50"`
+	Recipient  string `json:"recipient" validate:"required,max=50"`
+	Phone      string `json:"phone" validate:"required,mobile_phone"`
+	Street     string `json:"street" validate:"required,max=255"`
+	Village    string `json:"village" validate:"required,max=100"`
+	District   string `json:"district" validate:"required,max=100"`
+	City       string `json:"city" validate:"required,max=100"`
+	Province   string `json:"province" validate:"required,max=100"`
+	PostalCode string `json:"postal_code" validate:"required,len=5,number_format"`
+	IsDefault  bool   `json:"is_default"`
+}
+
+type AddressResponse struct {
+	ID         int    `json:"id"`
+	Label      string `json:"label"`
+	Recipient  string `json:"recipient"`
+	Phone      string `json:"phone"`
+	Street     string `json:"street"`
+	Village    string `json:"village"`
+	District   string `json:"district"`
+	City       string `json:"city"`
+	Province   string `json:"province"`
+	PostalCode string `json:"postalCode"`
+	IsDefault  bool   `json:"isDefault"`
+}
+
+// EarnLine line of a sale earning loyalty points, Product is the name or code
+// of the product sold.
+type EarnLine struct {
+	Product  string `json:"product" validate:"max=100"`
+	Category string `json:"category" validate:"max=50"`
+	Quantity int    `json:"quantity" validate:"min=0"`
+	Amount   int64  `json:"amount" validate:"min=0"`
+}
+
+// EarnRequest sale earning loyalty points, Reference is the number of the sale.
+type EarnRequest struct {
+	Reference string     `json:"reference" validate:"required,max=100"`
+	Lines     []EarnLine `json:"lines" validate:"required,min=1,dive"`
+}
+
+// RedeemRequest loyalty points redeemed as a tender of the sale Reference.
+type RedeemRequest struct {
+	Reference string `json:"reference" validate:"required,max=100"`
+	Points    int    `json:"points" validate:"required,min=1"`
+}
+
+// ReversalRequest refunded sale whose earned and redeemed loyalty points are
+// reversed.
+type ReversalRequest struct {
+	Reference string `json:"reference" validate:"required,max=100"`
+}
+
+type LoyaltyEntryResponse struct {
+	ID             int        `json:"id"`
+	Type           string     `json:"type"`
+	Points         int        `json:"points"`
+	Amount         int64      `json:"amount,omitempty"`
+	Reference      string     `json:"reference"`
+	RelatedEntryID *int       `json:"relatedEntryId,omitempty"`
+	ExpiresAt      *time.Time `json:"expiresAt,omitempty"`
+	CreatedAt      time.Time  `json:"createdAt"`
+}
+
+// LoyaltyExpiry points expiring at the same time.
+type LoyaltyExpiry struct {
+	Points    int       `json:"points"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// LoyaltyResponse balance of the loyalty points of a customer and the entries
+// of its ledger, the latest first.
+type LoyaltyResponse struct {
+	Balance    int                    `json:"balance"`
+	NextExpiry *LoyaltyExpiry         `json:"nextExpiry,omitempty"`
+	History    []LoyaltyEntryResponse `json:"history"`
+}
+
+// LoyaltyBalanceResponse balance of the loyalty points of a customer included
+// in its response, without the entries of its ledger.
+type LoyaltyBalanceResponse struct {
+	Balance    int            `json:"balance"`
+	NextExpiry *LoyaltyExpiry `json:"nextExpiry,omitempty"`
+}
+
+// RedemptionResponse redemption entry and the amount it pays for at checkout.
+type RedemptionResponse struct {
+	Entry   LoyaltyEntryResponse `json:"entry"`
+	Amount  int64                `json:"amount"`
+	Balance int                  `json:"balance"`
+}
+
+// MembershipActivity spend and points earned by a customer over a period.
+type MembershipActivity struct {
+	CustomerID int
+	Spend      int64
+	Points     int
+}
+
+type TierChangeResponse struct {
+	Tier         string    `json:"tier"`
+	PreviousTier string    `json:"previousTier"`
+	Spend        int64     `json:"spend"`
+	Points       int       `json:"points"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+// MembershipResponse membership tier of a customer with its benefits, the
+// rolling spend and points of the last 12 months and the changes of its tier,
+// the latest first.
+type MembershipResponse struct {
+	Tier       string               `json:"tier"`
+	Multiplier float64              `json:"multiplier"`
+	Discount   float64              `json:"discount"`
+	Spend      int64                `json:"spend"`
+	Points     int                  `json:"points"`
+	History    []TierChangeResponse `json:"history"`
+}
+
+// SegmentRequest segment of customers, Rule is required by the dynamic
+// segments and forbidden to the static ones.
+type SegmentRequest struct {
+	Name        string `json:"name" validate:"required,max=50"`
+	Description string `json:"description" validate:"max=255"`
+	Type        string `json:"type" validate:"required,oneof=static dynamic"`
+	Rule        string `json:"rule" validate:"required_if=Type dynamic,excluded_if=Type static,max=1000"`
+}
+
+// SegmentMembersRequest customers added to a static segment.
+type SegmentMembersRequest struct {
+	CustomerIDs []int `json:"customer_ids" validate:"required,min=1,max=1000,unique,dive,required"`
+}
+
+type SegmentResponse struct {
+	ID          int        `json:"id"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Type        string     `json:"type"`
+	Rule        string     `json:"rule,omitempty"`
+	MemberCount int        `json:"memberCount"`
+	EvaluatedAt *time.Time `json:"evaluatedAt,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	UpdatedAt   time.Time  `json:"updatedAt"`
+}
+
+// SaleStats spend and number of sales of a customer over a period.
+type SaleStats struct {
+	CustomerID int
+	Spend      int64
+	Visits     int
+}
+
+// SaleSummary number of sales, spend and first and last sale of a customer, the
+// sales refunded left out.
+type SaleSummary struct {
+	Visits     int
+	Spend      int64
+	FirstVisit *time.Time
+	LastVisit  *time.Time
+}
+
+// FavouriteProduct product bought by a customer with the quantity and amount
+// bought.
+type FavouriteProduct struct {
+	Product  string `json:"product"`
+	Quantity int    `json:"quantity"`
+	Amount   int64  `json:"amount"`
+}
+
+type SaleLineResponse struct {
+	Product  string `json:"product,omitempty"`
+	Category string `json:"category,omitempty"`
+	Quantity int    `json:"quantity"`
+	Amount   int64  `json:"amount"`
+}
+
+// SaleResponse sale of a customer, RefundedAmount is the amount of its refund.
+type SaleResponse struct {
+	ID             int                `json:"id"`
+	Reference      string             `json:"reference"`
+	Amount         int64              `json:"amount"`
+	RefundedAmount int64              `json:"refundedAmount"`
+	Points         int                `json:"points"`
+	Lines          []SaleLineResponse `json:"lines"`
+	CreatedAt      time.Time          `json:"createdAt"`
+}
+
+type SalePaginationResponse struct {
+	Pagination utils.Pagination
+	Data       []SaleResponse
+}
+
+// SummaryResponse purchase summary of a customer, the sales refunded left out,
+// LifetimeValue is the spend projected over the customer lifespan.
+type SummaryResponse struct {
+	Visits            int                `json:"visits"`
+	TotalSpend        int64              `json:"totalSpend"`
+	AverageBasket     int64              `json:"averageBasket"`
+	LastVisit         *time.Time         `json:"lastVisit"`
+	FavouriteProducts []FavouriteProduct `json:"favouriteProducts"`
+	LifetimeValue     int64              `json:"lifetimeValue"`
+}
+
+// WalletRequest top-up, spend at checkout or refund of a sale to the wallet,
+// Reference is the number of the payment or the sale.
+type WalletRequest struct {
+	Reference string `json:"reference" validate:"required,max=100"`
+	Amount    int64  `json:"amount" validate:"required,min=1"`
+	Reason    string `json:"reason" validate:"max=255"`
+	StaffID   string `json:"staff_id" validate:"max=50"`
+}
+
+// WalletAdjustmentRequest manual adjustment of the wallet, a negative Amount
+// debits it.
+type WalletAdjustmentRequest struct {
+	Reference string `json:"reference" validate:"max=100"`
+	Amount    int64  `json:"amount" validate:"required"`
+	Reason    string `json:"reason" validate:"required,max=255"`
+	StaffID   string `json:"staff_id" validate:"required,max=50"`
+}
+
+type WalletEntryResponse struct {
+	ID        int       `json:"id"`
+	Type      string    `json:"type"`
+	Amount    int64     `json:"amount"`
+	Balance   int64     `json:"balance"`
+	Reference string    `json:"reference,omitempty"`
+	Reason    string    `json:"reason,omitempty"`
+	StaffID   string    `json:"staffId,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// WalletResponse store credit of a customer with its ledger, the latest entry
+// first.
+type WalletResponse struct {
+	Balance int64                 `json:"balance"`
+	History []WalletEntryResponse `json:"history"`
+}
+
+// ConsentChange consent granted or withdrawn on a channel.
+type ConsentChange struct {
+	// Channel one of domain.ConsentChannels, checked by the handler
+	Channel string `json:"channel" validate:"required"`
+	Granted *bool  `json:"granted" validate:"required"`
+}
+
+// ConsentRequest changes of the marketing consents of a customer collected by
+// Source.
+type ConsentRequest struct {
+	Source   string          `json:"source" validate:"required,oneof=pos web import"`
+	Consents []ConsentChange `json:"consents" validate:"required,min=1,unique=Channel,dive"`
+}
+
+type ConsentResponse struct {
+	Channel   string    `json:"channel"`
+	Granted   bool      `json:"granted"`
+	Source    string    `json:"source"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// ConsentStatus consent of a customer on a channel, UpdatedAt is nil when it was
+// never collected and the consent isn't granted.
+type ConsentStatus struct {
+	Channel   string     `json:"channel"`
+	Granted   bool       `json:"granted"`
+	Source    string     `json:"source,omitempty"`
+	UpdatedAt *time.Time `json:"updatedAt"`
+}
+
+// ConsentsResponse consents of a customer on every channel with their changes,
+// the latest first.
+type ConsentsResponse struct {
+	Consents []ConsentStatus   `json:"consents"`
+	History  []ConsentResponse `json:"history"`
+}
+
+// ErasureRequest erasure of the personal data of a customer, PerformedBy
+// identifies the staff performing it.
+type ErasureRequest struct {
+	PerformedBy string `json:"performed_by" validate:"required,max=50"`
+	Reason      string `json:"reason" validate:"max=255"`
+	// AuthorizedBy identity of the admin API key the erasure was requested with,
+	// set by the handler
+	AuthorizedBy string `json:"-"`
+}
+
+type ErasureResponse struct {
+	ID           int       `json:"id"`
+	CustomerID   int       `json:"customerId"`
+	PerformedBy  string    `json:"performedBy"`
+	AuthorizedBy string    `json:"authorizedBy,omitempty"`
+	Reason       string    `json:"reason,omitempty"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+// MergeRecordResponse audit record of a merge the customer was part of, the
+// contact details are those of the merged customer.
+type MergeRecordResponse struct {
+	SurvivorID  int       `json:"survivorId"`
+	MergedID    int       `json:"mergedId"`
+	Name        string    `json:"name"`
+	Email       string    `json:"email"`
+	MobilePhone string    `json:"mobilePhone"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// ProfileResponse profile of a customer with its addresses and the time it
+// was created and updated.
+type ProfileResponse struct {
+	Response
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// DataExport everything stored about a customer, the records the latest first.
+type DataExport struct {
+	ExportedAt  time.Time              `json:"exportedAt"`
+	Profile     ProfileResponse        `json:"profile"`
+	Sales       []SaleResponse         `json:"sales"`
+	Loyalty     []LoyaltyEntryResponse `json:"loyalty"`
+	Wallet      WalletResponse         `json:"wallet"`
+	TierChanges []TierChangeResponse   `json:"tierChanges"`
+	Segments    []string               `json:"segments"`
+	Consents    ConsentsResponse       `json:"consents"`
+	Merges      []MergeRecordResponse  `json:"merges"`
+	Erasures    []ErasureResponse      `json:"erasures"`
+}
+
+// DataExportFile file of the ZIP bundle of a DataExport.
+type DataExportFile struct {
+	Name string
+	Data interface{}
+}
+
+// Files splits the export in the files of its ZIP bundle, one per section.
+func (e DataExport) Files() []DataExportFile {
+	return []DataExportFile{
+		{Name: "profile.json", Data: e.Profile},
+		{Name: "sales.json", Data: e.Sales},
+		{Name: "loyalty.json", Data: e.Loyalty},
+		{Name: "wallet.json", Data: e.Wallet},
+		{Name: "tier-changes.json", Data: e.TierChanges},
+		{Name: "segments.json", Data: e.Segments},
+		{Name: "consents.json", Data: e.Consents},
+		{Name: "merges.json", Data: e.Merges},
+		{Name: "erasures.json", Data: e.Erasures},
+	}
+}
+
+// MergeRequest customers merged into the survivor customer.
+type MergeRequest struct {
+	SurvivorID int   `json:"survivor_id" validate:"required"`
+	MergedIDs  []int `json:"merged_ids" validate:"required,min=1,unique,dive,required"`
+}
+
+// Reasons of a duplicate candidate.
+const (
+	DuplicateReasonEmail       = "email"
+	DuplicateReasonMobilePhone = "mobile_phone"
+	DuplicateReasonName        = "name"
+)
+
+// DuplicateCandidate customer which may be the same person as another customer,
+// with the reasons it was found for.
+type DuplicateCandidate struct {
+	Customer       Response `json:"customer"`
+	Reasons        []string `json:"reasons"`
+	NameSimilarity float64  `json:"nameSimilarity"`
 }
 
 type PaginationResponse struct {
 	Pagination utils.Pagination
 	Data       []Response
 }
+
+const (
+	ImportStatusPending = "pending"
+	ImportStatusRunning = "running"
+	ImportStatusDone    = "done"
+	ImportStatusFailed  = "failed"
+)
+
+// ImportColumns columns of an import file, the first row of the file names them.
+var ImportColumns = []string{"name", "email", "mobile_phone", "password"}
+
+// ImportRow customer read from a row of an import file.
+type ImportRow struct {
+	Row     int
+	Request StoreRequest
+}
+
+// ImportRowError error of a row of an import file: validator.ValidationErrors,
+// a duplicate error or the error of the database.
+type ImportRowError struct {
+	Row         int
+	Email       string
+	MobilePhone string
+	Err         error
+}
+
+// ImportJob progress and outcome of a customer import running in the background.
+// Imported counts the rows created, or the valid rows of a dry run. Error is the
+// error which stopped a failed import, the rows processed until then are kept.
+type ImportJob struct {
+	ID         string           `json:"id"`
+	Status     string           `json:"status"`
+	DryRun     bool             `json:"dryRun"`
+	Total      int              `json:"total"`
+	Processed  int              `json:"processed"`
+	Imported   int              `json:"imported"`
+	Failed     int              `json:"failed"`
+	Errors     []ImportRowError `json:"-"`
+	Error      string           `json:"error,omitempty"`
+	CreatedAt  time.Time        `json:"createdAt"`
+	FinishedAt *time.Time       `json:"finishedAt,omitempty"`
+}
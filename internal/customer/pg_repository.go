@@ -8,9 +8,15 @@ import (
 
 type PgRepository interface {
 	Create(ctx context.Context, entity *domain.Customer) error
-	Update(ctx context.Context, entity domain.Customer) error
-	FindOneCustomerByID(ctx context.Context, id int) (domain.Customer, error)
-	FindCustomers(ctx context.Context, page, size int, search, order string) (*database.Paginator, error)
+	Update(ctx context.Context, entity domain.Customer, columns ...string) error
+	FindOneCustomerByID(ctx context.Context, id int, preloads ...string) (domain.Customer, error)
+	FindDeletedCustomerByID(ctx context.Context, id int) (domain.Customer, error)
+	FindCustomers(ctx context.Context, query ListQuery) (*database.Paginator, error)
+	FindCustomersInBatches(ctx context.Context, query ListQuery, batchSize int, fn func([]domain.Customer) error) error
+	FindDuplicateCandidates(ctx context.Context, entity domain.Customer, limit int) ([]domain.Customer, error)
 	CheckDuplicate(ctx context.Context, args ...interface{}) (int64, error)
 	Delete(ctx context.Context, id int) error
+	Restore(ctx context.Context, id int) error
+	Purge(ctx context.Context, id int) error
+	Merge(ctx context.Context, survivor domain.Customer, customers []domain.Customer) error
 }
@@ -0,0 +1,27 @@
+package customer
+
+import (
+	"context"
+	"github.com/alpakih/point-of-sales/internal/domain"
+)
+
+// PersonalData everything stored about a customer, Customer is read with its
+// addresses and LoyaltyEntries with their lines and reversals.
+type PersonalData struct {
+	Customer       domain.Customer
+	LoyaltyEntries []domain.LoyaltyEntry
+	WalletEntries  []domain.WalletEntry
+	TierChanges    []domain.CustomerTierChange
+	Segments       []domain.CustomerSegment
+	Consents       []domain.CustomerConsent
+	Merges         []domain.CustomerMerge
+	Erasures       []domain.CustomerErasure
+}
+
+// PrivacyPgRepository reads and erases the personal data of the customers,
+// soft-deleted customers included.
+type PrivacyPgRepository interface {
+	LockCustomer(ctx context.Context, customerID int) error
+	FindPersonalData(ctx context.Context, customerID int) (PersonalData, error)
+	Erase(ctx context.Context, erasure *domain.CustomerErasure) error
+}
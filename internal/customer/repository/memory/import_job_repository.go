@@ -0,0 +1,55 @@
+package memory
+
+import (
+	"context"
+	"github.com/alpakih/point-of-sales/internal/constant"
+	"github.com/alpakih/point-of-sales/internal/customer"
+	"sync"
+	"time"
+)
+
+// DefaultImportJobTTL how long a finished import job and its report are kept.
+const DefaultImportJobTTL = 24 * time.Hour
+
+type importJobMemoryRepository struct {
+	mu   sync.RWMutex
+	ttl  time.Duration
+	jobs map[string]customer.ImportJob
+}
+
+// NewImportJobMemoryRepository keeps the import jobs in memory, finished jobs are
+// evicted ttl after they finished. Jobs are lost on restart and aren't shared
+// between instances.
+func NewImportJobMemoryRepository(ttl time.Duration) customer.ImportJobRepository {
+	return &importJobMemoryRepository{
+		ttl:  ttl,
+		jobs: make(map[string]customer.ImportJob),
+	}
+}
+
+func (r *importJobMemoryRepository) Save(ctx context.Context, job customer.ImportJob) error {
+	job.Errors = append([]customer.ImportRowError(nil), job.Errors...)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for id, stored := range r.jobs {
+		if stored.FinishedAt != nil && now.Sub(*stored.FinishedAt) > r.ttl {
+			delete(r.jobs, id)
+		}
+	}
+	r.jobs[job.ID] = job
+	return nil
+}
+
+func (r *importJobMemoryRepository) FindImportJobByID(ctx context.Context, id string) (customer.ImportJob, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	job, ok := r.jobs[id]
+	if !ok {
+		return customer.ImportJob{}, constant.ErrImportJobNotFound
+	}
+	return job, nil
+}
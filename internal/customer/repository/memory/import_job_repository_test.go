@@ -0,0 +1,43 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"github.com/alpakih/point-of-sales/internal/constant"
+	"github.com/alpakih/point-of-sales/internal/customer"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestImportJobMemoryRepository_Save(t *testing.T) {
+	ctx := context.TODO()
+	repository := NewImportJobMemoryRepository(time.Hour)
+
+	job := customer.ImportJob{ID: "job", Status: customer.ImportStatusRunning}
+	job.Errors = append(job.Errors, customer.ImportRowError{Row: 2, Err: errors.New("row")})
+	assert.NoError(t, repository.Save(ctx, job))
+
+	job.Errors[0].Row = 3
+	stored, err := repository.FindImportJobByID(ctx, "job")
+	assert.NoError(t, err)
+	assert.Equal(t, customer.ImportStatusRunning, stored.Status)
+	assert.Equal(t, 2, stored.Errors[0].Row)
+
+	_, err = repository.FindImportJobByID(ctx, "unknown")
+	assert.ErrorIs(t, err, constant.ErrImportJobNotFound)
+}
+
+func TestImportJobMemoryRepository_Evict(t *testing.T) {
+	ctx := context.TODO()
+	repository := NewImportJobMemoryRepository(time.Hour)
+
+	finishedAt := time.Now().Add(-2 * time.Hour)
+	assert.NoError(t, repository.Save(ctx, customer.ImportJob{ID: "finished", Status: customer.ImportStatusDone, FinishedAt: &finishedAt}))
+	assert.NoError(t, repository.Save(ctx, customer.ImportJob{ID: "running", Status: customer.ImportStatusRunning}))
+
+	_, err := repository.FindImportJobByID(ctx, "finished")
+	assert.ErrorIs(t, err, constant.ErrImportJobNotFound)
+	_, err = repository.FindImportJobByID(ctx, "running")
+	assert.NoError(t, err)
+}
@@ -0,0 +1,108 @@
+package pg
+
+import (
+	"context"
+	"github.com/alpakih/point-of-sales/internal/customer"
+	"github.com/alpakih/point-of-sales/internal/domain"
+	"github.com/alpakih/point-of-sales/pkg/database"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type customerAddressPgRepository struct {
+	db *gorm.DB
+}
+
+func NewCustomerAddressPgRepository(db *gorm.DB) customer.AddressPgRepository {
+	return &customerAddressPgRepository{db: db}
+}
+
+// LockCustomer locks the active customer until the end of the transaction, the
+// changes of its default address are serialized. gorm.ErrRecordNotFound is
+// returned when it doesn't exist.
+func (c customerAddressPgRepository) LockCustomer(ctx context.Context, customerID int) error {
+	return lockCustomer(database.FromContext(ctx, c.db), customerID)
+}
+
+func (c customerAddressPgRepository) Create(ctx context.Context, entity *domain.CustomerAddress) error {
+	return database.FromContext(ctx, c.db).Create(entity).Error
+}
+
+// Update writes every field of the address, gorm.ErrRecordNotFound is returned
+// when it isn't an address of the customer.
+func (c customerAddressPgRepository) Update(ctx context.Context, entity domain.CustomerAddress) error {
+	result := database.FromContext(ctx, c.db).
+		Select("*").Omit("id", "customer_id", "created_at").
+		Where("customer_id = ?", entity.CustomerID).
+		Updates(&entity)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (c customerAddressPgRepository) FindAddressByID(ctx context.Context, customerID, id int) (domain.CustomerAddress, error) {
+	var entity domain.CustomerAddress
+	err := database.FromContext(ctx, c.db).First(&entity, "id = ? AND customer_id = ?", id, customerID).Error
+	return entity, err
+}
+
+// FindAddresses returns the addresses of the customer, the default address first
+// and the others in the order they were added.
+func (c customerAddressPgRepository) FindAddresses(ctx context.Context, customerID int) ([]domain.CustomerAddress, error) {
+	var entities []domain.CustomerAddress
+	err := database.FromContext(ctx, c.db).
+		Where("customer_id = ?", customerID).
+		Order("is_default DESC").Order("id").
+		Find(&entities).Error
+	return entities, err
+}
+
+// ClearDefault unsets the default address of the customer.
+func (c customerAddressPgRepository) ClearDefault(ctx context.Context, customerID int) error {
+	return database.FromContext(ctx, c.db).Model(&domain.CustomerAddress{}).
+		Where("customer_id = ? AND is_default = ?", customerID, true).
+		Update("is_default", false).Error
+}
+
+func (c customerAddressPgRepository) Delete(ctx context.Context, customerID, id int) error {
+	result := database.FromContext(ctx, c.db).Delete(&domain.CustomerAddress{}, "id = ? AND customer_id = ?", id, customerID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// lockCustomer locks the active customer until the end of the transaction.
+func lockCustomer(db *gorm.DB, customerID int) error {
+	var entity domain.Customer
+	return db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id").
+		First(&entity, "id = ?", customerID).Error
+}
+
+// mergeAddresses moves the addresses of the merged customers to the survivor,
+// the survivor keeps its default address or gets the oldest one as default.
+func mergeAddresses(db *gorm.DB, survivorID int, ids []int) error {
+	if err := db.Model(&domain.CustomerAddress{}).Where("customer_id IN ? AND is_default = ?", ids, true).Update("is_default", false).Error; err != nil {
+		return err
+	}
+	if err := db.Model(&domain.CustomerAddress{}).Where("customer_id IN ?", ids).Update("customer_id", survivorID).Error; err != nil {
+		return err
+	}
+
+	var addresses []domain.CustomerAddress
+	if err := db.Where("customer_id = ?", survivorID).Order("is_default DESC").Order("id").Limit(1).Find(&addresses).Error; err != nil {
+		return err
+	}
+	if len(addresses) == 0 || addresses[0].IsDefault {
+		return nil
+	}
+	return db.Model(&addresses[0]).Update("is_default", true).Error
+}
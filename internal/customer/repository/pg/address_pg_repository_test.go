@@ -0,0 +1,59 @@
+package pg
+
+import (
+	"context"
+	"github.com/alpakih/point-of-sales/internal/domain"
+	"github.com/alpakih/point-of-sales/pkg/database"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"testing"
+)
+
+func TestCustomerAddressPgRepository_InMemory(t *testing.T) {
+	db, err := database.NewInMemory(&domain.Customer{}, &domain.CustomerAddress{})
+	assert.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.TODO()
+	pgRepository := NewCustomerPgRepository(db.Conn())
+	addressPgRepository := NewCustomerAddressPgRepository(db.Conn())
+
+	alice := domain.Customer{Name: "Alice", Email: "alice@test.com", MobilePhone: "+6287766777001", Password: "password"}
+	assert.NoError(t, pgRepository.Create(ctx, &alice))
+	assert.NoError(t, addressPgRepository.LockCustomer(ctx, alice.ID))
+	assert.ErrorIs(t, addressPgRepository.LockCustomer(ctx, alice.ID+1), gorm.ErrRecordNotFound)
+
+	// an included association without records is empty, not missing
+	data, err := pgRepository.FindOneCustomerByID(ctx, alice.ID, "Addresses")
+	assert.NoError(t, err)
+	assert.NotNil(t, data.Addresses)
+	assert.Empty(t, data.Addresses)
+
+	home := domain.CustomerAddress{CustomerID: alice.ID, Label: "Home", City: "Bandung", IsDefault: true}
+	office := domain.CustomerAddress{CustomerID: alice.ID, Label: "Office", City: "Jakarta"}
+	assert.NoError(t, addressPgRepository.Create(ctx, &home))
+	assert.NoError(t, addressPgRepository.Create(ctx, &office))
+
+	assert.NoError(t, addressPgRepository.ClearDefault(ctx, alice.ID))
+	office.IsDefault = true
+	office.City = "Depok"
+	assert.NoError(t, addressPgRepository.Update(ctx, office))
+
+	addresses, err := addressPgRepository.FindAddresses(ctx, alice.ID)
+	assert.NoError(t, err)
+	assert.Len(t, addresses, 2)
+	assert.Equal(t, office.ID, addresses[0].ID)
+	assert.Equal(t, "Depok", addresses[0].City)
+	assert.True(t, addresses[0].IsDefault)
+	assert.False(t, addresses[1].IsDefault)
+
+	_, err = addressPgRepository.FindAddressByID(ctx, alice.ID+1, home.ID)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	assert.ErrorIs(t, addressPgRepository.Update(ctx, domain.CustomerAddress{ID: home.ID, CustomerID: alice.ID + 1}), gorm.ErrRecordNotFound)
+	assert.ErrorIs(t, addressPgRepository.Delete(ctx, alice.ID+1, home.ID), gorm.ErrRecordNotFound)
+
+	assert.NoError(t, addressPgRepository.Delete(ctx, alice.ID, home.ID))
+	addresses, err = addressPgRepository.FindAddresses(ctx, alice.ID)
+	assert.NoError(t, err)
+	assert.Len(t, addresses, 1)
+}
@@ -0,0 +1,72 @@
+package pg
+
+import (
+	"context"
+	"github.com/alpakih/point-of-sales/internal/customer"
+	"github.com/alpakih/point-of-sales/internal/domain"
+	"github.com/alpakih/point-of-sales/pkg/database"
+	"gorm.io/gorm"
+)
+
+type customerConsentPgRepository struct {
+	db *gorm.DB
+}
+
+func NewCustomerConsentPgRepository(db *gorm.DB) customer.ConsentPgRepository {
+	return &customerConsentPgRepository{db: db}
+}
+
+// LockCustomer locks the active customer until the end of the transaction, its
+// consents are read and changed by one transaction at a time.
+// gorm.ErrRecordNotFound is returned when it doesn't exist.
+func (c customerConsentPgRepository) LockCustomer(ctx context.Context, customerID int) error {
+	return lockCustomer(database.FromContext(ctx, c.db), customerID)
+}
+
+// FindConsents returns the consent changes of the customer in the order they
+// were appended.
+func (c customerConsentPgRepository) FindConsents(ctx context.Context, customerID int) ([]domain.CustomerConsent, error) {
+	var consents []domain.CustomerConsent
+	err := database.FromContext(ctx, c.db).
+		Where("customer_id = ?", customerID).
+		Order("id").
+		Find(&consents).Error
+	return consents, err
+}
+
+// Append appends the consent changes and sets their ids.
+func (c customerConsentPgRepository) Append(ctx context.Context, consents []domain.CustomerConsent) error {
+	if len(consents) == 0 {
+		return nil
+	}
+	return database.FromContext(ctx, c.db).Create(&consents).Error
+}
+
+// FindConsentedCustomerIDs returns the ids of the active customers, among
+// customerIDs, whose latest change on the channel granted their consent, in
+// ascending order.
+func (c customerConsentPgRepository) FindConsentedCustomerIDs(ctx context.Context, channel string, customerIDs []int) ([]int, error) {
+	var ids []int
+	if len(customerIDs) == 0 {
+		return ids, nil
+	}
+	db := database.FromContext(ctx, c.db)
+	err := consentedCustomers(db, channel).
+		Where("customer_id IN ?", customerIDs).
+		Where("customer_id IN (?)", db.Session(&gorm.Session{NewDB: true}).Model(&domain.Customer{}).Select("id")).
+		Order("customer_id").
+		Pluck("customer_id", &ids).Error
+	return ids, err
+}
+
+// consentedCustomers selects the ids of the customers whose latest change on the
+// channel granted their consent, a later withdrawal overrides the grant.
+func consentedCustomers(db *gorm.DB, channel string) *gorm.DB {
+	db = db.Session(&gorm.Session{NewDB: true})
+	return db.Model(&domain.CustomerConsent{}).
+		Select("customer_id").
+		Where("channel = ? AND granted = ?", channel, true).
+		Where("NOT EXISTS (?)", db.Table("customer_consents AS later").
+			Select("1").
+			Where("later.customer_id = customer_consents.customer_id AND later.channel = customer_consents.channel AND later.id > customer_consents.id"))
+}
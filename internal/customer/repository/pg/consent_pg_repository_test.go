@@ -0,0 +1,63 @@
+package pg
+
+import (
+	"context"
+	"github.com/alpakih/point-of-sales/internal/constant"
+	"github.com/alpakih/point-of-sales/internal/customer"
+	"github.com/alpakih/point-of-sales/internal/domain"
+	"github.com/alpakih/point-of-sales/pkg/database"
+	"github.com/alpakih/point-of-sales/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestCustomerConsentPgRepository_InMemory(t *testing.T) {
+	db, err := database.NewInMemory(&domain.Customer{}, &domain.CustomerSegment{}, &domain.CustomerSegmentMember{}, &domain.CustomerConsent{})
+	assert.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.TODO()
+	pgRepository := NewCustomerPgRepository(db.Conn())
+	consentPgRepository := NewCustomerConsentPgRepository(db.Conn())
+
+	alice := domain.Customer{Name: "Alice", Email: "alice@test.com", MobilePhone: "+6287766777001", Password: "password"}
+	bob := domain.Customer{Name: "Bob", Email: "bob@test.com", MobilePhone: "+6287766777002", Password: "password"}
+	carol := domain.Customer{Name: "Carol", Email: "carol@test.com", MobilePhone: "+6287766777003", Password: "password"}
+	for _, entity := range []*domain.Customer{&alice, &bob, &carol} {
+		assert.NoError(t, pgRepository.Create(ctx, entity))
+	}
+
+	assert.NoError(t, consentPgRepository.Append(ctx, []domain.CustomerConsent{
+		{CustomerID: alice.ID, Channel: domain.ConsentChannelEmail, Granted: true, Source: domain.ConsentSourceWeb},
+		{CustomerID: alice.ID, Channel: domain.ConsentChannelSMS, Granted: true, Source: domain.ConsentSourceWeb},
+		{CustomerID: bob.ID, Channel: domain.ConsentChannelEmail, Granted: true, Source: domain.ConsentSourcePOS},
+		{CustomerID: carol.ID, Channel: domain.ConsentChannelEmail, Granted: true, Source: domain.ConsentSourceImport},
+	}))
+	// bob withdraws and carol is deleted, neither may be sent to
+	assert.NoError(t, consentPgRepository.Append(ctx, []domain.CustomerConsent{
+		{CustomerID: bob.ID, Channel: domain.ConsentChannelEmail, Granted: false, Source: domain.ConsentSourceWeb},
+	}))
+	assert.NoError(t, consentPgRepository.Append(ctx, nil))
+	assert.NoError(t, pgRepository.Delete(ctx, carol.ID))
+
+	consents, err := consentPgRepository.FindConsents(ctx, bob.ID)
+	assert.NoError(t, err)
+	assert.Len(t, consents, 2)
+	assert.False(t, consents[1].Granted)
+
+	ids, err := consentPgRepository.FindConsentedCustomerIDs(ctx, domain.ConsentChannelEmail, []int{alice.ID, bob.ID, carol.ID})
+	assert.NoError(t, err)
+	assert.Equal(t, []int{alice.ID}, ids)
+
+	ids, err = consentPgRepository.FindConsentedCustomerIDs(ctx, domain.ConsentChannelWhatsApp, []int{alice.ID, bob.ID, carol.ID})
+	assert.NoError(t, err)
+	assert.Empty(t, ids)
+
+	data, err := pgRepository.FindCustomers(ctx, customer.ListQuery{PaginationQuery: utils.PaginationQuery{Page: 1, Size: 10}, Consent: domain.ConsentChannelEmail})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), data.Total)
+	assert.Equal(t, "Alice", (*data.Records.(*[]domain.Customer))[0].Name)
+
+	_, err = pgRepository.FindCustomers(ctx, customer.ListQuery{PaginationQuery: utils.PaginationQuery{Page: 1, Size: 10}, Consent: "fax"})
+	assert.ErrorIs(t, err, constant.ErrInvalidConsentChannel)
+}
@@ -0,0 +1,122 @@
+package pg
+
+import (
+	"context"
+	"fmt"
+	"github.com/alpakih/point-of-sales/internal/constant"
+	"github.com/alpakih/point-of-sales/internal/customer"
+	"github.com/alpakih/point-of-sales/internal/domain"
+	"github.com/alpakih/point-of-sales/pkg/database"
+	"gorm.io/gorm"
+	"time"
+)
+
+// loyaltyEarnReferenceIndex unique index of the references of the earn entries,
+// points are earned once per sale.
+const loyaltyEarnReferenceIndex = "idx_loyalty_entries_earned_reference"
+
+type customerLoyaltyPgRepository struct {
+	db *gorm.DB
+}
+
+func NewCustomerLoyaltyPgRepository(db *gorm.DB) customer.LoyaltyPgRepository {
+	return &customerLoyaltyPgRepository{db: db}
+}
+
+// LockCustomer locks the active customer until the end of the transaction, the
+// entries of its ledger are appended one transaction at a time.
+// gorm.ErrRecordNotFound is returned when it doesn't exist.
+func (c customerLoyaltyPgRepository) LockCustomer(ctx context.Context, customerID int) error {
+	return lockCustomer(database.FromContext(ctx, c.db), customerID)
+}
+
+// Append appends the entries to the ledger and returns them with their ids,
+// constant.ErrLoyaltyReferenceExists is returned when points were already earned
+// by the reference of an earn entry.
+func (c customerLoyaltyPgRepository) Append(ctx context.Context, entries []domain.LoyaltyEntry) ([]domain.LoyaltyEntry, error) {
+	if len(entries) == 0 {
+		return entries, nil
+	}
+	err := database.FromContext(ctx, c.db).Create(&entries).Error
+	if _, ok := database.UniqueViolation(err); ok {
+		return nil, constant.ErrLoyaltyReferenceExists
+	}
+	return entries, err
+}
+
+// FindEntries returns the ledger of the customer in the order it was appended.
+func (c customerLoyaltyPgRepository) FindEntries(ctx context.Context, customerID int) ([]domain.LoyaltyEntry, error) {
+	var entries []domain.LoyaltyEntry
+	err := database.FromContext(ctx, c.db).
+		Where("customer_id = ?", customerID).
+		Order("id").
+		Find(&entries).Error
+	return entries, err
+}
+
+// FindCustomerIDsWithExpiringPoints returns the ids of the customers credited
+// points expiring after from, up to to.
+func (c customerLoyaltyPgRepository) FindCustomerIDsWithExpiringPoints(ctx context.Context, from, to time.Time) ([]int, error) {
+	var ids []int
+	err := database.FromContext(ctx, c.db).Model(&domain.LoyaltyEntry{}).
+		Distinct("customer_id").
+		Where("points > 0 AND expires_at > ? AND expires_at <= ?", from, to).
+		Order("customer_id").
+		Pluck("customer_id", &ids).Error
+	return ids, err
+}
+
+// mergeLoyalty transfers the loyalty points of the merged customers to the
+// survivor, the transferred points keep their expiry. The ledgers are appended
+// to, points already expired are expired on the ledger of the merged customer.
+func mergeLoyalty(db *gorm.DB, survivorID int, ids []int) error {
+	var now = time.Now()
+	var appended []domain.LoyaltyEntry
+
+	for _, id := range ids {
+		var entries []domain.LoyaltyEntry
+		if err := db.Where("customer_id = ?", id).Order("id").Find(&entries).Error; err != nil {
+			return err
+		}
+		ledger := customer.ReplayLoyaltyLedger(entries)
+		reference := fmt.Sprintf("customer:%d", id)
+
+		for _, lot := range ledger.Lots {
+			if lot.Remaining == 0 {
+				continue
+			}
+			entryID, expiresAt := lot.EntryID, lot.ExpiresAt
+			if !lot.ExpiresAt.After(now) {
+				appended = append(appended, domain.LoyaltyEntry{
+					CustomerID: id, Type: domain.LoyaltyEntryExpire, Points: -lot.Remaining, RelatedEntryID: &entryID,
+				})
+				continue
+			}
+			appended = append(appended,
+				domain.LoyaltyEntry{
+					CustomerID: id, Type: domain.LoyaltyEntryTransfer, Points: -lot.Remaining,
+					Reference: fmt.Sprintf("customer:%d", survivorID), RelatedEntryID: &entryID,
+				},
+				domain.LoyaltyEntry{
+					CustomerID: survivorID, Type: domain.LoyaltyEntryTransfer, Points: lot.Remaining,
+					Reference: reference, RelatedEntryID: &entryID, ExpiresAt: &expiresAt,
+				})
+		}
+		if ledger.Deficit > 0 {
+			appended = append(appended,
+				domain.LoyaltyEntry{
+					CustomerID: id, Type: domain.LoyaltyEntryTransfer, Points: ledger.Deficit,
+					Reference: fmt.Sprintf("customer:%d", survivorID),
+				},
+				domain.LoyaltyEntry{
+					CustomerID: survivorID, Type: domain.LoyaltyEntryTransfer, Points: -ledger.Deficit,
+					Reference: reference,
+				})
+		}
+	}
+
+	if len(appended) == 0 {
+		return nil
+	}
+	return db.Create(&appended).Error
+}
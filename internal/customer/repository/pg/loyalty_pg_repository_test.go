@@ -0,0 +1,108 @@
+package pg
+
+import (
+	"context"
+	"github.com/alpakih/point-of-sales/internal/constant"
+	"github.com/alpakih/point-of-sales/internal/customer"
+	"github.com/alpakih/point-of-sales/internal/domain"
+	"github.com/alpakih/point-of-sales/pkg/database"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"testing"
+	"time"
+)
+
+func TestCustomerLoyaltyPgRepository_InMemory(t *testing.T) {
+	db, err := database.NewInMemory(&domain.Customer{}, &domain.LoyaltyEntry{})
+	assert.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.TODO()
+	assert.NoError(t, MigrateIndexes(ctx, db.Conn()))
+	pgRepository := NewCustomerPgRepository(db.Conn())
+	loyaltyPgRepository := NewCustomerLoyaltyPgRepository(db.Conn())
+
+	alice := domain.Customer{Name: "Alice", Email: "alice@test.com", MobilePhone: "+6287766777001", Password: "password"}
+	assert.NoError(t, pgRepository.Create(ctx, &alice))
+	assert.NoError(t, loyaltyPgRepository.LockCustomer(ctx, alice.ID))
+	assert.ErrorIs(t, loyaltyPgRepository.LockCustomer(ctx, alice.ID+1), gorm.ErrRecordNotFound)
+
+	now := time.Now()
+	expiresAt := now.AddDate(0, 0, 1)
+	entries, err := loyaltyPgRepository.Append(ctx, []domain.LoyaltyEntry{
+		{CustomerID: alice.ID, Type: domain.LoyaltyEntryEarn, Points: 10, Reference: "S-1", ExpiresAt: &expiresAt},
+		{CustomerID: alice.ID, Type: domain.LoyaltyEntryRedeem, Points: -4, Reference: "S-2"},
+	})
+	assert.NoError(t, err)
+	assert.NotZero(t, entries[0].ID)
+	assert.Greater(t, entries[1].ID, entries[0].ID)
+
+	// a sale earns points once, redeeming points with its reference is allowed
+	_, err = loyaltyPgRepository.Append(ctx, []domain.LoyaltyEntry{
+		{CustomerID: alice.ID, Type: domain.LoyaltyEntryEarn, Points: 5, Reference: "S-1", ExpiresAt: &expiresAt},
+	})
+	assert.ErrorIs(t, err, constant.ErrLoyaltyReferenceExists)
+	_, err = loyaltyPgRepository.Append(ctx, []domain.LoyaltyEntry{
+		{CustomerID: alice.ID, Type: domain.LoyaltyEntryRedeem, Points: -1, Reference: "S-1"},
+	})
+	assert.NoError(t, err)
+
+	found, err := loyaltyPgRepository.FindEntries(ctx, alice.ID)
+	assert.NoError(t, err)
+	assert.Len(t, found, 3)
+	assert.Equal(t, entries[0].ID, found[0].ID)
+
+	ids, err := loyaltyPgRepository.FindCustomerIDsWithExpiringPoints(ctx, now, now.AddDate(0, 0, 2))
+	assert.NoError(t, err)
+	assert.Equal(t, []int{alice.ID}, ids)
+	ids, err = loyaltyPgRepository.FindCustomerIDsWithExpiringPoints(ctx, now.AddDate(0, 0, 2), now.AddDate(0, 0, 3))
+	assert.NoError(t, err)
+	assert.Empty(t, ids)
+}
+
+func TestCustomerPgRepository_MergeLoyaltyInMemory(t *testing.T) {
+	db, err := database.NewInMemory(&domain.Customer{}, &domain.CustomerAddress{}, &domain.CustomerMerge{}, &domain.LoyaltyEntry{},
+		&domain.CustomerSegment{}, &domain.CustomerSegmentMember{}, &domain.WalletEntry{})
+	assert.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.TODO()
+	pgRepository := NewCustomerPgRepository(db.Conn())
+	loyaltyPgRepository := NewCustomerLoyaltyPgRepository(db.Conn())
+
+	john := domain.Customer{Name: "John Smith", Email: "john@test.com", MobilePhone: "+6287766777001", Password: "password"}
+	jhon := domain.Customer{Name: "Jhon Smith", Email: "jhon@test.com", MobilePhone: "+6287766777002", Password: "password"}
+	for _, entity := range []*domain.Customer{&john, &jhon} {
+		assert.NoError(t, pgRepository.Create(ctx, entity))
+	}
+
+	now := time.Now()
+	expired, live := now.AddDate(0, 0, -1), now.AddDate(0, 6, 0)
+	_, err = loyaltyPgRepository.Append(ctx, []domain.LoyaltyEntry{
+		{CustomerID: john.ID, Type: domain.LoyaltyEntryEarn, Points: 3, Reference: "S-1", ExpiresAt: &live},
+		{CustomerID: jhon.ID, Type: domain.LoyaltyEntryEarn, Points: 7, Reference: "S-2", ExpiresAt: &expired},
+		{CustomerID: jhon.ID, Type: domain.LoyaltyEntryEarn, Points: 20, Reference: "S-3", ExpiresAt: &live},
+		{CustomerID: jhon.ID, Type: domain.LoyaltyEntryRedeem, Points: -5, Reference: "S-4", CreatedAt: expired.AddDate(0, 0, -1)},
+	})
+	assert.NoError(t, err)
+
+	assert.NoError(t, pgRepository.Merge(ctx, john, []domain.Customer{jhon}))
+
+	// the merged ledger is appended to, its live points move with their expiry
+	entries, err := loyaltyPgRepository.FindEntries(ctx, john.ID)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 2)
+	assert.Equal(t, domain.LoyaltyEntryTransfer, entries[1].Type)
+	assert.Equal(t, 20, entries[1].Points)
+	assert.WithinDuration(t, live, *entries[1].ExpiresAt, time.Second)
+	assert.Equal(t, 23, customer.ReplayLoyaltyLedger(entries).Balance(now))
+
+	entries, err = loyaltyPgRepository.FindEntries(ctx, jhon.ID)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 5)
+	assert.Equal(t, domain.LoyaltyEntryExpire, entries[3].Type)
+	assert.Equal(t, -2, entries[3].Points)
+	assert.Equal(t, domain.LoyaltyEntryTransfer, entries[4].Type)
+	assert.Equal(t, -20, entries[4].Points)
+	assert.Equal(t, 0, customer.ReplayLoyaltyLedger(entries).Balance(now))
+}
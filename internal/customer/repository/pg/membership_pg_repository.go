@@ -0,0 +1,118 @@
+package pg
+
+import (
+	"context"
+	"github.com/alpakih/point-of-sales/internal/customer"
+	"github.com/alpakih/point-of-sales/internal/domain"
+	"github.com/alpakih/point-of-sales/pkg/database"
+	"gorm.io/gorm"
+	"sort"
+	"time"
+)
+
+type customerMembershipPgRepository struct {
+	db *gorm.DB
+}
+
+func NewCustomerMembershipPgRepository(db *gorm.DB) customer.MembershipPgRepository {
+	return &customerMembershipPgRepository{db: db}
+}
+
+// FindActivities returns the spend and points of the sales of the customers
+// after from, up to to, net of their reversals. The sales of the customers
+// merged into a customer count as its own, the customers without sale are left
+// out.
+func (c customerMembershipPgRepository) FindActivities(ctx context.Context, from, to time.Time, customerIDs []int) ([]customer.MembershipActivity, error) {
+	var survivorIDs []int
+	survivors := database.FromContext(ctx, c.db).Model(&domain.CustomerMerge{}).Distinct("survivor_id").
+		Where("survivor_id IN (?)", database.FromContext(ctx, c.db).Model(&domain.Customer{}).Select("id"))
+	if customerIDs != nil {
+		survivors = survivors.Where("survivor_id IN ?", customerIDs)
+	}
+	if err := survivors.Pluck("survivor_id", &survivorIDs).Error; err != nil {
+		return nil, err
+	}
+	owners, err := findMergeOwners(database.FromContext(ctx, c.db), survivorIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	customers := database.FromContext(ctx, c.db).Where("customer_id IN (?)", database.FromContext(ctx, c.db).Model(&domain.Customer{}).Select("id"))
+	if customerIDs != nil {
+		customers = customers.Where("customer_id IN ?", customerIDs)
+	}
+	if merged := owners.merged(); len(merged) > 0 {
+		customers = customers.Or("customer_id IN ?", merged)
+	}
+	var rows []customer.MembershipActivity
+	err = database.FromContext(ctx, c.db).Model(&domain.LoyaltyEntry{}).
+		Select("customer_id, SUM(amount) AS spend, SUM(points) AS points").
+		Where("type IN ? AND amount <> 0", []string{domain.LoyaltyEntryEarn, domain.LoyaltyEntryReversal}).
+		Where("created_at > ? AND created_at <= ?", from, to).
+		Where(customers).
+		Group("customer_id").
+		Order("customer_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var activities []customer.MembershipActivity
+	var index = make(map[int]int, len(rows))
+	for _, row := range rows {
+		row.CustomerID = owners.owner(row.CustomerID)
+		if i, ok := index[row.CustomerID]; ok {
+			activities[i].Spend += row.Spend
+			activities[i].Points += row.Points
+			continue
+		}
+		index[row.CustomerID] = len(activities)
+		activities = append(activities, row)
+	}
+	sort.Slice(activities, func(i, j int) bool {
+		return activities[i].CustomerID < activities[j].CustomerID
+	})
+	return activities, nil
+}
+
+// FindCurrentTiers returns the latest tier change of the customers, the
+// customers whose tier never changed are left out.
+func (c customerMembershipPgRepository) FindCurrentTiers(ctx context.Context, customerIDs []int) ([]domain.CustomerTierChange, error) {
+	var changes []domain.CustomerTierChange
+	latest := database.FromContext(ctx, c.db).Model(&domain.CustomerTierChange{}).Select("MAX(id)").Group("customer_id")
+	err := database.FromContext(ctx, c.db).
+		Where("id IN (?)", latest).
+		Scopes(activeCustomers(customerIDs)).
+		Order("customer_id").
+		Find(&changes).Error
+	return changes, err
+}
+
+// FindTierChanges returns the tier changes of the customer, the latest first.
+func (c customerMembershipPgRepository) FindTierChanges(ctx context.Context, customerID int) ([]domain.CustomerTierChange, error) {
+	var changes []domain.CustomerTierChange
+	err := database.FromContext(ctx, c.db).
+		Where("customer_id = ?", customerID).
+		Order("id DESC").
+		Find(&changes).Error
+	return changes, err
+}
+
+func (c customerMembershipPgRepository) AppendTierChanges(ctx context.Context, changes []domain.CustomerTierChange) error {
+	if len(changes) == 0 {
+		return nil
+	}
+	return database.FromContext(ctx, c.db).Create(&changes).Error
+}
+
+// activeCustomers restricts the rows to those of the active customers, and of
+// the customerIDs when not nil.
+func activeCustomers(customerIDs []int) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		db = db.Where("customer_id IN (?)", db.Session(&gorm.Session{NewDB: true}).Model(&domain.Customer{}).Select("id"))
+		if customerIDs != nil {
+			db = db.Where("customer_id IN ?", customerIDs)
+		}
+		return db
+	}
+}
@@ -0,0 +1,94 @@
+package pg
+
+import (
+	"context"
+	"github.com/alpakih/point-of-sales/internal/customer"
+	"github.com/alpakih/point-of-sales/internal/domain"
+	"github.com/alpakih/point-of-sales/pkg/database"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestCustomerMembershipPgRepository_InMemory(t *testing.T) {
+	db, err := database.NewInMemory(&domain.Customer{}, &domain.LoyaltyEntry{}, &domain.CustomerTierChange{}, &domain.CustomerMerge{},
+		&domain.CustomerAddress{}, &domain.CustomerSegment{}, &domain.CustomerSegmentMember{}, &domain.WalletEntry{})
+	assert.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.TODO()
+	pgRepository := NewCustomerPgRepository(db.Conn())
+	membershipPgRepository := NewCustomerMembershipPgRepository(db.Conn())
+
+	alice := domain.Customer{Name: "Alice", Email: "alice@test.com", MobilePhone: "+6287766777001", Password: "password"}
+	bob := domain.Customer{Name: "Bob", Email: "bob@test.com", MobilePhone: "+6287766777002", Password: "password"}
+	carol := domain.Customer{Name: "Carol", Email: "carol@test.com", MobilePhone: "+6287766777003", Password: "password"}
+	for _, entity := range []*domain.Customer{&alice, &bob, &carol} {
+		assert.NoError(t, pgRepository.Create(ctx, entity))
+	}
+
+	now := time.Now()
+	earnID := 0
+	entries := []domain.LoyaltyEntry{
+		{CustomerID: alice.ID, Type: domain.LoyaltyEntryEarn, Points: 10, Amount: 100000, Reference: "S-1", CreatedAt: now.AddDate(0, -1, 0)},
+		{CustomerID: alice.ID, Type: domain.LoyaltyEntryEarn, Points: 4, Amount: 40000, Reference: "S-2", CreatedAt: now.AddDate(0, -2, 0)},
+		{CustomerID: alice.ID, Type: domain.LoyaltyEntryRedeem, Points: -5, Reference: "S-3", CreatedAt: now.AddDate(0, -1, 0)},
+		{CustomerID: alice.ID, Type: domain.LoyaltyEntryEarn, Points: 7, Amount: 70000, Reference: "S-4", CreatedAt: now.AddDate(-2, 0, 0)},
+		{CustomerID: bob.ID, Type: domain.LoyaltyEntryEarn, Points: 3, Amount: 30000, Reference: "S-5", CreatedAt: now.AddDate(0, -1, 0)},
+		{CustomerID: carol.ID, Type: domain.LoyaltyEntryEarn, Points: 9, Amount: 90000, Reference: "S-6", CreatedAt: now.AddDate(0, -1, 0)},
+	}
+	assert.NoError(t, db.Conn().Create(&entries).Error)
+	earnID = entries[1].ID
+	// the refund of S-2 and of the redemption of S-3 don't count
+	assert.NoError(t, db.Conn().Create(&[]domain.LoyaltyEntry{
+		{CustomerID: alice.ID, Type: domain.LoyaltyEntryReversal, Points: -4, Amount: -40000, Reference: "S-2", RelatedEntryID: &earnID, CreatedAt: now.AddDate(0, 0, -1)},
+		{CustomerID: alice.ID, Type: domain.LoyaltyEntryReversal, Points: 5, Reference: "S-3", CreatedAt: now.AddDate(0, 0, -1)},
+	}).Error)
+	assert.NoError(t, pgRepository.Delete(ctx, carol.ID))
+
+	activities, err := membershipPgRepository.FindActivities(ctx, now.AddDate(-1, 0, 0), now, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []customer.MembershipActivity{
+		{CustomerID: alice.ID, Spend: 100000, Points: 10},
+		{CustomerID: bob.ID, Spend: 30000, Points: 3},
+	}, activities)
+
+	activities, err = membershipPgRepository.FindActivities(ctx, now.AddDate(-1, 0, 0), now, []int{bob.ID})
+	assert.NoError(t, err)
+	assert.Len(t, activities, 1)
+	assert.Equal(t, bob.ID, activities[0].CustomerID)
+
+	assert.NoError(t, membershipPgRepository.AppendTierChanges(ctx, []domain.CustomerTierChange{
+		{CustomerID: alice.ID, Tier: "gold", PreviousTier: "silver"},
+		{CustomerID: carol.ID, Tier: "gold", PreviousTier: "silver"},
+	}))
+	assert.NoError(t, membershipPgRepository.AppendTierChanges(ctx, []domain.CustomerTierChange{
+		{CustomerID: alice.ID, Tier: "platinum", PreviousTier: "gold"},
+	}))
+
+	current, err := membershipPgRepository.FindCurrentTiers(ctx, nil)
+	assert.NoError(t, err)
+	assert.Len(t, current, 1)
+	assert.Equal(t, "platinum", current[0].Tier)
+
+	current, err = membershipPgRepository.FindCurrentTiers(ctx, []int{bob.ID})
+	assert.NoError(t, err)
+	assert.Empty(t, current)
+
+	changes, err := membershipPgRepository.FindTierChanges(ctx, alice.ID)
+	assert.NoError(t, err)
+	assert.Len(t, changes, 2)
+	assert.Equal(t, "platinum", changes[0].Tier)
+
+	// the sales of bob count as sales of alice once bob is merged into alice
+	assert.NoError(t, db.Conn().First(&alice, alice.ID).Error)
+	assert.NoError(t, pgRepository.Merge(ctx, alice, []domain.Customer{bob}))
+
+	activities, err = membershipPgRepository.FindActivities(ctx, now.AddDate(-1, 0, 0), now, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []customer.MembershipActivity{{CustomerID: alice.ID, Spend: 130000, Points: 13}}, activities)
+
+	activities, err = membershipPgRepository.FindActivities(ctx, now.AddDate(-1, 0, 0), now, []int{alice.ID})
+	assert.NoError(t, err)
+	assert.Equal(t, []customer.MembershipActivity{{CustomerID: alice.ID, Spend: 130000, Points: 13}}, activities)
+}
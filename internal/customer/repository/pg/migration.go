@@ -0,0 +1,116 @@
+package pg
+
+import (
+	"context"
+	"github.com/alpakih/point-of-sales/internal/domain"
+	"github.com/alpakih/point-of-sales/pkg/database"
+	"github.com/alpakih/point-of-sales/pkg/phone"
+	"gorm.io/gorm"
+)
+
+// migrationBatchSize number of customers read at once by the migrations.
+const migrationBatchSize = 500
+
+// legacyIndex index dropped by MigrateIndexes.
+type legacyIndex struct {
+	Table string
+	Name  string
+}
+
+// legacyIndexes unique indexes of the customers counting the soft-deleted
+// customers, and unique earn and wallet reference indexes, built over every
+// entry by the databases without partial index, replaced by partialUniqueIndexes, and the
+// unique default address index, built over every address of a customer as well.
+// A single default address is kept by the transactions locking the customer.
+var legacyIndexes = []legacyIndex{
+	{Table: "customers", Name: "idx_customers_email"},
+	{Table: "customers", Name: "idx_customers_mobile_phone"},
+	{Table: "customer_addresses", Name: "idx_customer_addresses_default"},
+	{Table: "loyalty_entries", Name: "idx_loyalty_entries_earn_reference"},
+	{Table: "wallet_entries", Name: "idx_wallet_entries_reference"},
+}
+
+// partialUniqueIndexes unique indexes over part of the rows, which AutoMigrate
+// can't build on every database.
+var partialUniqueIndexes = []database.PartialUniqueIndex{
+	{
+		Table: "customers", Name: emailUniqueIndex, Columns: []string{"email"}, Where: "deleted_at IS NULL",
+		Generated: "active_email", GeneratedType: "varchar(100)",
+	},
+	{
+		Table: "customers", Name: mobilePhoneUniqueIndex, Columns: []string{"mobile_phone"}, Where: "deleted_at IS NULL",
+		Generated: "active_mobile_phone", GeneratedType: "varchar(16)",
+	},
+	{
+		Table: "loyalty_entries", Name: loyaltyEarnReferenceIndex, Columns: []string{"reference"}, Where: "type = 'earn'",
+		Generated: "earn_reference", GeneratedType: "varchar(100)",
+	},
+	{
+		Table: "wallet_entries", Name: walletReferenceIndex, Columns: []string{"type", "reference"},
+		Where: "type IN ('topup', 'spend', 'refund')", Generated: "recorded_reference", GeneratedType: "varchar(100)",
+	},
+}
+
+// MigrateIndexes drops the legacy indexes and creates the partial unique indexes
+// missing, once the tables are migrated. The indexes of the tables not migrated
+// are skipped.
+func MigrateIndexes(ctx context.Context, db *gorm.DB) error {
+	db = db.WithContext(ctx)
+	for _, index := range legacyIndexes {
+		if !db.Migrator().HasTable(index.Table) {
+			continue
+		}
+		if err := database.DropIndexes(db, index.Table, index.Name); err != nil {
+			return err
+		}
+	}
+	for _, index := range partialUniqueIndexes {
+		if !db.Migrator().HasTable(index.Table) {
+			continue
+		}
+		if err := index.Create(db); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MigrateMobilePhones converts the mobile phones of the customers, soft-deleted
+// or not, which differ from their E.164 form, and increments their version. The
+// erased customers, without mobile phone, are left out. It returns the number of
+// converted customers and the ids of the customers left as is, whose mobile
+// phone is invalid or is the mobile phone of another customer once converted.
+// Those are to be fixed or merged before running it again.
+func MigrateMobilePhones(ctx context.Context, db *gorm.DB) (int, []int, error) {
+	var converted int
+	var skipped []int
+	var entities []domain.Customer
+
+	err := db.WithContext(ctx).Unscoped().
+		Where("mobile_phone IS NOT NULL AND mobile_phone <> ''").
+		FindInBatches(&entities, migrationBatchSize, func(tx *gorm.DB, batch int) error {
+			for _, entity := range entities {
+				mobilePhone, err := phone.Normalize(entity.MobilePhone)
+				if err != nil {
+					skipped = append(skipped, entity.ID)
+					continue
+				}
+				if mobilePhone == entity.MobilePhone {
+					continue
+				}
+				err = db.WithContext(ctx).Unscoped().Model(&domain.Customer{}).
+					Where("id = ?", entity.ID).
+					UpdateColumns(map[string]interface{}{"mobile_phone": mobilePhone, "version": gorm.Expr("version + 1")}).Error
+				if _, ok := database.UniqueViolation(err); ok {
+					skipped = append(skipped, entity.ID)
+					continue
+				}
+				if err != nil {
+					return err
+				}
+				converted++
+			}
+			return nil
+		}).Error
+	return converted, skipped, err
+}
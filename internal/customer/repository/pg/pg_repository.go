@@ -2,56 +2,184 @@ package pg
 
 import (
 	"context"
-	"fmt"
+	"errors"
+	"github.com/alpakih/point-of-sales/internal/constant"
 	"github.com/alpakih/point-of-sales/internal/customer"
 	"github.com/alpakih/point-of-sales/internal/domain"
 	"github.com/alpakih/point-of-sales/pkg/database"
 	"github.com/alpakih/point-of-sales/pkg/utils"
 	"gorm.io/gorm"
-	"net/http"
+	"sort"
+	"strings"
 )
 
+const (
+	emailUniqueIndex       = "idx_customers_active_email"
+	mobilePhoneUniqueIndex = "idx_customers_active_mobile_phone"
+)
+
+const (
+	// DuplicateScopeActive soft-deleted customers release their email and mobile
+	// phone, which can be registered again.
+	DuplicateScopeActive = "active"
+	// DuplicateScopeAll soft-deleted customers keep their email and mobile phone
+	// reserved until they are purged.
+	DuplicateScopeAll = "all"
+)
+
+// duplicatePhoneSuffix number of trailing digits of the mobile phones compared by
+// FindDuplicateCandidates, leaving out the country or trunk prefix.
+const duplicatePhoneSuffix = 8
+
+// mergeReferences move the records referencing the merged customers to the
+// survivor customer, once Merge locked them.
+var mergeReferences = []func(db *gorm.DB, survivorID int, ids []int) error{
+	mergeAddresses,
+	mergeLoyalty,
+	mergeSegments,
+	mergeWallet,
+}
+
+type RepositoryOption func(*customerPgRepository)
+
+// RepositoryDuplicateScope sets the customers CheckDuplicate counts, one of
+// DuplicateScopeActive (default) or DuplicateScopeAll.
+func RepositoryDuplicateScope(scope string) RepositoryOption {
+	return func(c *customerPgRepository) {
+		c.duplicateScope = scope
+	}
+}
+
 type customerPgRepository struct {
-	db *gorm.DB
+	db             *gorm.DB
+	duplicateScope string
 }
 
-func NewCustomerPgRepository(db *gorm.DB) customer.PgRepository {
-	return &customerPgRepository{
-		db: db,
+func NewCustomerPgRepository(db *gorm.DB, opts ...RepositoryOption) customer.PgRepository {
+	repository := &customerPgRepository{
+		db:             db,
+		duplicateScope: DuplicateScopeActive,
+	}
+	for _, opt := range opts {
+		opt(repository)
 	}
+	return repository
 }
 
 func (c customerPgRepository) Create(ctx context.Context, entity *domain.Customer) error {
-	return c.db.WithContext(ctx).Create(entity).Error
+	return translateError(database.FromContext(ctx, c.db).Create(entity).Error)
 }
 
-func (c customerPgRepository) Update(ctx context.Context, entity domain.Customer) error {
-	return c.db.WithContext(ctx).Updates(&entity).Error
+// Update writes the non-zero fields of entity, or only the given columns, which
+// may be cleared, when columns are passed.
+func (c customerPgRepository) Update(ctx context.Context, entity domain.Customer, columns ...string) error {
+	version := entity.Version
+	entity.Version = version + 1
+
+	db := database.FromContext(ctx, c.db)
+	if len(columns) > 0 {
+		db = db.Select(append(columns, "version", "updated_at"))
+	}
+	result := db.Where("version = ?", version).Updates(&entity)
+	if result.Error != nil {
+		return translateError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return constant.ErrVersionMismatch
+	}
+	return nil
 }
 
-func (c customerPgRepository) FindCustomers(ctx context.Context, page, size int, search, order string) (*database.Paginator, error) {
+func (c customerPgRepository) FindCustomers(ctx context.Context, query customer.ListQuery) (*database.Paginator, error) {
 	var entities []domain.Customer
-	db := c.db
+	db, sortKeys, err := c.listQuery(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	for _, preload := range customer.ResponseFieldset.Preloads(query.FieldsetQuery) {
+		db = db.Preload(preload)
+	}
+
+	paginator := database.NewPaginator(db, query.GetLinkBuilder(), query.GetPage(), query.GetSize(), &entities).
+		OrderBy(sortKeys...)
+	if query.IsCursorMode() {
+		paginator.Cursor(query.GetCursor()).CountTotal(query.GetCount())
+	}
+
+	return paginator, paginator.Find(ctx)
+}
+
+// FindCustomersInBatches reads the customers of the listing query, ignoring its
+// page, by batches of batchSize customers passed to fn.
+func (c customerPgRepository) FindCustomersInBatches(ctx context.Context, query customer.ListQuery, batchSize int, fn func([]domain.Customer) error) error {
+	var entities []domain.Customer
+	db, sortKeys, err := c.listQuery(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	paginator := database.NewPaginator(db, nil, 1, batchSize, &entities).
+		MaxPageSize(batchSize).
+		OrderBy(sortKeys...)
+	return paginator.FindInBatches(ctx, func() error {
+		return fn(entities)
+	})
+}
+
+// listQuery applies the search, filters, segment, consent and with_deleted of
+// the listing query and parses its sort keys. constant.ErrSegmentNotFound is
+// returned when the segment doesn't exist and constant.ErrInvalidConsentChannel
+// when the consent isn't a channel.
+func (c customerPgRepository) listQuery(ctx context.Context, query customer.ListQuery) (*gorm.DB, []database.SortKey, error) {
+	db := database.FromContext(ctx, c.db)
+	if query.GetWithDeleted() {
+		db = db.Unscoped()
+	}
 	fields := utils.GetListValueFromTagStruct(domain.Customer{}, "qsearch")
-	if search != "" {
-		for i := range fields {
-			db = db.Or(fmt.Sprintf("%s ILIKE ?", fields[i]), "%"+search+"%")
+	if search := query.GetSearch(); search != "" {
+		db = db.Scopes(database.Search(search, fields...))
+	}
+	db, err := database.ApplyFilters(db, &domain.Customer{}, query.GetFilters())
+	if err != nil {
+		return nil, nil, err
+	}
+	if name := query.GetSegment(); name != "" {
+		var segment domain.CustomerSegment
+		if err := database.FromContext(ctx, c.db).Select("id").Where("name = ?", name).Take(&segment).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, nil, constant.ErrSegmentNotFound
+			}
+			return nil, nil, err
 		}
+		members := database.FromContext(ctx, c.db).Model(&domain.CustomerSegmentMember{}).Select("customer_id").Where("segment_id = ?", segment.ID)
+		db = db.Where("id IN (?)", members)
 	}
-	if order != "" {
-		if utils.ItemExists(fields, order) {
-			db = db.Order(order)
+	if channel := query.GetConsent(); channel != "" {
+		if !domain.IsConsentChannel(channel) {
+			return nil, nil, constant.ErrInvalidConsentChannel
 		}
+		db = db.Where("id IN (?)", consentedCustomers(database.FromContext(ctx, c.db), channel))
 	}
+	sortKeys, err := database.ParseSort(query.GetOrderBy(), utils.GetListValueFromTagStruct(domain.Customer{}, "qsort"))
+	if err != nil {
+		return nil, nil, err
+	}
+	return db, sortKeys, nil
+}
 
-	paginator := database.NewPaginator(db, ctx.Value("requestCtx").(*http.Request), page, size, &entities)
-
-	return paginator, paginator.Find(ctx).Error
+func (c customerPgRepository) FindOneCustomerByID(ctx context.Context, id int, preloads ...string) (domain.Customer, error) {
+	var entity domain.Customer
+	db := database.FromContext(ctx, c.db)
+	for _, preload := range preloads {
+		db = db.Preload(preload)
+	}
+	err := db.First(&entity, "id =?", id).Error
+	return entity, err
 }
 
-func (c customerPgRepository) FindOneCustomerByID(ctx context.Context, id int) (domain.Customer, error) {
+func (c customerPgRepository) FindDeletedCustomerByID(ctx context.Context, id int) (domain.Customer, error) {
 	var entity domain.Customer
-	err := c.db.WithContext(ctx).First(&entity, "id =?", id).Error
+	err := database.FromContext(ctx, c.db).Unscoped().Where("deleted_at IS NOT NULL").First(&entity, "id =?", id).Error
 	return entity, err
 }
 
@@ -59,7 +187,10 @@ func (c customerPgRepository) CheckDuplicate(ctx context.Context, args ...interf
 
 	var count int64
 
-	db := c.db.WithContext(ctx).Model(&domain.Customer{})
+	db := database.FromContext(ctx, c.db).Model(&domain.Customer{})
+	if c.duplicateScope == DuplicateScopeAll {
+		db = db.Unscoped()
+	}
 
 	if args != nil {
 		db.Where(args[0], args[1:]...)
@@ -68,6 +199,178 @@ func (c customerPgRepository) CheckDuplicate(ctx context.Context, args ...interf
 	return count, db.Count(&count).Error
 }
 
+// Delete soft-deletes the customer, it stays referenced by its sales and can be
+// restored.
 func (c customerPgRepository) Delete(ctx context.Context, id int) error {
-	return c.db.WithContext(ctx).Delete(&domain.Customer{}, id).Error
+	return database.FromContext(ctx, c.db).Delete(&domain.Customer{}, id).Error
+}
+
+// Restore clears the deletion of a soft-deleted customer and increments its
+// version, gorm.ErrRecordNotFound is returned when it isn't deleted.
+func (c customerPgRepository) Restore(ctx context.Context, id int) error {
+	result := database.FromContext(ctx, c.db).Unscoped().Model(&domain.Customer{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Updates(map[string]interface{}{"deleted_at": nil, "version": gorm.Expr("version + 1")})
+	if result.Error != nil {
+		return translateError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// Purge permanently deletes the customer, soft-deleted or not.
+func (c customerPgRepository) Purge(ctx context.Context, id int) error {
+	result := database.FromContext(ctx, c.db).Unscoped().Delete(&domain.Customer{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// FindDuplicateCandidates returns up to limit other active customers sharing the
+// email, the trailing digits of the mobile phone or a word of the name of entity.
+// It is a coarse selection, the candidates are to be compared by the caller.
+func (c customerPgRepository) FindDuplicateCandidates(ctx context.Context, entity domain.Customer, limit int) ([]domain.Customer, error) {
+	var entities []domain.Customer
+
+	conditions := c.db.Where("lower(email) = ?", strings.ToLower(strings.TrimSpace(entity.Email)))
+	if phone := utils.Digits(entity.MobilePhone); len(phone) >= duplicatePhoneSuffix {
+		conditions = conditions.Or("mobile_phone LIKE ?", "%"+phone[len(phone)-duplicatePhoneSuffix:])
+	}
+	for _, word := range strings.Fields(utils.NormalizeName(entity.Name)) {
+		if len([]rune(word)) >= 3 {
+			conditions = conditions.Or("lower(name) LIKE ?", "%"+word+"%")
+		}
+	}
+
+	err := database.FromContext(ctx, c.db).
+		Where("id <> ?", entity.ID).
+		Where(conditions).
+		Order("id").
+		Limit(limit).
+		Find(&entities).Error
+	return entities, err
+}
+
+// Merge merges customers into survivor: survivor and customers are locked in
+// the order of their ids, the records referencing them are moved to survivor,
+// they are soft-deleted and the merge is recorded in the customer_merges audit
+// trail. The version of survivor is incremented,
+// constant.ErrVersionMismatch is returned when it was updated meanwhile.
+func (c customerPgRepository) Merge(ctx context.Context, survivor domain.Customer, customers []domain.Customer) error {
+	db := database.FromContext(ctx, c.db)
+
+	var ids = make([]int, len(customers))
+	var merges = make([]domain.CustomerMerge, len(customers))
+	for k, v := range customers {
+		ids[k] = v.ID
+		merges[k] = domain.CustomerMerge{
+			SurvivorID:  survivor.ID,
+			MergedID:    v.ID,
+			Name:        v.Name,
+			Email:       v.Email,
+			MobilePhone: v.MobilePhone,
+		}
+	}
+
+	var locked = append([]int{survivor.ID}, ids...)
+	sort.Ints(locked)
+	for _, id := range locked {
+		if err := lockCustomer(db, id); err != nil {
+			return err
+		}
+	}
+
+	for _, mergeReference := range mergeReferences {
+		if err := mergeReference(db, survivor.ID, ids); err != nil {
+			return err
+		}
+	}
+
+	result := db.Delete(&domain.Customer{}, ids)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected != int64(len(ids)) {
+		return gorm.ErrRecordNotFound
+	}
+
+	if err := db.Create(&merges).Error; err != nil {
+		return err
+	}
+
+	result = db.Model(&domain.Customer{}).
+		Where("id = ? AND version = ?", survivor.ID, survivor.Version).
+		Updates(map[string]interface{}{"version": gorm.Expr("version + 1")})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return constant.ErrVersionMismatch
+	}
+	return nil
+}
+
+// mergeOwners maps the customers merged into other customers, directly or into
+// a customer merged in turn, to the customer they were merged into among the
+// customers resolved by findMergeOwners. The sales of a merged customer count
+// as sales of its owner.
+type mergeOwners map[int]int
+
+// owner returns the customer the customer was merged into, the customer itself
+// when it wasn't merged.
+func (o mergeOwners) owner(id int) int {
+	if owner, ok := o[id]; ok {
+		return owner
+	}
+	return id
+}
+
+// merged returns the ids of the merged customers in ascending order.
+func (o mergeOwners) merged() []int {
+	var ids = make([]int, 0, len(o))
+	for id := range o {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// findMergeOwners resolves the customers merged into the given customers.
+func findMergeOwners(db *gorm.DB, customerIDs []int) (mergeOwners, error) {
+	var owners = make(mergeOwners)
+	for survivorIDs := customerIDs; len(survivorIDs) > 0; {
+		var merges []domain.CustomerMerge
+		if err := db.Select("survivor_id, merged_id").Where("survivor_id IN ?", survivorIDs).Order("id").Find(&merges).Error; err != nil {
+			return nil, err
+		}
+		survivorIDs = nil
+		for _, merge := range merges {
+			if _, ok := owners[merge.MergedID]; ok {
+				continue
+			}
+			owners[merge.MergedID] = owners.owner(merge.SurvivorID)
+			survivorIDs = append(survivorIDs, merge.MergedID)
+		}
+	}
+	return owners, nil
+}
+
+// translateError maps unique index violations raised by the driver to the
+// duplicate errors returned by the duplicate checks.
+func translateError(err error) error {
+	if name, ok := database.UniqueViolation(err); ok {
+		switch name {
+		case emailUniqueIndex, "customers.email":
+			return constant.ErrEmailAlreadyExist
+		case mobilePhoneUniqueIndex, "customers.mobile_phone":
+			return constant.ErrMobilePhoneAlreadyExist
+		}
+	}
+	return err
 }
@@ -2,11 +2,17 @@ package pg
 
 import (
 	"context"
+	"fmt"
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/alpakih/point-of-sales/internal/constant"
+	"github.com/alpakih/point-of-sales/internal/customer"
 	"github.com/alpakih/point-of-sales/internal/domain"
+	"github.com/alpakih/point-of-sales/pkg/database"
 	"github.com/alpakih/point-of-sales/pkg/utils"
+	"github.com/jackc/pgconn"
 	"github.com/stretchr/testify/assert"
-	"net/http"
+	"gorm.io/gorm"
+	"math"
 	"net/url"
 	"regexp"
 	"testing"
@@ -22,15 +28,16 @@ func TestCustomerPgRepository_Create(t *testing.T) {
 		Email:       "email",
 		MobilePhone: "mobile phone",
 		Password:    "password",
+		Version:     1,
 		CreatedAt:   time.Now(),
 		UpdatedAt:   time.Now(),
 	}
 
-	query := `INSERT INTO "customers" ("name","email","mobile_phone","password","created_at","updated_at") VALUES ($1,$2,$3,$4,$5,$6) RETURNING "id"`
+	query := `INSERT INTO "customers" ("name","email","mobile_phone","password","version","created_at","updated_at","deleted_at") VALUES ($1,$2,$3,$4,$5,$6,$7,$8) RETURNING "id"`
 	queryRegex := regexp.QuoteMeta(query)
 
 	dbMock.ExpectBegin()
-	dbMock.ExpectQuery(queryRegex).WithArgs(data.Name, data.Email, data.MobilePhone, data.Password, data.CreatedAt, data.UpdatedAt).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	dbMock.ExpectQuery(queryRegex).WithArgs(data.Name, data.Email, data.MobilePhone, data.Password, data.Version, data.CreatedAt, data.UpdatedAt, nil).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
 	dbMock.ExpectCommit()
 
 	pgRepository := NewCustomerPgRepository(gormDb)
@@ -39,41 +46,98 @@ func TestCustomerPgRepository_Create(t *testing.T) {
 	assert.NoError(t, err)
 }
 
-func TestCustomerPgRepository_Update(t *testing.T) {
+func TestCustomerPgRepository_CreateDuplicateEmail(t *testing.T) {
+
 	gormDb, dbMock := utils.GetDatabaseMock("postgres")
 
 	data := domain.Customer{
-		ID:          1,
 		Name:        "name",
 		Email:       "email",
 		MobilePhone: "mobile phone",
 		Password:    "password",
+		Version:     1,
+		CreatedAt:   time.Now(),
 		UpdatedAt:   time.Now(),
 	}
 
-	query := `UPDATE "customers" SET "name"=$1,"email"=$2,"mobile_phone"=$3,"password"=$4,"updated_at"=$5 WHERE "id" = $6`
+	query := `INSERT INTO "customers" ("name","email","mobile_phone","password","version","created_at","updated_at","deleted_at") VALUES ($1,$2,$3,$4,$5,$6,$7,$8) RETURNING "id"`
 	queryRegex := regexp.QuoteMeta(query)
 
 	dbMock.ExpectBegin()
-	dbMock.ExpectExec(queryRegex).WithArgs(data.Name, data.Email, data.MobilePhone, data.Password, utils.AnyTime{}, data.ID).WillReturnResult(sqlmock.NewResult(1, 1))
-	dbMock.ExpectCommit()
+	dbMock.ExpectQuery(queryRegex).WithArgs(data.Name, data.Email, data.MobilePhone, data.Password, data.Version, data.CreatedAt, data.UpdatedAt, nil).WillReturnError(&pgconn.PgError{Code: "23505", ConstraintName: "idx_customers_active_email"})
+	dbMock.ExpectRollback()
 
 	pgRepository := NewCustomerPgRepository(gormDb)
 
-	err := pgRepository.Update(context.TODO(), data)
-	assert.NoError(t, err)
+	err := pgRepository.Create(context.TODO(), &data)
+	assert.ErrorIs(t, err, constant.ErrEmailAlreadyExist)
+}
+
+func TestCustomerPgRepository_Update(t *testing.T) {
+	gormDb, dbMock := utils.GetDatabaseMock("postgres")
+
+	data := domain.Customer{
+		ID:          1,
+		Name:        "name",
+		Email:       "email",
+		MobilePhone: "mobile phone",
+		Password:    "password",
+		Version:     1,
+		UpdatedAt:   time.Now(),
+	}
+
+	query := `UPDATE "customers" SET "name"=$1,"email"=$2,"mobile_phone"=$3,"password"=$4,"version"=$5,"updated_at"=$6 WHERE version = $7 AND "customers"."deleted_at" IS NULL AND "id" = $8`
+	queryRegex := regexp.QuoteMeta(query)
+
+	t.Run("success", func(t *testing.T) {
+		dbMock.ExpectBegin()
+		dbMock.ExpectExec(queryRegex).WithArgs(data.Name, data.Email, data.MobilePhone, data.Password, 2, utils.AnyTime{}, 1, data.ID).WillReturnResult(sqlmock.NewResult(1, 1))
+		dbMock.ExpectCommit()
+
+		pgRepository := NewCustomerPgRepository(gormDb)
+
+		err := pgRepository.Update(context.TODO(), data)
+		assert.NoError(t, err)
+	})
+
+	t.Run("version-mismatch", func(t *testing.T) {
+		dbMock.ExpectBegin()
+		dbMock.ExpectExec(queryRegex).WithArgs(data.Name, data.Email, data.MobilePhone, data.Password, 2, utils.AnyTime{}, 1, data.ID).WillReturnResult(sqlmock.NewResult(0, 0))
+		dbMock.ExpectCommit()
+
+		pgRepository := NewCustomerPgRepository(gormDb)
+
+		err := pgRepository.Update(context.TODO(), data)
+		assert.ErrorIs(t, err, constant.ErrVersionMismatch)
+	})
+
+	t.Run("selected-columns", func(t *testing.T) {
+		cleared := data
+		cleared.Name = ""
+
+		dbMock.ExpectBegin()
+		dbMock.ExpectExec(regexp.QuoteMeta(`UPDATE "customers" SET "name"=$1,"version"=$2,"updated_at"=$3 WHERE version = $4 AND "customers"."deleted_at" IS NULL AND "id" = $5`)).
+			WithArgs("", 2, utils.AnyTime{}, 1, data.ID).WillReturnResult(sqlmock.NewResult(1, 1))
+		dbMock.ExpectCommit()
+
+		pgRepository := NewCustomerPgRepository(gormDb)
+
+		err := pgRepository.Update(context.TODO(), cleared, "name")
+		assert.NoError(t, err)
+		assert.NoError(t, dbMock.ExpectationsWereMet())
+	})
 }
 
 func TestCustomerPgRepository_FindOneCustomerByID(t *testing.T) {
 	gormDb, dbMock := utils.GetDatabaseMock("postgres")
 
-	query := `SELECT * FROM "customers" WHERE id =$1 ORDER BY "customers"."id" LIMIT 1`
+	query := `SELECT * FROM "customers" WHERE id =$1 AND "customers"."deleted_at" IS NULL ORDER BY "customers"."id" LIMIT 1`
 	queryRegex := regexp.QuoteMeta(query)
 
 	dbMock.ExpectQuery(queryRegex).WithArgs(1).WillReturnRows(
 		sqlmock.NewRows(
-			[]string{"id", "name", "email", "mobile_phone", "password", "created_at", "updated_at"}).
-			AddRow(1, "name", "email", "mobile phone", "password", time.Now(), time.Now()),
+			[]string{"id", "name", "email", "mobile_phone", "password", "version", "created_at", "updated_at"}).
+			AddRow(1, "name", "email", "mobile phone", "password", 1, time.Now(), time.Now()),
 	)
 
 	pgRepository := NewCustomerPgRepository(gormDb)
@@ -87,49 +151,269 @@ func TestCustomerPgRepository_FindOneCustomerByID(t *testing.T) {
 func TestCustomerPgRepository_FindCustomers(t *testing.T) {
 	gormDb, dbMock := utils.GetDatabaseMock("postgres")
 
-	queryCount := `SELECT count(*) FROM "customers"`
+	queryCount := `SELECT count(*) FROM "customers" WHERE "customers"."deleted_at" IS NULL`
 	queryRegexCount := regexp.QuoteMeta(queryCount)
 	dbMock.ExpectQuery(queryRegexCount).
 		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(10))
 
-	query := `SELECT * FROM "customers" LIMIT 10`
+	query := `SELECT * FROM "customers" WHERE "customers"."deleted_at" IS NULL ORDER BY "id" LIMIT 10`
 	queryRegex := regexp.QuoteMeta(query)
 	dbMock.ExpectQuery(queryRegex).
 		WillReturnRows(sqlmock.NewRows(
-			[]string{"id", "name", "email", "mobile_phone", "password", "created_at", "updated_at"}).
-			AddRow(1, "name", "email", "mobile phone", "password", time.Now(), time.Now()).
-			AddRow(2, "name", "email", "mobile phone", "password", time.Now(), time.Now()).
-			AddRow(3, "name", "email", "mobile phone", "password", time.Now(), time.Now()).
-			AddRow(4, "name", "email", "mobile phone", "password", time.Now(), time.Now()).
-			AddRow(5, "name", "email", "mobile phone", "password", time.Now(), time.Now()).
-			AddRow(6, "name", "email", "mobile phone", "password", time.Now(), time.Now()).
-			AddRow(7, "name", "email", "mobile phone", "password", time.Now(), time.Now()).
-			AddRow(8, "name", "email", "mobile phone", "password", time.Now(), time.Now()).
-			AddRow(9, "name", "email", "mobile phone", "password", time.Now(), time.Now()).
-			AddRow(10, "name", "email", "mobile phone", "password", time.Now(), time.Now()),
+			[]string{"id", "name", "email", "mobile_phone", "password", "version", "created_at", "updated_at"}).
+			AddRow(1, "name", "email", "mobile phone", "password", 1, time.Now(), time.Now()).
+			AddRow(2, "name", "email", "mobile phone", "password", 1, time.Now(), time.Now()).
+			AddRow(3, "name", "email", "mobile phone", "password", 1, time.Now(), time.Now()).
+			AddRow(4, "name", "email", "mobile phone", "password", 1, time.Now(), time.Now()).
+			AddRow(5, "name", "email", "mobile phone", "password", 1, time.Now(), time.Now()).
+			AddRow(6, "name", "email", "mobile phone", "password", 1, time.Now(), time.Now()).
+			AddRow(7, "name", "email", "mobile phone", "password", 1, time.Now(), time.Now()).
+			AddRow(8, "name", "email", "mobile phone", "password", 1, time.Now(), time.Now()).
+			AddRow(9, "name", "email", "mobile phone", "password", 1, time.Now(), time.Now()).
+			AddRow(10, "name", "email", "mobile phone", "password", 1, time.Now(), time.Now()),
 		)
 
 	pgRepository := NewCustomerPgRepository(gormDb)
 
-	data, err := pgRepository.FindCustomers(context.WithValue(context.TODO(), "requestCtx", &http.Request{URL: &url.URL{
-		Scheme: "http",
-		Host:   "localhost:8083",
-		Path:   "/api/v1/customers",
-	}}), 1, 10, "", "")
+	data, err := pgRepository.FindCustomers(context.TODO(), customer.ListQuery{PaginationQuery: utils.PaginationQuery{Page: 1, Size: 10}})
 
 	assert.NoError(t, err)
 	assert.NotNil(t, data.Records)
 
 }
 
+func TestCustomerPgRepository_FindCustomersWithSearch(t *testing.T) {
+	gormDb, dbMock := utils.GetDatabaseMock("postgres")
+
+	queryCount := `SELECT count(*) FROM "customers" WHERE (("name" ILIKE $1 ESCAPE '!' OR "email" ILIKE $2 ESCAPE '!' OR "mobile_phone" ILIKE $3 ESCAPE '!')) AND "customers"."deleted_at" IS NULL`
+	dbMock.ExpectQuery(regexp.QuoteMeta(queryCount)).
+		WithArgs("%50!%!_off%", "%50!%!_off%", "%50!%!_off%").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	query := `SELECT * FROM "customers" WHERE (("name" ILIKE $1 ESCAPE '!' OR "email" ILIKE $2 ESCAPE '!' OR "mobile_phone" ILIKE $3 ESCAPE '!')) AND "customers"."deleted_at" IS NULL ORDER BY "id" LIMIT 10`
+	dbMock.ExpectQuery(regexp.QuoteMeta(query)).
+		WithArgs("%50!%!_off%", "%50!%!_off%", "%50!%!_off%").
+		WillReturnRows(sqlmock.NewRows(
+			[]string{"id", "name", "email", "mobile_phone", "password", "version", "created_at", "updated_at"}).
+			AddRow(1, "50%_off", "email", "mobile phone", "password", 1, time.Now(), time.Now()))
+
+	pgRepository := NewCustomerPgRepository(gormDb)
+
+	data, err := pgRepository.FindCustomers(context.TODO(), customer.ListQuery{PaginationQuery: utils.PaginationQuery{Page: 1, Size: 10, Search: "50%_off"}})
+
+	assert.NoError(t, err)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+	assert.Equal(t, int64(1), data.Total)
+}
+
+func TestCustomerPgRepository_FindCustomersWithSort(t *testing.T) {
+	gormDb, dbMock := utils.GetDatabaseMock("postgres")
+
+	dbMock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "customers" WHERE "customers"."deleted_at" IS NULL`)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	query := `SELECT * FROM "customers" WHERE "customers"."deleted_at" IS NULL ORDER BY "created_at" DESC,"name","id" LIMIT 10`
+	dbMock.ExpectQuery(regexp.QuoteMeta(query)).
+		WillReturnRows(sqlmock.NewRows(
+			[]string{"id", "name", "email", "mobile_phone", "password", "version", "created_at", "updated_at"}).
+			AddRow(1, "name", "email", "mobile phone", "password", 1, time.Now(), time.Now()))
+
+	pgRepository := NewCustomerPgRepository(gormDb)
+	ctx := context.TODO()
+
+	_, err := pgRepository.FindCustomers(ctx, customer.ListQuery{PaginationQuery: utils.PaginationQuery{Page: 1, Size: 10, OrderBy: "-created_at,name"}})
+	assert.NoError(t, err)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+
+	_, err = pgRepository.FindCustomers(ctx, customer.ListQuery{PaginationQuery: utils.PaginationQuery{Page: 1, Size: 10, OrderBy: "-password"}})
+	var sortError *database.SortError
+	assert.ErrorAs(t, err, &sortError)
+	assert.Equal(t, "-password", sortError.Field)
+	assert.Equal(t, []string{"id", "name", "email", "mobile_phone", "created_at", "updated_at"}, sortError.Allowed)
+}
+
+func TestCustomerPgRepository_FindCustomersWithFilter(t *testing.T) {
+	gormDb, dbMock := utils.GetDatabaseMock("postgres")
+
+	createdFrom := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	queryCount := `SELECT count(*) FROM "customers" WHERE ("created_at" >= $1 AND "id" IN ($2,$3)) AND "customers"."deleted_at" IS NULL`
+	dbMock.ExpectQuery(regexp.QuoteMeta(queryCount)).
+		WithArgs(createdFrom, int64(1), int64(2)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	query := `SELECT * FROM "customers" WHERE ("created_at" >= $1 AND "id" IN ($2,$3)) AND "customers"."deleted_at" IS NULL ORDER BY "id" LIMIT 10`
+	dbMock.ExpectQuery(regexp.QuoteMeta(query)).
+		WithArgs(createdFrom, int64(1), int64(2)).
+		WillReturnRows(sqlmock.NewRows(
+			[]string{"id", "name", "email", "mobile_phone", "password", "version", "created_at", "updated_at"}).
+			AddRow(1, "name", "email", "mobile phone", "password", 1, time.Now(), time.Now()))
+
+	pgRepository := NewCustomerPgRepository(gormDb)
+	ctx := context.TODO()
+
+	filters, err := database.ParseFilters(url.Values{
+		"filter[created_at][gte]": {"2026-01-01"},
+		"filter[id][in]":          {"1,2"},
+	})
+	assert.NoError(t, err)
+
+	data, err := pgRepository.FindCustomers(ctx, customer.ListQuery{PaginationQuery: utils.PaginationQuery{Page: 1, Size: 10, Filters: filters}})
+	assert.NoError(t, err)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+	assert.Equal(t, int64(1), data.Total)
+
+	for _, item := range []struct {
+		filter database.Filter
+		reason string
+	}{
+		{database.Filter{Field: "password", Operator: database.FilterEqual, Values: []string{"secret"}}, database.FilterReasonUnknownField},
+		{database.Filter{Field: "email", Operator: database.FilterGreater, Values: []string{"a"}}, database.FilterReasonUnknownOperator},
+		{database.Filter{Field: "id", Operator: database.FilterIn, Values: []string{"1", "x"}}, database.FilterReasonInvalidValue},
+	} {
+		_, err = pgRepository.FindCustomers(ctx, customer.ListQuery{PaginationQuery: utils.PaginationQuery{Page: 1, Size: 10, Filters: []database.Filter{item.filter}}})
+		var filterError *database.FilterError
+		assert.ErrorAs(t, err, &filterError)
+		assert.Equal(t, item.reason, filterError.Reason)
+	}
+}
+
+func TestCustomerPgRepository_FindCustomersInMemory(t *testing.T) {
+	db, err := database.NewInMemory(&domain.Customer{})
+	assert.NoError(t, err)
+	defer db.Close()
+	assert.NoError(t, MigrateIndexes(context.TODO(), db.Conn()))
+
+	pgRepository := NewCustomerPgRepository(db.Conn())
+
+	assert.NoError(t, pgRepository.Create(context.TODO(), &domain.Customer{Name: "Alice", Email: "alice@test.com", MobilePhone: "087766777001", Password: "password"}))
+	assert.NoError(t, pgRepository.Create(context.TODO(), &domain.Customer{Name: "Bob", Email: "bob@test.com", MobilePhone: "087766777002", Password: "password"}))
+
+	data, err := pgRepository.FindCustomers(context.TODO(), customer.ListQuery{PaginationQuery: utils.PaginationQuery{Page: 1, Size: 10, Search: "ALICE"}})
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), data.Total)
+	assert.Equal(t, "Alice", (*data.Records.(*[]domain.Customer))[0].Name)
+
+	data, err = pgRepository.FindCustomers(context.TODO(), customer.ListQuery{PaginationQuery: utils.PaginationQuery{Page: -1, Size: 1000}})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, data.CurrentPage)
+	assert.Equal(t, database.DefaultMaxPageSize, data.PageSize)
+	assert.Len(t, *data.Records.(*[]domain.Customer), 2)
+
+	// a page whose offset overflows reads an empty page instead of failing
+	data, err = pgRepository.FindCustomers(context.TODO(), customer.ListQuery{PaginationQuery: utils.PaginationQuery{Page: math.MaxInt64 / 5, Size: 10}})
+	assert.NoError(t, err)
+	assert.Equal(t, math.MaxInt32/10+1, data.CurrentPage)
+	assert.Empty(t, *data.Records.(*[]domain.Customer))
+
+	err = pgRepository.Create(context.TODO(), &domain.Customer{Name: "Alice", Email: "alice@test.com", MobilePhone: "087766777003", Password: "password"})
+	assert.ErrorIs(t, err, constant.ErrEmailAlreadyExist)
+}
+
+func TestCustomerPgRepository_FindCustomersInBatches(t *testing.T) {
+	db, err := database.NewInMemory(&domain.Customer{})
+	assert.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.TODO()
+	pgRepository := NewCustomerPgRepository(db.Conn())
+
+	for i, name := range []string{"Carol", "Alice", "Dave", "Bob", "Alan"} {
+		assert.NoError(t, pgRepository.Create(ctx, &domain.Customer{Name: name, Email: fmt.Sprintf("customer%d@test.com", i), MobilePhone: fmt.Sprintf("08776677700%d", i), Password: "password"}))
+	}
+	assert.NoError(t, pgRepository.Delete(ctx, 3))
+
+	var names []string
+	var batches int
+	err = pgRepository.FindCustomersInBatches(ctx, customer.ListQuery{PaginationQuery: utils.PaginationQuery{OrderBy: "name"}}, 2, func(entities []domain.Customer) error {
+		batches++
+		for _, entity := range entities {
+			names = append(names, entity.Name)
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, batches)
+	assert.Equal(t, []string{"Alan", "Alice", "Bob", "Carol"}, names)
+
+	names = nil
+	err = pgRepository.FindCustomersInBatches(ctx, customer.ListQuery{PaginationQuery: utils.PaginationQuery{Search: "al"}, WithDeleted: true}, 2, func(entities []domain.Customer) error {
+		for _, entity := range entities {
+			names = append(names, entity.Name)
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Alice", "Alan"}, names)
+
+	err = pgRepository.FindCustomersInBatches(ctx, customer.ListQuery{PaginationQuery: utils.PaginationQuery{OrderBy: "-password"}}, 2, func(entities []domain.Customer) error {
+		return nil
+	})
+	var sortError *database.SortError
+	assert.ErrorAs(t, err, &sortError)
+}
+
+func TestCustomerPgRepository_FindCustomersWithCursor(t *testing.T) {
+	db, err := database.NewInMemory(&domain.Customer{})
+	assert.NoError(t, err)
+	defer db.Close()
+
+	pgRepository := NewCustomerPgRepository(db.Conn())
+
+	for i, name := range []string{"Carol", "Alice", "Bob"} {
+		assert.NoError(t, pgRepository.Create(context.TODO(), &domain.Customer{
+			Name:        name,
+			Email:       fmt.Sprintf("customer%d@test.com", i),
+			MobilePhone: fmt.Sprintf("08776677700%d", i),
+			Password:    "password",
+		}))
+	}
+
+	ctx := context.TODO()
+	names := func(paginator *database.Paginator) []string {
+		var result []string
+		for _, entity := range *paginator.Records.(*[]domain.Customer) {
+			result = append(result, entity.Name)
+		}
+		return result
+	}
+
+	links, err := database.ParseURLLinkBuilder("http://localhost:8083/api/v1/customers?orderBy=name&size=2")
+	assert.NoError(t, err)
+
+	first, err := pgRepository.FindCustomers(ctx, customer.ListQuery{PaginationQuery: utils.PaginationQuery{Size: 2, OrderBy: "name", CursorMode: true, LinkBuilder: links}})
+	assert.NoError(t, err)
+	assert.Equal(t, "http://localhost:8083/api/v1/customers?cursor="+first.Cursors.Next+"&orderBy=name&size=2", first.Links.Next)
+	assert.Equal(t, []string{"Alice", "Bob"}, names(first))
+	assert.Equal(t, int64(0), first.Total)
+	assert.NotEmpty(t, first.Cursors.Next)
+	assert.Empty(t, first.Cursors.Prev)
+
+	second, err := pgRepository.FindCustomers(ctx, customer.ListQuery{PaginationQuery: utils.PaginationQuery{Size: 2, OrderBy: "name", CursorMode: true, Cursor: first.Cursors.Next, Count: true}})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Carol"}, names(second))
+	assert.Equal(t, int64(3), second.Total)
+	assert.Empty(t, second.Cursors.Next)
+	assert.NotEmpty(t, second.Cursors.Prev)
+
+	previous, err := pgRepository.FindCustomers(ctx, customer.ListQuery{PaginationQuery: utils.PaginationQuery{Size: 2, OrderBy: "name", CursorMode: true, Cursor: second.Cursors.Prev}})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Alice", "Bob"}, names(previous))
+	assert.NotEmpty(t, previous.Cursors.Next)
+	assert.Empty(t, previous.Cursors.Prev)
+
+	_, err = pgRepository.FindCustomers(ctx, customer.ListQuery{PaginationQuery: utils.PaginationQuery{Size: 2, CursorMode: true, Cursor: first.Cursors.Next}})
+	assert.ErrorIs(t, err, database.ErrInvalidCursor)
+}
+
 func TestCustomerPgRepository_Delete(t *testing.T) {
 	gormDb, dbMock := utils.GetDatabaseMock("postgres")
 
-	query := `DELETE FROM "customers" WHERE "customers"."id" = $1`
+	query := `UPDATE "customers" SET "deleted_at"=$1 WHERE "customers"."id" = $2 AND "customers"."deleted_at" IS NULL`
 	queryRegex := regexp.QuoteMeta(query)
 	dbMock.ExpectBegin()
 	dbMock.ExpectExec(queryRegex).
-		WithArgs(1).
+		WithArgs(utils.AnyTime{}, 1).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 	dbMock.ExpectCommit()
 
@@ -139,3 +423,231 @@ func TestCustomerPgRepository_Delete(t *testing.T) {
 	assert.NoError(t, err)
 
 }
+
+func TestCustomerPgRepository_Purge(t *testing.T) {
+	gormDb, dbMock := utils.GetDatabaseMock("postgres")
+
+	query := `DELETE FROM "customers" WHERE "customers"."id" = $1`
+	queryRegex := regexp.QuoteMeta(query)
+	pgRepository := NewCustomerPgRepository(gormDb)
+
+	t.Run("success", func(t *testing.T) {
+		dbMock.ExpectBegin()
+		dbMock.ExpectExec(queryRegex).
+			WithArgs(1).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		dbMock.ExpectCommit()
+
+		err := pgRepository.Purge(context.TODO(), 1)
+		assert.NoError(t, err)
+	})
+
+	t.Run("not-found", func(t *testing.T) {
+		dbMock.ExpectBegin()
+		dbMock.ExpectExec(queryRegex).
+			WithArgs(2).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		dbMock.ExpectCommit()
+
+		err := pgRepository.Purge(context.TODO(), 2)
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+		assert.NoError(t, dbMock.ExpectationsWereMet())
+	})
+}
+
+func TestCustomerPgRepository_SoftDeleteInMemory(t *testing.T) {
+	db, err := database.NewInMemory(&domain.Customer{})
+	assert.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.TODO()
+	pgRepository := NewCustomerPgRepository(db.Conn())
+
+	alice := domain.Customer{Name: "Alice", Email: "alice@test.com", MobilePhone: "087766777001", Password: "password"}
+	assert.NoError(t, pgRepository.Create(ctx, &alice))
+	assert.NoError(t, pgRepository.Delete(ctx, alice.ID))
+
+	_, err = pgRepository.FindOneCustomerByID(ctx, alice.ID)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	deleted, err := pgRepository.FindDeletedCustomerByID(ctx, alice.ID)
+	assert.NoError(t, err)
+	assert.True(t, deleted.DeletedAt.Valid)
+
+	data, err := pgRepository.FindCustomers(ctx, customer.ListQuery{PaginationQuery: utils.PaginationQuery{Page: 1, Size: 10}})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), data.Total)
+
+	data, err = pgRepository.FindCustomers(ctx, customer.ListQuery{PaginationQuery: utils.PaginationQuery{Page: 1, Size: 10}, WithDeleted: true})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), data.Total)
+
+	// the email of a soft-deleted customer is released by default
+	count, err := pgRepository.CheckDuplicate(ctx, "email =?", alice.Email)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), count)
+
+	count, err = NewCustomerPgRepository(db.Conn(), RepositoryDuplicateScope(DuplicateScopeAll)).CheckDuplicate(ctx, "email =?", alice.Email)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+
+	assert.NoError(t, pgRepository.Restore(ctx, alice.ID))
+	restored, err := pgRepository.FindOneCustomerByID(ctx, alice.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, restored.Version)
+	assert.ErrorIs(t, pgRepository.Restore(ctx, alice.ID), gorm.ErrRecordNotFound)
+
+	assert.NoError(t, pgRepository.Purge(ctx, alice.ID))
+	_, err = pgRepository.FindDeletedCustomerByID(ctx, alice.ID)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func TestCustomerPgRepository_MergeInMemory(t *testing.T) {
+	db, err := database.NewInMemory(&domain.Customer{}, &domain.CustomerAddress{}, &domain.CustomerMerge{}, &domain.LoyaltyEntry{},
+		&domain.CustomerSegment{}, &domain.CustomerSegmentMember{}, &domain.WalletEntry{})
+	assert.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.TODO()
+	pgRepository := NewCustomerPgRepository(db.Conn())
+
+	john := domain.Customer{Name: "John Smith", Email: "john@test.com", MobilePhone: "087766777001", Password: "password"}
+	jhon := domain.Customer{Name: "Jhon Smith", Email: "JOHN@test.com ", MobilePhone: "087766777002", Password: "password"}
+	johnny := domain.Customer{Name: "Johnny", Email: "johnny@test.com", MobilePhone: "+6287766777001", Password: "password"}
+	alice := domain.Customer{Name: "Alice", Email: "alice@test.com", MobilePhone: "087766777003", Password: "password"}
+	for _, entity := range []*domain.Customer{&john, &jhon, &johnny, &alice} {
+		assert.NoError(t, pgRepository.Create(ctx, entity))
+	}
+
+	candidates, err := pgRepository.FindDuplicateCandidates(ctx, john, 10)
+	assert.NoError(t, err)
+	var ids []int
+	for _, candidate := range candidates {
+		ids = append(ids, candidate.ID)
+	}
+	assert.Equal(t, []int{jhon.ID, johnny.ID}, ids)
+
+	addresses := []domain.CustomerAddress{
+		{CustomerID: jhon.ID, Label: "Home", IsDefault: true},
+		{CustomerID: jhon.ID, Label: "Office"},
+		{CustomerID: johnny.ID, Label: "Warehouse", IsDefault: true},
+	}
+	assert.NoError(t, db.Conn().Create(&addresses).Error)
+
+	assert.NoError(t, pgRepository.Merge(ctx, john, []domain.Customer{jhon, johnny}))
+
+	survivor, err := pgRepository.FindOneCustomerByID(ctx, john.ID, "Addresses")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, survivor.Version)
+	// the survivor had no address, the oldest moved address is its default
+	assert.Len(t, survivor.Addresses, 3)
+	for _, address := range survivor.Addresses {
+		assert.Equal(t, address.ID == addresses[0].ID, address.IsDefault)
+	}
+	_, err = pgRepository.FindOneCustomerByID(ctx, jhon.ID)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	var merges []domain.CustomerMerge
+	assert.NoError(t, db.Conn().Order("merged_id").Find(&merges).Error)
+	assert.Len(t, merges, 2)
+	assert.Equal(t, john.ID, merges[0].SurvivorID)
+	assert.Equal(t, jhon.ID, merges[0].MergedID)
+	assert.Equal(t, "JOHN@test.com ", merges[0].Email)
+
+	// the survivor was read before the first merge
+	err = pgRepository.Merge(ctx, john, []domain.Customer{alice})
+	assert.ErrorIs(t, err, constant.ErrVersionMismatch)
+
+	// the customers are locked before their records are moved
+	aliceAddress := domain.CustomerAddress{CustomerID: alice.ID, Label: "Home", IsDefault: true}
+	assert.NoError(t, db.Conn().Create(&aliceAddress).Error)
+	err = pgRepository.Merge(ctx, domain.Customer{ID: jhon.ID, Version: 1}, []domain.Customer{alice})
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	assert.NoError(t, db.Conn().First(&aliceAddress, aliceAddress.ID).Error)
+	assert.Equal(t, alice.ID, aliceAddress.CustomerID)
+}
+
+func TestMigrateIndexes(t *testing.T) {
+	db, err := database.NewInMemory(&domain.Customer{}, &domain.CustomerAddress{}, &domain.LoyaltyEntry{})
+	assert.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.TODO()
+	// unique indexes of a database migrated before the soft delete
+	assert.NoError(t, db.Conn().Exec("CREATE UNIQUE INDEX idx_customers_email ON customers (email)").Error)
+	assert.NoError(t, db.Conn().Exec("CREATE UNIQUE INDEX idx_customers_mobile_phone ON customers (mobile_phone)").Error)
+	// default address index built without its condition
+	assert.NoError(t, db.Conn().Exec("CREATE UNIQUE INDEX idx_customer_addresses_default ON customer_addresses (customer_id)").Error)
+	assert.NoError(t, db.Conn().Exec("CREATE UNIQUE INDEX idx_loyalty_entries_earn_reference ON loyalty_entries (reference)").Error)
+
+	assert.NoError(t, MigrateIndexes(ctx, db.Conn()))
+	assert.NoError(t, MigrateIndexes(ctx, db.Conn()))
+	assert.False(t, db.Conn().Migrator().HasIndex("customers", "idx_customers_email"))
+	assert.False(t, db.Conn().Migrator().HasIndex("customers", "idx_customers_mobile_phone"))
+	assert.False(t, db.Conn().Migrator().HasIndex("customer_addresses", "idx_customer_addresses_default"))
+	assert.False(t, db.Conn().Migrator().HasIndex("loyalty_entries", "idx_loyalty_entries_earn_reference"))
+	assert.True(t, db.Conn().Migrator().HasIndex("loyalty_entries", loyaltyEarnReferenceIndex))
+	assert.True(t, db.Conn().Migrator().HasIndex("customers", emailUniqueIndex))
+	assert.True(t, db.Conn().Migrator().HasIndex("customers", mobilePhoneUniqueIndex))
+
+	pgRepository := NewCustomerPgRepository(db.Conn())
+	alice := domain.Customer{Name: "Alice", Email: "alice@test.com", MobilePhone: "+6281234567001", Password: "password"}
+	assert.NoError(t, pgRepository.Create(ctx, &alice))
+	err = pgRepository.Create(ctx, &domain.Customer{Name: "Alice", Email: "alice@test.com", MobilePhone: "+6281234567002", Password: "password"})
+	assert.ErrorIs(t, err, constant.ErrEmailAlreadyExist)
+	err = pgRepository.Create(ctx, &domain.Customer{Name: "Alice", Email: "alice2@test.com", MobilePhone: "+6281234567001", Password: "password"})
+	assert.ErrorIs(t, err, constant.ErrMobilePhoneAlreadyExist)
+
+	// a soft-deleted customer releases its email and mobile phone
+	assert.NoError(t, db.Conn().Delete(&domain.Customer{}, alice.ID).Error)
+	assert.NoError(t, pgRepository.Create(ctx, &domain.Customer{Name: "Alice", Email: "alice@test.com", MobilePhone: "+6281234567001", Password: "password"}))
+}
+
+func TestMigrateMobilePhones(t *testing.T) {
+	db, err := database.NewInMemory(&domain.Customer{})
+	assert.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.TODO()
+	assert.NoError(t, MigrateIndexes(ctx, db.Conn()))
+	customers := []domain.Customer{
+		{Name: "Alice", Email: "alice@test.com", MobilePhone: "0812-3456-7890", Password: "password"},
+		{Name: "Bob", Email: "bob@test.com", MobilePhone: "+6281234567891", Password: "password"},
+		{Name: "Carol", Email: "carol@test.com", MobilePhone: "6281234567891", Password: "password"},
+		{Name: "Dave", Email: "dave@test.com", MobilePhone: "0212345678", Password: "password"},
+		{Name: "Erin", Email: "erin@test.com", MobilePhone: "+62 812-3456-7892", Password: "password"},
+		{Name: "Frank", Email: "frank@test.com", MobilePhone: "+6208123456793", Password: "password"},
+	}
+	assert.NoError(t, db.Conn().Create(&customers).Error)
+	// an erased customer has no mobile phone to convert
+	erased := domain.Customer{Name: "", Email: "erased-1", Password: ""}
+	assert.NoError(t, db.Conn().Create(&erased).Error)
+	assert.NoError(t, db.Conn().Model(&erased).UpdateColumn("mobile_phone", nil).Error)
+
+	converted, skipped, err := MigrateMobilePhones(ctx, db.Conn())
+	assert.NoError(t, err)
+	assert.Equal(t, 3, converted)
+	// Carol has the mobile phone of Bob, Dave a landline
+	assert.Equal(t, []int{customers[2].ID, customers[3].ID}, skipped)
+
+	var alice domain.Customer
+	assert.NoError(t, db.Conn().First(&alice, customers[0].ID).Error)
+	assert.Equal(t, "+6281234567890", alice.MobilePhone)
+	assert.Equal(t, 2, alice.Version)
+
+	// the numbers written with a + are converted as well when not in E.164 form
+	var erin, frank domain.Customer
+	assert.NoError(t, db.Conn().First(&erin, customers[4].ID).Error)
+	assert.Equal(t, "+6281234567892", erin.MobilePhone)
+	assert.NoError(t, db.Conn().First(&frank, customers[5].ID).Error)
+	assert.Equal(t, "+628123456793", frank.MobilePhone)
+
+	var bob domain.Customer
+	assert.NoError(t, db.Conn().First(&bob, customers[1].ID).Error)
+	assert.Equal(t, 1, bob.Version)
+
+	converted, skipped, err = MigrateMobilePhones(ctx, db.Conn())
+	assert.NoError(t, err)
+	assert.Equal(t, 0, converted)
+	assert.Len(t, skipped, 2)
+}
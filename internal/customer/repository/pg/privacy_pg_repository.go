@@ -0,0 +1,122 @@
+package pg
+
+import (
+	"context"
+	"fmt"
+	"github.com/alpakih/point-of-sales/internal/customer"
+	"github.com/alpakih/point-of-sales/internal/domain"
+	"github.com/alpakih/point-of-sales/pkg/database"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type customerPrivacyPgRepository struct {
+	db *gorm.DB
+}
+
+func NewCustomerPrivacyPgRepository(db *gorm.DB) customer.PrivacyPgRepository {
+	return &customerPrivacyPgRepository{db: db}
+}
+
+// LockCustomer locks the customer, soft-deleted or not, until the end of the
+// transaction. gorm.ErrRecordNotFound is returned when it doesn't exist.
+func (c customerPrivacyPgRepository) LockCustomer(ctx context.Context, customerID int) error {
+	var entity domain.Customer
+	return database.FromContext(ctx, c.db).Unscoped().
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id").
+		First(&entity, "id = ?", customerID).Error
+}
+
+// FindPersonalData reads everything stored about the customer, the tier changes
+// the latest first and the other records in the order of their ids.
+// gorm.ErrRecordNotFound is returned when it doesn't exist.
+func (c customerPrivacyPgRepository) FindPersonalData(ctx context.Context, customerID int) (customer.PersonalData, error) {
+	var data customer.PersonalData
+	db := database.FromContext(ctx, c.db)
+
+	err := db.Unscoped().
+		Preload("Addresses", func(db *gorm.DB) *gorm.DB {
+			return db.Order("id")
+		}).
+		First(&data.Customer, "id = ?", customerID).Error
+	if err != nil {
+		return data, err
+	}
+
+	err = db.Where("customer_id = ?", customerID).
+		Preload("Lines", func(db *gorm.DB) *gorm.DB {
+			return db.Order("id")
+		}).
+		Preload("RelatedEntries", "type = ?", domain.LoyaltyEntryReversal).
+		Order("id").
+		Find(&data.LoyaltyEntries).Error
+	if err != nil {
+		return data, err
+	}
+	if err := db.Where("customer_id = ?", customerID).Order("id").Find(&data.WalletEntries).Error; err != nil {
+		return data, err
+	}
+	if err := db.Where("customer_id = ?", customerID).Order("id DESC").Find(&data.TierChanges).Error; err != nil {
+		return data, err
+	}
+	err = db.Where("id IN (?)", db.Session(&gorm.Session{NewDB: true}).Model(&domain.CustomerSegmentMember{}).
+		Select("segment_id").
+		Where("customer_id = ?", customerID)).
+		Order("name").
+		Find(&data.Segments).Error
+	if err != nil {
+		return data, err
+	}
+	if err := db.Where("customer_id = ?", customerID).Order("id").Find(&data.Consents).Error; err != nil {
+		return data, err
+	}
+	if err := db.Where("merged_id = ? OR survivor_id = ?", customerID, customerID).Order("id").Find(&data.Merges).Error; err != nil {
+		return data, err
+	}
+	err = db.Where("customer_id = ?", customerID).Order("id").Find(&data.Erasures).Error
+	return data, err
+}
+
+// Erase anonymizes the personal data of the customer and soft-deletes it: its
+// name and password are cleared, its email replaced by the erased-<id>
+// placeholder, unique to the customer, its mobile phone set to NULL, which the
+// unique indexes and the mobile phone migration ignore, its addresses and segment
+// memberships deleted and the contact details the merge audit trail kept of it
+// cleared. Its sales, ledgers and consent changes are kept, the erasure is
+// recorded.
+func (c customerPrivacyPgRepository) Erase(ctx context.Context, erasure *domain.CustomerErasure) error {
+	db := database.FromContext(ctx, c.db)
+
+	result := db.Unscoped().Model(&domain.Customer{}).
+		Where("id = ?", erasure.CustomerID).
+		Updates(map[string]interface{}{
+			"name":         "",
+			"email":        fmt.Sprintf("erased-%d", erasure.CustomerID),
+			"mobile_phone": nil,
+			"password":     "",
+			"version":      gorm.Expr("version + 1"),
+			"deleted_at":   gorm.Expr("COALESCE(deleted_at, ?)", erasure.CreatedAt),
+			"updated_at":   erasure.CreatedAt,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	if err := db.Delete(&domain.CustomerAddress{}, "customer_id = ?", erasure.CustomerID).Error; err != nil {
+		return err
+	}
+	if err := db.Delete(&domain.CustomerSegmentMember{}, "customer_id = ?", erasure.CustomerID).Error; err != nil {
+		return err
+	}
+	err := db.Model(&domain.CustomerMerge{}).
+		Where("merged_id = ?", erasure.CustomerID).
+		Updates(map[string]interface{}{"name": "", "email": "", "mobile_phone": ""}).Error
+	if err != nil {
+		return err
+	}
+	return db.Create(erasure).Error
+}
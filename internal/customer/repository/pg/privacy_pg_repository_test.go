@@ -0,0 +1,106 @@
+package pg
+
+import (
+	"context"
+	"fmt"
+	"github.com/alpakih/point-of-sales/internal/domain"
+	"github.com/alpakih/point-of-sales/pkg/database"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"testing"
+	"time"
+)
+
+func TestCustomerPrivacyPgRepository_InMemory(t *testing.T) {
+	db, err := database.NewInMemory(&domain.Customer{}, &domain.CustomerAddress{}, &domain.CustomerMerge{}, &domain.LoyaltyEntry{},
+		&domain.LoyaltyEntryLine{}, &domain.CustomerTierChange{}, &domain.CustomerSegment{}, &domain.CustomerSegmentMember{},
+		&domain.WalletEntry{}, &domain.CustomerErasure{}, &domain.CustomerConsent{})
+	assert.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.TODO()
+	assert.NoError(t, MigrateIndexes(ctx, db.Conn()))
+	// the erased customers don't collide on an index counting the soft-deleted customers
+	assert.NoError(t, db.Conn().Exec("CREATE UNIQUE INDEX idx_customers_email ON customers (email)").Error)
+	assert.NoError(t, db.Conn().Exec("CREATE UNIQUE INDEX idx_customers_mobile_phone ON customers (mobile_phone)").Error)
+	pgRepository := NewCustomerPgRepository(db.Conn())
+	privacyPgRepository := NewCustomerPrivacyPgRepository(db.Conn())
+
+	john := domain.Customer{Name: "John Smith", Email: "john@test.com", MobilePhone: "+6287766777001", Password: "password"}
+	jhon := domain.Customer{Name: "Jhon Smith", Email: "jhon@test.com", MobilePhone: "+6287766777002", Password: "password"}
+	for _, entity := range []*domain.Customer{&john, &jhon} {
+		assert.NoError(t, pgRepository.Create(ctx, entity))
+	}
+	assert.NoError(t, db.Conn().Create(&domain.CustomerAddress{CustomerID: jhon.ID, Label: "home", Recipient: "Jhon", Phone: "+6287766777002",
+		Street: "Jl. Merdeka 1", City: "Bandung", Province: "Jawa Barat", PostalCode: "40111", IsDefault: true}).Error)
+	_, err = NewCustomerLoyaltyPgRepository(db.Conn()).Append(ctx, []domain.LoyaltyEntry{
+		{CustomerID: jhon.ID, Type: domain.LoyaltyEntryEarn, Points: 5, Amount: 50000, Reference: "S-1", Lines: []domain.LoyaltyEntryLine{
+			{Product: "Kopi Susu", Quantity: 2, Amount: 50000},
+		}},
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, NewCustomerWalletPgRepository(db.Conn()).Append(ctx, &domain.WalletEntry{
+		CustomerID: jhon.ID, Type: domain.WalletEntryTopUp, Amount: 10000, Balance: 10000, Reference: "P-1",
+	}))
+	vip := domain.CustomerSegment{Name: "vip", Type: domain.CustomerSegmentStatic}
+	assert.NoError(t, db.Conn().Create(&vip).Error)
+	assert.NoError(t, NewCustomerSegmentPgRepository(db.Conn()).AddMembers(ctx, vip.ID, []int{jhon.ID}))
+	assert.NoError(t, NewCustomerConsentPgRepository(db.Conn()).Append(ctx, []domain.CustomerConsent{
+		{CustomerID: jhon.ID, Channel: domain.ConsentChannelEmail, Granted: true, Source: domain.ConsentSourcePOS},
+	}))
+
+	// jhon is merged into john, its records move to john
+	assert.NoError(t, pgRepository.Merge(ctx, john, []domain.Customer{jhon}))
+
+	data, err := privacyPgRepository.FindPersonalData(ctx, jhon.ID)
+	assert.NoError(t, err)
+	assert.True(t, data.Customer.DeletedAt.Valid)
+	assert.Len(t, data.LoyaltyEntries, 2)
+	assert.Len(t, data.LoyaltyEntries[0].Lines, 1)
+	assert.Len(t, data.WalletEntries, 2)
+	// the consents were given for the contact details of jhon, they aren't moved
+	assert.Len(t, data.Consents, 1)
+	assert.Len(t, data.Merges, 1)
+
+	data, err = privacyPgRepository.FindPersonalData(ctx, john.ID)
+	assert.NoError(t, err)
+	assert.Len(t, data.Customer.Addresses, 1)
+	assert.Len(t, data.Segments, 1)
+	assert.Len(t, data.Merges, 1)
+	assert.Empty(t, data.Erasures)
+
+	assert.NoError(t, privacyPgRepository.LockCustomer(ctx, jhon.ID))
+	erasure := domain.CustomerErasure{CustomerID: jhon.ID, PerformedBy: "A-01", Reason: "data subject request", CreatedAt: time.Now()}
+	assert.NoError(t, privacyPgRepository.Erase(ctx, &erasure))
+	assert.NotZero(t, erasure.ID)
+	erasure = domain.CustomerErasure{CustomerID: john.ID, PerformedBy: "A-01", CreatedAt: time.Now()}
+	assert.NoError(t, privacyPgRepository.Erase(ctx, &erasure))
+
+	data, err = privacyPgRepository.FindPersonalData(ctx, john.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, data.Customer.Name)
+	assert.Equal(t, fmt.Sprintf("erased-%d", john.ID), data.Customer.Email)
+	assert.Empty(t, data.Customer.MobilePhone)
+	assert.Empty(t, data.Customer.Password)
+	assert.Equal(t, 3, data.Customer.Version)
+	assert.True(t, data.Customer.DeletedAt.Valid)
+	assert.Empty(t, data.Customer.Addresses)
+	assert.Empty(t, data.Segments)
+	// the merge trail keeps no contact detail of jhon, the sales are kept
+	assert.Empty(t, data.Merges[0].Email)
+	assert.Len(t, data.Erasures, 1)
+
+	data, err = privacyPgRepository.FindPersonalData(ctx, jhon.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf("erased-%d", jhon.ID), data.Customer.Email)
+	assert.Len(t, data.LoyaltyEntries, 2)
+	assert.Equal(t, int64(50000), data.LoyaltyEntries[0].Amount)
+	// the erased mobile phones are NULL, not a value taken for a mobile phone
+	var erasedPhones int64
+	assert.NoError(t, db.Conn().Unscoped().Model(&domain.Customer{}).Where("mobile_phone IS NULL").Count(&erasedPhones).Error)
+	assert.Equal(t, int64(2), erasedPhones)
+
+	_, err = privacyPgRepository.FindPersonalData(ctx, 99)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	assert.ErrorIs(t, privacyPgRepository.Erase(ctx, &domain.CustomerErasure{CustomerID: 99, PerformedBy: "A-01"}), gorm.ErrRecordNotFound)
+}
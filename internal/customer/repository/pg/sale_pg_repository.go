@@ -0,0 +1,121 @@
+package pg
+
+import (
+	"context"
+	"github.com/alpakih/point-of-sales/internal/customer"
+	"github.com/alpakih/point-of-sales/internal/domain"
+	"github.com/alpakih/point-of-sales/pkg/database"
+	"github.com/alpakih/point-of-sales/pkg/utils"
+	"gorm.io/gorm"
+)
+
+// notReversed leaves out the earn entries of the sales refunded.
+const notReversed = "NOT EXISTS (SELECT 1 FROM loyalty_entries r WHERE r.related_entry_id = loyalty_entries.id AND r.type = ?)"
+
+type customerSalePgRepository struct {
+	db *gorm.DB
+}
+
+func NewCustomerSalePgRepository(db *gorm.DB) customer.SalePgRepository {
+	return &customerSalePgRepository{db: db}
+}
+
+// FindSales returns the page of the sales of the customer, the latest first,
+// with their lines and reversals. The sales of the customers merged into the
+// customer are included, like in the other queries of the sales.
+func (c customerSalePgRepository) FindSales(ctx context.Context, customerID int, query utils.PaginationQuery) (*database.Paginator, error) {
+	var entities []domain.LoyaltyEntry
+	customerIDs, err := c.customerIDs(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+	db := c.sales(ctx, customerIDs).
+		Preload("Lines", func(db *gorm.DB) *gorm.DB {
+			return db.Order("id")
+		}).
+		Preload("RelatedEntries", "type = ?", domain.LoyaltyEntryReversal)
+
+	paginator := database.NewPaginator(db, query.GetLinkBuilder(), query.GetPage(), query.GetSize(), &entities).
+		OrderBy(database.SortKey{Column: "created_at", Desc: true})
+	if query.IsCursorMode() {
+		paginator.Cursor(query.GetCursor()).CountTotal(query.GetCount())
+	}
+
+	return paginator, paginator.Find(ctx)
+}
+
+// FindSaleSummary returns the number of sales of the customer, its spend and the
+// time of its first and last sale. The sales refunded are left out, a refund
+// reverses the whole sale.
+func (c customerSalePgRepository) FindSaleSummary(ctx context.Context, customerID int) (customer.SaleSummary, error) {
+	var summary customer.SaleSummary
+	customerIDs, err := c.customerIDs(ctx, customerID)
+	if err != nil {
+		return summary, err
+	}
+	err = c.sales(ctx, customerIDs).Model(&domain.LoyaltyEntry{}).
+		Where(notReversed, domain.LoyaltyEntryReversal).
+		Select("COUNT(*) AS visits, COALESCE(SUM(amount), 0) AS spend").
+		Scan(&summary).Error
+	if err != nil || summary.Visits == 0 {
+		return summary, err
+	}
+
+	// the first and last sale are read by the index rather than aggregated, the
+	// drivers don't agree on the type of MIN and MAX of a timestamp
+	first, err := c.visit(ctx, customerIDs, "created_at, id")
+	if err != nil {
+		return summary, err
+	}
+	last, err := c.visit(ctx, customerIDs, "created_at DESC, id DESC")
+	if err != nil {
+		return summary, err
+	}
+	summary.FirstVisit, summary.LastVisit = &first.CreatedAt, &last.CreatedAt
+	return summary, nil
+}
+
+func (c customerSalePgRepository) visit(ctx context.Context, customerIDs []int, order string) (domain.LoyaltyEntry, error) {
+	var entry domain.LoyaltyEntry
+	err := c.sales(ctx, customerIDs).Where(notReversed, domain.LoyaltyEntryReversal).Order(order).Take(&entry).Error
+	return entry, err
+}
+
+// FindFavouriteProducts returns the products the customer bought the most of,
+// by quantity then amount. The sales refunded are left out.
+func (c customerSalePgRepository) FindFavouriteProducts(ctx context.Context, customerID int, limit int) ([]customer.FavouriteProduct, error) {
+	var products []customer.FavouriteProduct
+	customerIDs, err := c.customerIDs(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+	err = database.FromContext(ctx, c.db).Model(&domain.LoyaltyEntryLine{}).
+		Select("loyalty_entry_lines.product, SUM(loyalty_entry_lines.quantity) AS quantity, SUM(loyalty_entry_lines.amount) AS amount").
+		Joins("JOIN loyalty_entries e ON e.id = loyalty_entry_lines.entry_id").
+		Where("e.customer_id IN ? AND e.type = ? AND loyalty_entry_lines.product <> ''", customerIDs, domain.LoyaltyEntryEarn).
+		Where("NOT EXISTS (SELECT 1 FROM loyalty_entries r WHERE r.related_entry_id = e.id AND r.type = ?)", domain.LoyaltyEntryReversal).
+		Group("loyalty_entry_lines.product").
+		Order("quantity DESC, amount DESC, loyalty_entry_lines.product").
+		Limit(limit).
+		Scan(&products).Error
+	return products, err
+}
+
+// customerIDs returns the customer and the customers merged into it, whose sales
+// are sales of the customer.
+func (c customerSalePgRepository) customerIDs(ctx context.Context, customerID int) ([]int, error) {
+	owners, err := findMergeOwners(database.FromContext(ctx, c.db), []int{customerID})
+	if err != nil {
+		return nil, err
+	}
+	return append([]int{customerID}, owners.merged()...), nil
+}
+
+// sales selects the earn entries of the customers recording a sale. A sale is
+// known only once it is sent to earn the points of the customer, the sales of
+// no point are recorded too, as long as their amount isn't zero. The sales
+// refunded are kept, with their reversal.
+func (c customerSalePgRepository) sales(ctx context.Context, customerIDs []int) *gorm.DB {
+	return database.FromContext(ctx, c.db).
+		Where("customer_id IN ? AND type = ? AND amount <> 0", customerIDs, domain.LoyaltyEntryEarn)
+}
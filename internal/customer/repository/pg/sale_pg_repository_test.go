@@ -0,0 +1,114 @@
+package pg
+
+import (
+	"context"
+	"github.com/alpakih/point-of-sales/internal/customer"
+	"github.com/alpakih/point-of-sales/internal/domain"
+	"github.com/alpakih/point-of-sales/pkg/database"
+	"github.com/alpakih/point-of-sales/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestCustomerSalePgRepository_InMemory(t *testing.T) {
+	db, err := database.NewInMemory(&domain.Customer{}, &domain.LoyaltyEntry{}, &domain.LoyaltyEntryLine{}, &domain.CustomerMerge{},
+		&domain.CustomerAddress{}, &domain.CustomerSegment{}, &domain.CustomerSegmentMember{}, &domain.WalletEntry{})
+	assert.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.TODO()
+	pgRepository := NewCustomerPgRepository(db.Conn())
+	loyaltyPgRepository := NewCustomerLoyaltyPgRepository(db.Conn())
+	salePgRepository := NewCustomerSalePgRepository(db.Conn())
+
+	alice := domain.Customer{Name: "Alice", Email: "alice@test.com", MobilePhone: "+6287766777001", Password: "password"}
+	bob := domain.Customer{Name: "Bob", Email: "bob@test.com", MobilePhone: "+6287766777002", Password: "password"}
+	for _, entity := range []*domain.Customer{&alice, &bob} {
+		assert.NoError(t, pgRepository.Create(ctx, entity))
+	}
+
+	summary, err := salePgRepository.FindSaleSummary(ctx, alice.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, customer.SaleSummary{}, summary)
+
+	now := time.Now()
+	entries, err := loyaltyPgRepository.Append(ctx, []domain.LoyaltyEntry{
+		{CustomerID: alice.ID, Type: domain.LoyaltyEntryEarn, Points: 5, Amount: 50000, Reference: "S-1", CreatedAt: now.AddDate(0, -2, 0), Lines: []domain.LoyaltyEntryLine{
+			{Product: "Kopi Susu", Category: "drink", Quantity: 2, Amount: 40000},
+			{Product: "Croissant", Category: "food", Quantity: 1, Amount: 10000},
+		}},
+		{CustomerID: alice.ID, Type: domain.LoyaltyEntryEarn, Points: 3, Amount: 30000, Reference: "S-2", CreatedAt: now.AddDate(0, -1, 0), Lines: []domain.LoyaltyEntryLine{
+			{Product: "Croissant", Category: "food", Quantity: 3, Amount: 30000},
+		}},
+		{CustomerID: alice.ID, Type: domain.LoyaltyEntryEarn, Points: 2, Amount: 20000, Reference: "S-3", CreatedAt: now.AddDate(0, 0, -1), Lines: []domain.LoyaltyEntryLine{
+			{Product: "Kopi Susu", Category: "drink", Quantity: 1, Amount: 20000},
+		}},
+		{CustomerID: alice.ID, Type: domain.LoyaltyEntryRedeem, Points: -2, Reference: "S-3", CreatedAt: now.AddDate(0, 0, -1)},
+		// a sale too small to earn a point is still a sale
+		{CustomerID: alice.ID, Type: domain.LoyaltyEntryEarn, Points: 0, Amount: 5000, Reference: "S-5", CreatedAt: now.AddDate(0, 0, -3)},
+		{CustomerID: bob.ID, Type: domain.LoyaltyEntryEarn, Points: 9, Amount: 90000, Reference: "S-4", CreatedAt: now, Lines: []domain.LoyaltyEntryLine{
+			{Product: "Kopi Susu", Category: "drink", Quantity: 9, Amount: 90000},
+		}},
+	})
+	assert.NoError(t, err)
+	// S-2 is refunded
+	refundedID := entries[1].ID
+	_, err = loyaltyPgRepository.Append(ctx, []domain.LoyaltyEntry{
+		{CustomerID: alice.ID, Type: domain.LoyaltyEntryReversal, Points: -3, Amount: -30000, Reference: "S-2", RelatedEntryID: &refundedID, CreatedAt: now},
+	})
+	assert.NoError(t, err)
+
+	data, err := salePgRepository.FindSales(ctx, alice.ID, utils.PaginationQuery{Page: 1, Size: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), data.Total)
+	sales := *data.Records.(*[]domain.LoyaltyEntry)
+	assert.Len(t, sales, 2)
+	assert.Equal(t, "S-3", sales[0].Reference)
+	assert.Len(t, sales[0].Lines, 1)
+	assert.Equal(t, "S-5", sales[1].Reference)
+
+	// the refunded sale is listed with its refund
+	data, err = salePgRepository.FindSales(ctx, alice.ID, utils.PaginationQuery{Page: 2, Size: 2})
+	assert.NoError(t, err)
+	sales = *data.Records.(*[]domain.LoyaltyEntry)
+	assert.Equal(t, "S-2", sales[0].Reference)
+	assert.Len(t, sales[0].RelatedEntries, 1)
+	assert.Equal(t, int64(30000), customer.NewCustomerMapper().ToSaleResponse(sales[0]).RefundedAmount)
+
+	summary, err = salePgRepository.FindSaleSummary(ctx, alice.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, summary.Visits)
+	assert.Equal(t, int64(75000), summary.Spend)
+	assert.WithinDuration(t, now.AddDate(0, -2, 0), *summary.FirstVisit, time.Second)
+	assert.WithinDuration(t, now.AddDate(0, 0, -1), *summary.LastVisit, time.Second)
+
+	products, err := salePgRepository.FindFavouriteProducts(ctx, alice.ID, 5)
+	assert.NoError(t, err)
+	assert.Equal(t, []customer.FavouriteProduct{
+		{Product: "Kopi Susu", Quantity: 3, Amount: 60000},
+		{Product: "Croissant", Quantity: 1, Amount: 10000},
+	}, products)
+
+	// the sales of bob count as sales of alice once bob is merged into alice
+	assert.NoError(t, pgRepository.Merge(ctx, alice, []domain.Customer{bob}))
+
+	data, err = salePgRepository.FindSales(ctx, alice.ID, utils.PaginationQuery{Page: 1, Size: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), data.Total)
+	assert.Equal(t, "S-4", (*data.Records.(*[]domain.LoyaltyEntry))[0].Reference)
+
+	summary, err = salePgRepository.FindSaleSummary(ctx, alice.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, summary.Visits)
+	assert.Equal(t, int64(165000), summary.Spend)
+	assert.WithinDuration(t, now.AddDate(0, -2, 0), *summary.FirstVisit, time.Second)
+	assert.WithinDuration(t, now, *summary.LastVisit, time.Second)
+
+	products, err = salePgRepository.FindFavouriteProducts(ctx, alice.ID, 5)
+	assert.NoError(t, err)
+	assert.Equal(t, []customer.FavouriteProduct{
+		{Product: "Kopi Susu", Quantity: 12, Amount: 150000},
+		{Product: "Croissant", Quantity: 1, Amount: 10000},
+	}, products)
+}
@@ -0,0 +1,241 @@
+package pg
+
+import (
+	"context"
+	"github.com/alpakih/point-of-sales/internal/constant"
+	"github.com/alpakih/point-of-sales/internal/customer"
+	"github.com/alpakih/point-of-sales/internal/domain"
+	"github.com/alpakih/point-of-sales/pkg/database"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"sort"
+	"time"
+)
+
+// segmentMembersBatchSize number of members inserted at once.
+const segmentMembersBatchSize = 500
+
+type customerSegmentPgRepository struct {
+	db *gorm.DB
+}
+
+func NewCustomerSegmentPgRepository(db *gorm.DB) customer.SegmentPgRepository {
+	return &customerSegmentPgRepository{db: db}
+}
+
+// Create creates the segment, constant.ErrSegmentNameExists is returned when
+// another segment has its name.
+func (c customerSegmentPgRepository) Create(ctx context.Context, entity *domain.CustomerSegment) error {
+	err := database.FromContext(ctx, c.db).Create(entity).Error
+	if _, ok := database.UniqueViolation(err); ok {
+		return constant.ErrSegmentNameExists
+	}
+	return err
+}
+
+// Update writes every field of the segment but its members and evaluation time,
+// constant.ErrSegmentNameExists is returned when another segment has its name.
+func (c customerSegmentPgRepository) Update(ctx context.Context, entity domain.CustomerSegment) error {
+	result := database.FromContext(ctx, c.db).
+		Select("name", "description", "type", "rule", "updated_at").
+		Updates(&entity)
+	if _, ok := database.UniqueViolation(result.Error); ok {
+		return constant.ErrSegmentNameExists
+	}
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (c customerSegmentPgRepository) FindSegmentByID(ctx context.Context, id int) (domain.CustomerSegment, error) {
+	var entity domain.CustomerSegment
+	err := c.withMemberCount(ctx).First(&entity, "id = ?", id).Error
+	return entity, err
+}
+
+// FindSegments returns the segments of the type, every segment when it is
+// empty, ordered by name.
+func (c customerSegmentPgRepository) FindSegments(ctx context.Context, segmentType string) ([]domain.CustomerSegment, error) {
+	var entities []domain.CustomerSegment
+	db := c.withMemberCount(ctx)
+	if segmentType != "" {
+		db = db.Where("type = ?", segmentType)
+	}
+	err := db.Order("name").Find(&entities).Error
+	return entities, err
+}
+
+// withMemberCount selects the segments with their number of active members.
+func (c customerSegmentPgRepository) withMemberCount(ctx context.Context) *gorm.DB {
+	return database.FromContext(ctx, c.db).Model(&domain.CustomerSegment{}).
+		Select("customer_segments.*, (SELECT COUNT(*) FROM customer_segment_members m" +
+			" JOIN customers c ON c.id = m.customer_id AND c.deleted_at IS NULL" +
+			" WHERE m.segment_id = customer_segments.id) AS member_count")
+}
+
+// Delete deletes the segment and its members.
+func (c customerSegmentPgRepository) Delete(ctx context.Context, id int) error {
+	db := database.FromContext(ctx, c.db)
+	if err := db.Delete(&domain.CustomerSegmentMember{}, "segment_id = ?", id).Error; err != nil {
+		return err
+	}
+	result := db.Delete(&domain.CustomerSegment{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// AddMembers adds the customers to the segment, the customers already members
+// are left as is.
+func (c customerSegmentPgRepository) AddMembers(ctx context.Context, segmentID int, customerIDs []int) error {
+	return c.createMembers(database.FromContext(ctx, c.db), segmentID, customerIDs)
+}
+
+// RemoveMember removes the customer from the segment, gorm.ErrRecordNotFound is
+// returned when it isn't a member.
+func (c customerSegmentPgRepository) RemoveMember(ctx context.Context, segmentID, customerID int) error {
+	result := database.FromContext(ctx, c.db).Delete(&domain.CustomerSegmentMember{}, "segment_id = ? AND customer_id = ?", segmentID, customerID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// ReplaceMembers replaces the members of the segment by the customers and sets
+// its evaluation time.
+func (c customerSegmentPgRepository) ReplaceMembers(ctx context.Context, segmentID int, customerIDs []int, evaluatedAt time.Time) error {
+	db := database.FromContext(ctx, c.db)
+	if err := db.Delete(&domain.CustomerSegmentMember{}, "segment_id = ?", segmentID).Error; err != nil {
+		return err
+	}
+	if err := c.createMembers(db, segmentID, customerIDs); err != nil {
+		return err
+	}
+	result := db.Model(&domain.CustomerSegment{}).Where("id = ?", segmentID).UpdateColumn("evaluated_at", evaluatedAt)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (c customerSegmentPgRepository) createMembers(db *gorm.DB, segmentID int, customerIDs []int) error {
+	if len(customerIDs) == 0 {
+		return nil
+	}
+	now := time.Now()
+	var members = make([]domain.CustomerSegmentMember, len(customerIDs))
+	for k, v := range customerIDs {
+		members[k] = domain.CustomerSegmentMember{SegmentID: segmentID, CustomerID: v, CreatedAt: now}
+	}
+	return db.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(&members, segmentMembersBatchSize).Error
+}
+
+// FindSaleStats returns the spend, net of the refunds, and the number of sales
+// of the customers after from, up to to. The sales of the customers merged into
+// a customer count as its own, the customers without sale are left out.
+func (c customerSegmentPgRepository) FindSaleStats(ctx context.Context, customerIDs []int, from, to time.Time) ([]customer.SaleStats, error) {
+	owners, err := findMergeOwners(database.FromContext(ctx, c.db), customerIDs)
+	if err != nil {
+		return nil, err
+	}
+	var rows []customer.SaleStats
+	err = database.FromContext(ctx, c.db).Model(&domain.LoyaltyEntry{}).
+		Select("customer_id, SUM(amount) AS spend, SUM(CASE WHEN type = ? THEN 1 ELSE 0 END) AS visits", domain.LoyaltyEntryEarn).
+		Where("type IN ? AND amount <> 0", []string{domain.LoyaltyEntryEarn, domain.LoyaltyEntryReversal}).
+		Where("customer_id IN ? AND created_at > ? AND created_at <= ?", append(owners.merged(), customerIDs...), from, to).
+		Group("customer_id").
+		Order("customer_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var stats []customer.SaleStats
+	var index = make(map[int]int, len(rows))
+	for _, row := range rows {
+		row.CustomerID = owners.owner(row.CustomerID)
+		if i, ok := index[row.CustomerID]; ok {
+			stats[i].Spend += row.Spend
+			stats[i].Visits += row.Visits
+			continue
+		}
+		index[row.CustomerID] = len(stats)
+		stats = append(stats, row)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].CustomerID < stats[j].CustomerID
+	})
+	return stats, nil
+}
+
+// FindLastVisits returns the latest sale of the customers, the sales of the
+// customers merged into a customer counting as its own. The customers without
+// sale are left out.
+func (c customerSegmentPgRepository) FindLastVisits(ctx context.Context, customerIDs []int) ([]domain.LoyaltyEntry, error) {
+	owners, err := findMergeOwners(database.FromContext(ctx, c.db), customerIDs)
+	if err != nil {
+		return nil, err
+	}
+	var rows []domain.LoyaltyEntry
+	err = database.FromContext(ctx, c.db).
+		Where("type = ? AND customer_id IN ?", domain.LoyaltyEntryEarn, append(owners.merged(), customerIDs...)).
+		Where("NOT EXISTS (SELECT 1 FROM loyalty_entries l WHERE l.customer_id = loyalty_entries.customer_id AND l.type = loyalty_entries.type" +
+			" AND (l.created_at > loyalty_entries.created_at OR (l.created_at = loyalty_entries.created_at AND l.id > loyalty_entries.id)))").
+		Order("customer_id").
+		Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []domain.LoyaltyEntry
+	var index = make(map[int]int, len(rows))
+	for _, row := range rows {
+		row.CustomerID = owners.owner(row.CustomerID)
+		if i, ok := index[row.CustomerID]; ok {
+			if row.CreatedAt.After(entries[i].CreatedAt) || (row.CreatedAt.Equal(entries[i].CreatedAt) && row.ID > entries[i].ID) {
+				entries[i] = row
+			}
+			continue
+		}
+		index[row.CustomerID] = len(entries)
+		entries = append(entries, row)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].CustomerID < entries[j].CustomerID
+	})
+	return entries, nil
+}
+
+// mergeSegments moves the memberships of the merged customers to the survivor,
+// the survivor stays a member of its segments.
+func mergeSegments(db *gorm.DB, survivorID int, ids []int) error {
+	var segmentIDs []int
+	if err := db.Model(&domain.CustomerSegmentMember{}).Distinct("segment_id").Where("customer_id IN ?", ids).Pluck("segment_id", &segmentIDs).Error; err != nil {
+		return err
+	}
+	if len(segmentIDs) == 0 {
+		return nil
+	}
+	var members = make([]domain.CustomerSegmentMember, len(segmentIDs))
+	for k, v := range segmentIDs {
+		members[k] = domain.CustomerSegmentMember{SegmentID: v, CustomerID: survivorID, CreatedAt: time.Now()}
+	}
+	if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&members).Error; err != nil {
+		return err
+	}
+	return db.Delete(&domain.CustomerSegmentMember{}, "customer_id IN ?", ids).Error
+}
@@ -0,0 +1,116 @@
+package pg
+
+import (
+	"context"
+	"github.com/alpakih/point-of-sales/internal/constant"
+	"github.com/alpakih/point-of-sales/internal/customer"
+	"github.com/alpakih/point-of-sales/internal/domain"
+	"github.com/alpakih/point-of-sales/pkg/database"
+	"github.com/alpakih/point-of-sales/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"testing"
+	"time"
+)
+
+func TestCustomerSegmentPgRepository_InMemory(t *testing.T) {
+	db, err := database.NewInMemory(&domain.Customer{}, &domain.LoyaltyEntry{}, &domain.CustomerSegment{}, &domain.CustomerSegmentMember{}, &domain.CustomerMerge{},
+		&domain.CustomerAddress{}, &domain.WalletEntry{})
+	assert.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.TODO()
+	pgRepository := NewCustomerPgRepository(db.Conn())
+	segmentPgRepository := NewCustomerSegmentPgRepository(db.Conn())
+
+	alice := domain.Customer{Name: "Alice", Email: "alice@test.com", MobilePhone: "+6287766777001", Password: "password"}
+	bob := domain.Customer{Name: "Bob", Email: "bob@test.com", MobilePhone: "+6287766777002", Password: "password"}
+	carol := domain.Customer{Name: "Carol", Email: "carol@test.com", MobilePhone: "+6287766777003", Password: "password"}
+	for _, entity := range []*domain.Customer{&alice, &bob, &carol} {
+		assert.NoError(t, pgRepository.Create(ctx, entity))
+	}
+
+	vip := domain.CustomerSegment{Name: "vip", Type: domain.CustomerSegmentStatic}
+	assert.NoError(t, segmentPgRepository.Create(ctx, &vip))
+	err = segmentPgRepository.Create(ctx, &domain.CustomerSegment{Name: "vip", Type: domain.CustomerSegmentStatic})
+	assert.ErrorIs(t, err, constant.ErrSegmentNameExists)
+
+	assert.NoError(t, segmentPgRepository.AddMembers(ctx, vip.ID, []int{alice.ID, carol.ID}))
+	// adding a member twice is a no-op
+	assert.NoError(t, segmentPgRepository.AddMembers(ctx, vip.ID, []int{alice.ID}))
+	assert.NoError(t, pgRepository.Delete(ctx, carol.ID))
+
+	segment, err := segmentPgRepository.FindSegmentByID(ctx, vip.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, segment.MemberCount)
+
+	data, err := pgRepository.FindCustomers(ctx, customer.ListQuery{PaginationQuery: utils.PaginationQuery{Page: 1, Size: 10}, Segment: "vip"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), data.Total)
+	assert.Equal(t, "Alice", (*data.Records.(*[]domain.Customer))[0].Name)
+
+	_, err = pgRepository.FindCustomers(ctx, customer.ListQuery{PaginationQuery: utils.PaginationQuery{Page: 1, Size: 10}, Segment: "unknown"})
+	assert.ErrorIs(t, err, constant.ErrSegmentNotFound)
+
+	assert.NoError(t, segmentPgRepository.RemoveMember(ctx, vip.ID, alice.ID))
+	assert.ErrorIs(t, segmentPgRepository.RemoveMember(ctx, vip.ID, alice.ID), gorm.ErrRecordNotFound)
+
+	regulars := domain.CustomerSegment{Name: "regulars", Type: domain.CustomerSegmentDynamic, Rule: "visits_30d >= 2"}
+	assert.NoError(t, segmentPgRepository.Create(ctx, &regulars))
+	now := time.Now()
+	assert.NoError(t, segmentPgRepository.ReplaceMembers(ctx, regulars.ID, []int{bob.ID}, now))
+	assert.NoError(t, segmentPgRepository.ReplaceMembers(ctx, regulars.ID, []int{alice.ID, bob.ID}, now))
+
+	segments, err := segmentPgRepository.FindSegments(ctx, domain.CustomerSegmentDynamic)
+	assert.NoError(t, err)
+	assert.Len(t, segments, 1)
+	assert.Equal(t, 2, segments[0].MemberCount)
+	assert.NotNil(t, segments[0].EvaluatedAt)
+
+	earnID := 0
+	entries := []domain.LoyaltyEntry{
+		{CustomerID: alice.ID, Type: domain.LoyaltyEntryEarn, Points: 10, Amount: 100000, Reference: "S-1", CreatedAt: now.AddDate(0, 0, -3)},
+		{CustomerID: alice.ID, Type: domain.LoyaltyEntryEarn, Points: 4, Amount: 40000, Reference: "S-2", CreatedAt: now.AddDate(0, 0, -1)},
+		{CustomerID: alice.ID, Type: domain.LoyaltyEntryEarn, Points: 7, Amount: 70000, Reference: "S-3", CreatedAt: now.AddDate(0, -2, 0)},
+		{CustomerID: bob.ID, Type: domain.LoyaltyEntryRedeem, Points: -3, Reference: "S-4", CreatedAt: now.AddDate(0, 0, -1)},
+	}
+	assert.NoError(t, db.Conn().Create(&entries).Error)
+	earnID = entries[1].ID
+	assert.NoError(t, db.Conn().Create(&domain.LoyaltyEntry{
+		CustomerID: alice.ID, Type: domain.LoyaltyEntryReversal, Points: -2, Amount: -20000, Reference: "S-2", RelatedEntryID: &earnID, CreatedAt: now,
+	}).Error)
+
+	stats, err := segmentPgRepository.FindSaleStats(ctx, []int{alice.ID, bob.ID}, now.AddDate(0, 0, -30), now)
+	assert.NoError(t, err)
+	assert.Equal(t, []customer.SaleStats{{CustomerID: alice.ID, Spend: 120000, Visits: 2}}, stats)
+
+	visits, err := segmentPgRepository.FindLastVisits(ctx, []int{alice.ID, bob.ID})
+	assert.NoError(t, err)
+	assert.Len(t, visits, 1)
+	assert.Equal(t, "S-2", visits[0].Reference)
+
+	// the sales of dave count as sales of bob once dave is merged into bob
+	dave := domain.Customer{Name: "Dave", Email: "dave@test.com", MobilePhone: "+6287766777004", Password: "password"}
+	assert.NoError(t, pgRepository.Create(ctx, &dave))
+	assert.NoError(t, db.Conn().Create(&domain.LoyaltyEntry{
+		CustomerID: dave.ID, Type: domain.LoyaltyEntryEarn, Points: 5, Amount: 50000, Reference: "S-5", CreatedAt: now.AddDate(0, 0, -2),
+	}).Error)
+	assert.NoError(t, pgRepository.Merge(ctx, bob, []domain.Customer{dave}))
+	assert.NoError(t, db.Conn().First(&bob, bob.ID).Error)
+
+	stats, err = segmentPgRepository.FindSaleStats(ctx, []int{alice.ID, bob.ID}, now.AddDate(0, 0, -30), now)
+	assert.NoError(t, err)
+	assert.Equal(t, []customer.SaleStats{{CustomerID: alice.ID, Spend: 120000, Visits: 2}, {CustomerID: bob.ID, Spend: 50000, Visits: 1}}, stats)
+
+	visits, err = segmentPgRepository.FindLastVisits(ctx, []int{alice.ID, bob.ID})
+	assert.NoError(t, err)
+	assert.Len(t, visits, 2)
+	assert.Equal(t, bob.ID, visits[1].CustomerID)
+	assert.Equal(t, "S-5", visits[1].Reference)
+
+	assert.NoError(t, segmentPgRepository.Delete(ctx, regulars.ID))
+	assert.ErrorIs(t, segmentPgRepository.Delete(ctx, regulars.ID), gorm.ErrRecordNotFound)
+	var count int64
+	assert.NoError(t, db.Conn().Model(&domain.CustomerSegmentMember{}).Where("segment_id = ?", regulars.ID).Count(&count).Error)
+	assert.Equal(t, int64(0), count)
+}
@@ -0,0 +1,103 @@
+package pg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/alpakih/point-of-sales/internal/constant"
+	"github.com/alpakih/point-of-sales/internal/customer"
+	"github.com/alpakih/point-of-sales/internal/domain"
+	"github.com/alpakih/point-of-sales/pkg/database"
+	"gorm.io/gorm"
+	"time"
+)
+
+// walletReferenceIndex unique index of the references of the top-ups, spends
+// and refunds, a payment or a sale is credited or debited once.
+const walletReferenceIndex = "idx_wallet_entries_type_reference"
+
+type customerWalletPgRepository struct {
+	db *gorm.DB
+}
+
+func NewCustomerWalletPgRepository(db *gorm.DB) customer.WalletPgRepository {
+	return &customerWalletPgRepository{db: db}
+}
+
+// LockCustomer locks the active customer until the end of the transaction, the
+// balance of its wallet is read and appended to one transaction at a time.
+// gorm.ErrRecordNotFound is returned when it doesn't exist.
+func (c customerWalletPgRepository) LockCustomer(ctx context.Context, customerID int) error {
+	return lockCustomer(database.FromContext(ctx, c.db), customerID)
+}
+
+// FindBalance returns the balance of the wallet of the customer, zero when it
+// has no entry.
+func (c customerWalletPgRepository) FindBalance(ctx context.Context, customerID int) (int64, error) {
+	return walletBalance(database.FromContext(ctx, c.db), customerID)
+}
+
+// Append appends the entry to the ledger and sets its id,
+// constant.ErrWalletReferenceExists is returned when a top-up, spend or refund
+// was already recorded for its reference.
+func (c customerWalletPgRepository) Append(ctx context.Context, entry *domain.WalletEntry) error {
+	err := database.FromContext(ctx, c.db).Create(entry).Error
+	if _, ok := database.UniqueViolation(err); ok {
+		return constant.ErrWalletReferenceExists
+	}
+	return err
+}
+
+// FindEntries returns the ledger of the customer in the order it was appended.
+func (c customerWalletPgRepository) FindEntries(ctx context.Context, customerID int) ([]domain.WalletEntry, error) {
+	var entries []domain.WalletEntry
+	err := database.FromContext(ctx, c.db).
+		Where("customer_id = ?", customerID).
+		Order("id").
+		Find(&entries).Error
+	return entries, err
+}
+
+func walletBalance(db *gorm.DB, customerID int) (int64, error) {
+	var entry domain.WalletEntry
+	err := db.Select("balance").Where("customer_id = ?", customerID).Order("id DESC").Take(&entry).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, nil
+	}
+	return entry.Balance, err
+}
+
+// mergeWallet transfers the balances of the wallets of the merged customers to
+// the survivor.
+func mergeWallet(db *gorm.DB, survivorID int, ids []int) error {
+	balance, err := walletBalance(db, survivorID)
+	if err != nil {
+		return err
+	}
+	var now = time.Now()
+	var appended []domain.WalletEntry
+	for _, id := range ids {
+		transferred, err := walletBalance(db, id)
+		if err != nil {
+			return err
+		}
+		if transferred == 0 {
+			continue
+		}
+		balance += transferred
+		appended = append(appended,
+			domain.WalletEntry{
+				CustomerID: id, Type: domain.WalletEntryTransfer, Amount: -transferred, Balance: 0,
+				Reference: fmt.Sprintf("customer:%d", survivorID), CreatedAt: now,
+			},
+			domain.WalletEntry{
+				CustomerID: survivorID, Type: domain.WalletEntryTransfer, Amount: transferred, Balance: balance,
+				Reference: fmt.Sprintf("customer:%d", id), CreatedAt: now,
+			})
+	}
+
+	if len(appended) == 0 {
+		return nil
+	}
+	return db.Create(&appended).Error
+}
@@ -0,0 +1,91 @@
+package pg
+
+import (
+	"context"
+	"github.com/alpakih/point-of-sales/internal/constant"
+	"github.com/alpakih/point-of-sales/internal/domain"
+	"github.com/alpakih/point-of-sales/pkg/database"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestCustomerWalletPgRepository_InMemory(t *testing.T) {
+	db, err := database.NewInMemory(&domain.Customer{}, &domain.WalletEntry{})
+	assert.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.TODO()
+	assert.NoError(t, MigrateIndexes(ctx, db.Conn()))
+	pgRepository := NewCustomerPgRepository(db.Conn())
+	walletPgRepository := NewCustomerWalletPgRepository(db.Conn())
+
+	alice := domain.Customer{Name: "Alice", Email: "alice@test.com", MobilePhone: "+6287766777001", Password: "password"}
+	assert.NoError(t, pgRepository.Create(ctx, &alice))
+
+	balance, err := walletPgRepository.FindBalance(ctx, alice.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), balance)
+
+	topUp := domain.WalletEntry{CustomerID: alice.ID, Type: domain.WalletEntryTopUp, Amount: 100000, Balance: 100000, Reference: "P-1"}
+	assert.NoError(t, walletPgRepository.Append(ctx, &topUp))
+	assert.NotZero(t, topUp.ID)
+	assert.NoError(t, walletPgRepository.Append(ctx, &domain.WalletEntry{CustomerID: alice.ID, Type: domain.WalletEntrySpend, Amount: -30000, Balance: 70000, Reference: "S-1"}))
+	// a sale is refunded once, adjustments may share its reference
+	assert.NoError(t, walletPgRepository.Append(ctx, &domain.WalletEntry{CustomerID: alice.ID, Type: domain.WalletEntryRefund, Amount: 10000, Balance: 80000, Reference: "S-1"}))
+	err = walletPgRepository.Append(ctx, &domain.WalletEntry{CustomerID: alice.ID, Type: domain.WalletEntryRefund, Amount: 10000, Balance: 90000, Reference: "S-1"})
+	assert.ErrorIs(t, err, constant.ErrWalletReferenceExists)
+	assert.NoError(t, walletPgRepository.Append(ctx, &domain.WalletEntry{CustomerID: alice.ID, Type: domain.WalletEntryAdjustment, Amount: 5000, Balance: 85000, Reference: "S-1"}))
+	assert.NoError(t, walletPgRepository.Append(ctx, &domain.WalletEntry{CustomerID: alice.ID, Type: domain.WalletEntryAdjustment, Amount: -5000, Balance: 80000, Reference: "S-1"}))
+
+	err = walletPgRepository.Append(ctx, &domain.WalletEntry{CustomerID: alice.ID, Type: domain.WalletEntrySpend, Amount: -30000, Balance: 55000, Reference: "S-1"})
+	assert.ErrorIs(t, err, constant.ErrWalletReferenceExists)
+	// the balance is never negative
+	err = walletPgRepository.Append(ctx, &domain.WalletEntry{CustomerID: alice.ID, Type: domain.WalletEntrySpend, Amount: -90000, Balance: -5000, Reference: "S-2"})
+	assert.Error(t, err)
+
+	balance, err = walletPgRepository.FindBalance(ctx, alice.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(80000), balance)
+
+	entries, err := walletPgRepository.FindEntries(ctx, alice.ID)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 5)
+	assert.Equal(t, "P-1", entries[0].Reference)
+}
+
+func TestCustomerPgRepository_MergeWalletInMemory(t *testing.T) {
+	db, err := database.NewInMemory(&domain.Customer{}, &domain.CustomerAddress{}, &domain.CustomerMerge{}, &domain.LoyaltyEntry{},
+		&domain.CustomerSegment{}, &domain.CustomerSegmentMember{}, &domain.WalletEntry{})
+	assert.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.TODO()
+	pgRepository := NewCustomerPgRepository(db.Conn())
+	walletPgRepository := NewCustomerWalletPgRepository(db.Conn())
+
+	john := domain.Customer{Name: "John Smith", Email: "john@test.com", MobilePhone: "+6287766777001", Password: "password"}
+	jhon := domain.Customer{Name: "Jhon Smith", Email: "jhon@test.com", MobilePhone: "+6287766777002", Password: "password"}
+	johny := domain.Customer{Name: "Johny Smith", Email: "johny@test.com", MobilePhone: "+6287766777003", Password: "password"}
+	for _, entity := range []*domain.Customer{&john, &jhon, &johny} {
+		assert.NoError(t, pgRepository.Create(ctx, entity))
+	}
+	assert.NoError(t, walletPgRepository.Append(ctx, &domain.WalletEntry{CustomerID: john.ID, Type: domain.WalletEntryTopUp, Amount: 10000, Balance: 10000, Reference: "P-1"}))
+	assert.NoError(t, walletPgRepository.Append(ctx, &domain.WalletEntry{CustomerID: jhon.ID, Type: domain.WalletEntryTopUp, Amount: 25000, Balance: 25000, Reference: "P-2"}))
+
+	assert.NoError(t, pgRepository.Merge(ctx, john, []domain.Customer{jhon, johny}))
+
+	balance, err := walletPgRepository.FindBalance(ctx, john.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(35000), balance)
+
+	entries, err := walletPgRepository.FindEntries(ctx, jhon.ID)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 2)
+	assert.Equal(t, domain.WalletEntryTransfer, entries[1].Type)
+	assert.Equal(t, int64(-25000), entries[1].Amount)
+	assert.Equal(t, int64(0), entries[1].Balance)
+
+	entries, err = walletPgRepository.FindEntries(ctx, johny.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+}
@@ -0,0 +1,16 @@
+package customer
+
+import (
+	"context"
+	"github.com/alpakih/point-of-sales/pkg/database"
+	"github.com/alpakih/point-of-sales/pkg/utils"
+)
+
+// SalePgRepository reads the sales of the customers recorded by the earn
+// entries of their loyalty ledger. There is no sales table, a sale that is
+// never sent to earn points isn't known.
+type SalePgRepository interface {
+	FindSales(ctx context.Context, customerID int, query utils.PaginationQuery) (*database.Paginator, error)
+	FindSaleSummary(ctx context.Context, customerID int) (SaleSummary, error)
+	FindFavouriteProducts(ctx context.Context, customerID int, limit int) ([]FavouriteProduct, error)
+}
@@ -0,0 +1,23 @@
+package customer
+
+import (
+	"context"
+	"github.com/alpakih/point-of-sales/internal/domain"
+	"time"
+)
+
+// SegmentPgRepository stores the customer segments and their members, and reads
+// the sales statistics the dynamic segments are evaluated on from the loyalty
+// ledgers.
+type SegmentPgRepository interface {
+	Create(ctx context.Context, entity *domain.CustomerSegment) error
+	Update(ctx context.Context, entity domain.CustomerSegment) error
+	FindSegmentByID(ctx context.Context, id int) (domain.CustomerSegment, error)
+	FindSegments(ctx context.Context, segmentType string) ([]domain.CustomerSegment, error)
+	Delete(ctx context.Context, id int) error
+	AddMembers(ctx context.Context, segmentID int, customerIDs []int) error
+	RemoveMember(ctx context.Context, segmentID, customerID int) error
+	ReplaceMembers(ctx context.Context, segmentID int, customerIDs []int, evaluatedAt time.Time) error
+	FindSaleStats(ctx context.Context, customerIDs []int, from, to time.Time) ([]SaleStats, error)
+	FindLastVisits(ctx context.Context, customerIDs []int) ([]domain.LoyaltyEntry, error)
+}
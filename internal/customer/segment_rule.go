@@ -0,0 +1,421 @@
+package customer
+
+import (
+	"fmt"
+	"github.com/alpakih/point-of-sales/internal/domain"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Attributes of the customers a segment rule compares, spend_<N>d and
+// visits_<N>d are the spend and number of sales over the last N days.
+const (
+	SegmentAttributeName          = "name"
+	SegmentAttributeEmail         = "email"
+	SegmentAttributeMobilePhone   = "mobile_phone"
+	SegmentAttributeTier          = "tier"
+	SegmentAttributeSignupDays    = "signup_days"
+	SegmentAttributeLastVisitDays = "last_visit_days"
+	segmentAttributeSpend         = "spend_"
+	segmentAttributeVisits        = "visits_"
+)
+
+// maxSegmentWindowDays longest period of spend_<N>d and visits_<N>d.
+const maxSegmentWindowDays = 3650
+
+// SegmentRuleError error of a segment rule at the byte offset Position.
+type SegmentRuleError struct {
+	Position int
+	Reason   string
+}
+
+func (e *SegmentRuleError) Error() string {
+	return fmt.Sprintf("segment rule: %s at %d", e.Reason, e.Position)
+}
+
+// SegmentAttributes attributes of a customer a segment rule is evaluated on.
+type SegmentAttributes struct {
+	Customer domain.Customer
+	Tier     string
+	// Spend and Visits spend and number of sales by period in days
+	Spend  map[int]int64
+	Visits map[int]int
+	// LastVisit time of the latest sale, nil when the customer never bought
+	LastVisit *time.Time
+}
+
+// SegmentRule parsed rule of a dynamic segment, conditions comparing an
+// attribute to a value combined with and, or, not and parentheses:
+//
+//	spend_30d > Rp1jt and not tier = platinum
+//	last_visit_days > 90 or (visits_365d >= 12 and email ~ "@company.co.id")
+//
+// The operators are = != > >= < <= and ~ (contains, case-insensitive). Amounts
+// are written in rupiah, optionally prefixed by Rp and suffixed by rb or jt
+// (thousand, million). A customer who never bought has no last visit, it is
+// infinitely far.
+type SegmentRule struct {
+	root    segmentNode
+	windows []int
+}
+
+type segmentNode interface {
+	match(attributes SegmentAttributes, now time.Time) bool
+}
+
+type segmentAnd struct{ left, right segmentNode }
+
+func (n segmentAnd) match(a SegmentAttributes, now time.Time) bool {
+	return n.left.match(a, now) && n.right.match(a, now)
+}
+
+type segmentOr struct{ left, right segmentNode }
+
+func (n segmentOr) match(a SegmentAttributes, now time.Time) bool {
+	return n.left.match(a, now) || n.right.match(a, now)
+}
+
+type segmentNot struct{ node segmentNode }
+
+func (n segmentNot) match(a SegmentAttributes, now time.Time) bool {
+	return !n.node.match(a, now)
+}
+
+type segmentCondition struct {
+	attribute string
+	window    int
+	operator  string
+	text      string
+	number    float64
+}
+
+func (n segmentCondition) match(a SegmentAttributes, now time.Time) bool {
+	switch n.attribute {
+	case SegmentAttributeName:
+		return compareText(a.Customer.Name, n.operator, n.text)
+	case SegmentAttributeEmail:
+		return compareText(a.Customer.Email, n.operator, n.text)
+	case SegmentAttributeMobilePhone:
+		return compareText(a.Customer.MobilePhone, n.operator, n.text)
+	case SegmentAttributeTier:
+		return compareText(a.Tier, n.operator, n.text)
+	case SegmentAttributeSignupDays:
+		return compareNumber(now.Sub(a.Customer.CreatedAt).Hours()/24, n.operator, n.number)
+	case SegmentAttributeLastVisitDays:
+		if a.LastVisit == nil {
+			return compareNumber(math.Inf(1), n.operator, n.number)
+		}
+		return compareNumber(now.Sub(*a.LastVisit).Hours()/24, n.operator, n.number)
+	case segmentAttributeSpend:
+		return compareNumber(float64(a.Spend[n.window]), n.operator, n.number)
+	case segmentAttributeVisits:
+		return compareNumber(float64(a.Visits[n.window]), n.operator, n.number)
+	}
+	return false
+}
+
+func compareText(value, operator, text string) bool {
+	switch operator {
+	case "=":
+		return strings.EqualFold(value, text)
+	case "!=":
+		return !strings.EqualFold(value, text)
+	case "~":
+		return strings.Contains(strings.ToLower(value), strings.ToLower(text))
+	}
+	return false
+}
+
+func compareNumber(value float64, operator string, number float64) bool {
+	switch operator {
+	case "=":
+		return value == number
+	case "!=":
+		return value != number
+	case ">":
+		return value > number
+	case ">=":
+		return value >= number
+	case "<":
+		return value < number
+	case "<=":
+		return value <= number
+	}
+	return false
+}
+
+// Match reports whether the customer with the attributes is a member of the
+// segment at now.
+func (r SegmentRule) Match(attributes SegmentAttributes, now time.Time) bool {
+	return r.root.match(attributes, now)
+}
+
+// Windows periods in days of the spend and visits the rule compares.
+func (r SegmentRule) Windows() []int {
+	return r.windows
+}
+
+// ParseSegmentRule parses the rule of a dynamic segment, a *SegmentRuleError is
+// returned when it is invalid.
+func ParseSegmentRule(rule string) (SegmentRule, error) {
+	p := &segmentParser{input: rule}
+	if err := p.tokenize(); err != nil {
+		return SegmentRule{}, err
+	}
+	root, err := p.parseOr()
+	if err != nil {
+		return SegmentRule{}, err
+	}
+	if token := p.peek(); token.kind != segmentTokenEnd {
+		return SegmentRule{}, &SegmentRuleError{Position: token.position, Reason: fmt.Sprintf("unexpected %q", token.text)}
+	}
+
+	var windows []int
+	for window := range p.windows {
+		windows = append(windows, window)
+	}
+	sort.Ints(windows)
+	return SegmentRule{root: root, windows: windows}, nil
+}
+
+const (
+	segmentTokenEnd = iota
+	segmentTokenIdent
+	segmentTokenOperator
+	segmentTokenString
+	segmentTokenNumber
+	segmentTokenOpen
+	segmentTokenClose
+)
+
+type segmentToken struct {
+	kind     int
+	text     string
+	position int
+}
+
+type segmentParser struct {
+	input   string
+	tokens  []segmentToken
+	next    int
+	windows map[int]bool
+}
+
+func (p *segmentParser) tokenize() error {
+	runes := []rune(p.input)
+	offset := func(i int) int { return len(string(runes[:i])) }
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(' || r == ')':
+			kind := segmentTokenOpen
+			if r == ')' {
+				kind = segmentTokenClose
+			}
+			p.tokens = append(p.tokens, segmentToken{kind: kind, text: string(r), position: offset(i)})
+			i++
+		case strings.ContainsRune("=!<>~", r):
+			start := i
+			i++
+			if i < len(runes) && runes[i] == '=' && r != '=' && r != '~' {
+				i++
+			}
+			text := string(runes[start:i])
+			if text == "!" {
+				return &SegmentRuleError{Position: offset(start), Reason: `unexpected "!"`}
+			}
+			p.tokens = append(p.tokens, segmentToken{kind: segmentTokenOperator, text: text, position: offset(start)})
+		case r == '"':
+			start := i
+			var text strings.Builder
+			for i++; ; i++ {
+				if i >= len(runes) {
+					return &SegmentRuleError{Position: offset(start), Reason: "unterminated string"}
+				}
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+				} else if runes[i] == '"' {
+					i++
+					break
+				}
+				text.WriteRune(runes[i])
+			}
+			p.tokens = append(p.tokens, segmentToken{kind: segmentTokenString, text: text.String(), position: offset(start)})
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '.' || r == '@' || r == '+' || r == '-':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || strings.ContainsRune("_.@+-", runes[i])) {
+				i++
+			}
+			text := string(runes[start:i])
+			kind := segmentTokenIdent
+			if _, err := parseSegmentNumber(text); err == nil {
+				kind = segmentTokenNumber
+			}
+			p.tokens = append(p.tokens, segmentToken{kind: kind, text: text, position: offset(start)})
+		default:
+			return &SegmentRuleError{Position: offset(i), Reason: fmt.Sprintf("unexpected %q", string(r))}
+		}
+	}
+	p.tokens = append(p.tokens, segmentToken{kind: segmentTokenEnd, position: len(p.input)})
+	p.windows = make(map[int]bool)
+	return nil
+}
+
+func (p *segmentParser) peek() segmentToken {
+	return p.tokens[p.next]
+}
+
+func (p *segmentParser) keyword(keyword string) bool {
+	token := p.peek()
+	if token.kind == segmentTokenIdent && strings.EqualFold(token.text, keyword) {
+		p.next++
+		return true
+	}
+	return false
+}
+
+func (p *segmentParser) parseOr() (segmentNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("or") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = segmentOr{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *segmentParser) parseAnd() (segmentNode, error) {
+	left, err := p.parseFactor()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("and") {
+		right, err := p.parseFactor()
+		if err != nil {
+			return nil, err
+		}
+		left = segmentAnd{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *segmentParser) parseFactor() (segmentNode, error) {
+	if p.keyword("not") {
+		node, err := p.parseFactor()
+		if err != nil {
+			return nil, err
+		}
+		return segmentNot{node: node}, nil
+	}
+	if token := p.peek(); token.kind == segmentTokenOpen {
+		p.next++
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.peek(); closing.kind != segmentTokenClose {
+			return nil, &SegmentRuleError{Position: closing.position, Reason: `missing ")"`}
+		}
+		p.next++
+		return node, nil
+	}
+	return p.parseCondition()
+}
+
+func (p *segmentParser) parseCondition() (segmentNode, error) {
+	token := p.peek()
+	if token.kind != segmentTokenIdent {
+		return nil, &SegmentRuleError{Position: token.position, Reason: "attribute expected"}
+	}
+	p.next++
+
+	var condition = segmentCondition{attribute: strings.ToLower(token.text)}
+	numeric := true
+	switch condition.attribute {
+	case SegmentAttributeName, SegmentAttributeEmail, SegmentAttributeMobilePhone, SegmentAttributeTier:
+		numeric = false
+	case SegmentAttributeSignupDays, SegmentAttributeLastVisitDays:
+	default:
+		window, attribute, ok := parseSegmentWindow(condition.attribute)
+		if !ok {
+			return nil, &SegmentRuleError{Position: token.position, Reason: fmt.Sprintf("unknown attribute %q", token.text)}
+		}
+		condition.attribute, condition.window = attribute, window
+		p.windows[window] = true
+	}
+
+	operator := p.peek()
+	if operator.kind != segmentTokenOperator {
+		return nil, &SegmentRuleError{Position: operator.position, Reason: "operator expected"}
+	}
+	p.next++
+	condition.operator = operator.text
+	textOperator := operator.text == "=" || operator.text == "!=" || operator.text == "~"
+	if numeric && operator.text == "~" || !numeric && !textOperator {
+		return nil, &SegmentRuleError{Position: operator.position, Reason: fmt.Sprintf("operator %q not allowed on %s", operator.text, token.text)}
+	}
+
+	value := p.peek()
+	if value.kind != segmentTokenIdent && value.kind != segmentTokenNumber && value.kind != segmentTokenString {
+		return nil, &SegmentRuleError{Position: value.position, Reason: "value expected"}
+	}
+	p.next++
+	if !numeric {
+		condition.text = value.text
+		return condition, nil
+	}
+	number, err := parseSegmentNumber(value.text)
+	if err != nil || value.kind == segmentTokenString {
+		return nil, &SegmentRuleError{Position: value.position, Reason: fmt.Sprintf("number expected for %s", token.text)}
+	}
+	condition.number = number
+	return condition, nil
+}
+
+// parseSegmentWindow parses spend_<N>d and visits_<N>d.
+func parseSegmentWindow(attribute string) (int, string, bool) {
+	for _, prefix := range []string{segmentAttributeSpend, segmentAttributeVisits} {
+		if !strings.HasPrefix(attribute, prefix) || !strings.HasSuffix(attribute, "d") {
+			continue
+		}
+		window, err := strconv.Atoi(attribute[len(prefix) : len(attribute)-1])
+		if err != nil || window < 1 || window > maxSegmentWindowDays {
+			return 0, "", false
+		}
+		return window, prefix, true
+	}
+	return 0, "", false
+}
+
+// parseSegmentNumber parses a number optionally prefixed by Rp and suffixed by
+// rb or jt.
+func parseSegmentNumber(text string) (float64, error) {
+	lower := strings.ToLower(text)
+	lower = strings.TrimPrefix(lower, "rp")
+	multiplier := 1.0
+	switch {
+	case strings.HasSuffix(lower, "rb"):
+		lower, multiplier = strings.TrimSuffix(lower, "rb"), 1e3
+	case strings.HasSuffix(lower, "jt"):
+		lower, multiplier = strings.TrimSuffix(lower, "jt"), 1e6
+	}
+	if lower == "" || !unicode.IsDigit(rune(lower[0])) {
+		return 0, strconv.ErrSyntax
+	}
+	number, err := strconv.ParseFloat(lower, 64)
+	if err != nil {
+		return 0, err
+	}
+	return number * multiplier, nil
+}
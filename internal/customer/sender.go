@@ -0,0 +1,10 @@
+package customer
+
+import "context"
+
+// Sender sends a marketing message, a notification or a campaign, to customers
+// on a consent channel. The senders are wrapped by usecase.NewConsentSender, the
+// customers who didn't consent to marketing on the channel are left out.
+type Sender interface {
+	Send(ctx context.Context, channel string, customerIDs []int, message string) error
+}
@@ -2,12 +2,94 @@ package customer
 
 import (
 	"context"
+	"github.com/alpakih/point-of-sales/pkg/utils"
+	"time"
 )
 
 type UseCase interface {
 	StoreCustomer(ctx context.Context, request StoreRequest) (*Response, error)
-	UpdateCustomer(ctx context.Context, entity UpdateRequest, id int) error
-	GetCustomerByID(ctx context.Context, id int) (*Response, error)
+	UpdateCustomer(ctx context.Context, entity UpdateRequest, id, version int) (*Response, error)
+	PatchCustomer(ctx context.Context, patch []byte, id, version int) (*Response, error)
+	GetCustomerByID(ctx context.Context, id int, fieldset utils.FieldsetQuery) (*Response, error)
 	DeleteCustomer(ctx context.Context, id int) error
-	GetCustomers(ctx context.Context, page, size int, search, order string) (*PaginationResponse, error)
+	RestoreCustomer(ctx context.Context, id int) (*Response, error)
+	PurgeCustomer(ctx context.Context, id int) error
+	GetCustomers(ctx context.Context, query ListQuery) (*PaginationResponse, error)
+	ExportCustomers(ctx context.Context, query ListQuery, fn func([]Response) error) error
+	FindDuplicateCandidates(ctx context.Context, id int) ([]DuplicateCandidate, error)
+	MergeCustomers(ctx context.Context, request MergeRequest) (*Response, error)
+}
+
+// ImportUseCase imports customers in bulk in the background.
+type ImportUseCase interface {
+	ImportCustomers(ctx context.Context, rows []ImportRow, dryRun bool) (*ImportJob, error)
+	GetImportJob(ctx context.Context, id string) (*ImportJob, error)
+}
+
+// AddressUseCase manages the addresses of the customers.
+type AddressUseCase interface {
+	StoreAddress(ctx context.Context, customerID int, request AddressRequest) (*AddressResponse, error)
+	UpdateAddress(ctx context.Context, customerID, id int, request AddressRequest) (*AddressResponse, error)
+	GetAddressByID(ctx context.Context, customerID, id int) (*AddressResponse, error)
+	GetAddresses(ctx context.Context, customerID int) ([]AddressResponse, error)
+	DeleteAddress(ctx context.Context, customerID, id int) error
+}
+
+// LoyaltyUseCase earns, redeems and reverses the loyalty points of the customers.
+type LoyaltyUseCase interface {
+	GetLoyalty(ctx context.Context, customerID int) (*LoyaltyResponse, error)
+	EarnPoints(ctx context.Context, customerID int, request EarnRequest) (*LoyaltyEntryResponse, error)
+	RedeemPoints(ctx context.Context, customerID int, request RedeemRequest) (*RedemptionResponse, error)
+	ReversePoints(ctx context.Context, customerID int, request ReversalRequest) ([]LoyaltyEntryResponse, error)
+	ExpirePoints(ctx context.Context, from, to time.Time) (int, error)
+}
+
+// MembershipUseCase evaluates the membership tiers of the customers.
+type MembershipUseCase interface {
+	GetMembership(ctx context.Context, customerID int) (*MembershipResponse, error)
+	EvaluateTiers(ctx context.Context, now time.Time) (int, error)
+}
+
+// WalletUseCase manages the store credit of the customers, the balance of a
+// wallet never goes negative.
+type WalletUseCase interface {
+	GetWallet(ctx context.Context, customerID int) (*WalletResponse, error)
+	TopUp(ctx context.Context, customerID int, request WalletRequest) (*WalletEntryResponse, error)
+	Spend(ctx context.Context, customerID int, request WalletRequest) (*WalletEntryResponse, error)
+	Refund(ctx context.Context, customerID int, request WalletRequest) (*WalletEntryResponse, error)
+	Adjust(ctx context.Context, customerID int, request WalletAdjustmentRequest) (*WalletEntryResponse, error)
+}
+
+// PrivacyUseCase honours the requests of the customers on their personal data.
+type PrivacyUseCase interface {
+	ExportPersonalData(ctx context.Context, customerID int) (*DataExport, error)
+	ErasePersonalData(ctx context.Context, customerID int, request ErasureRequest) (*ErasureResponse, error)
+}
+
+// ConsentUseCase manages the marketing consents of the customers, a sender of
+// notifications or campaigns, wrapped by usecase.NewConsentSender, keeps only
+// the customers FilterConsented returns for its channel.
+type ConsentUseCase interface {
+	GetConsents(ctx context.Context, customerID int) (*ConsentsResponse, error)
+	UpdateConsents(ctx context.Context, customerID int, request ConsentRequest) (*ConsentsResponse, error)
+	FilterConsented(ctx context.Context, channel string, customerIDs []int) ([]int, error)
+}
+
+// SaleUseCase reads the purchase history of the customers.
+type SaleUseCase interface {
+	GetSales(ctx context.Context, customerID int, query utils.PaginationQuery) (*SalePaginationResponse, error)
+	GetSummary(ctx context.Context, customerID int) (*SummaryResponse, error)
+}
+
+// SegmentUseCase manages the customer segments and evaluates the dynamic ones.
+type SegmentUseCase interface {
+	StoreSegment(ctx context.Context, request SegmentRequest) (*SegmentResponse, error)
+	UpdateSegment(ctx context.Context, id int, request SegmentRequest) (*SegmentResponse, error)
+	GetSegmentByID(ctx context.Context, id int) (*SegmentResponse, error)
+	GetSegments(ctx context.Context) ([]SegmentResponse, error)
+	DeleteSegment(ctx context.Context, id int) error
+	AddSegmentMembers(ctx context.Context, id int, request SegmentMembersRequest) (*SegmentResponse, error)
+	RemoveSegmentMember(ctx context.Context, id, customerID int) error
+	EvaluateSegment(ctx context.Context, id int) (*SegmentResponse, error)
+	EvaluateSegments(ctx context.Context) (int, error)
 }
@@ -0,0 +1,138 @@
+package usecase
+
+import (
+	"context"
+	"github.com/alpakih/point-of-sales/internal/constant"
+	"github.com/alpakih/point-of-sales/internal/customer"
+	"github.com/alpakih/point-of-sales/pkg/database"
+)
+
+type customerAddressUseCase struct {
+	pgRepository        customer.PgRepository
+	addressPgRepository customer.AddressPgRepository
+	txManager           database.TxManager
+}
+
+func NewCustomerAddressUseCase(pgRepository customer.PgRepository, addressPgRepository customer.AddressPgRepository, txManager database.TxManager) customer.AddressUseCase {
+	return &customerAddressUseCase{
+		pgRepository:        pgRepository,
+		addressPgRepository: addressPgRepository,
+		txManager:           txManager,
+	}
+}
+
+// StoreAddress adds an address to the customer, the first address of a customer
+// is its default address.
+func (c customerAddressUseCase) StoreAddress(ctx context.Context, customerID int, request customer.AddressRequest) (*customer.AddressResponse, error) {
+	mapper := customer.NewCustomerMapper()
+	var entity = mapper.AddressRequestToEntity(request, customerID, 0)
+
+	err := c.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := c.addressPgRepository.LockCustomer(ctx, customerID); err != nil {
+			return err
+		}
+
+		addresses, err := c.addressPgRepository.FindAddresses(ctx, customerID)
+		if err != nil {
+			return err
+		}
+		if len(addresses) == 0 {
+			entity.IsDefault = true
+		} else if entity.IsDefault {
+			if err := c.addressPgRepository.ClearDefault(ctx, customerID); err != nil {
+				return err
+			}
+		}
+
+		return c.addressPgRepository.Create(ctx, &entity)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := mapper.ToAddressResponse(entity)
+	return &result, nil
+}
+
+// UpdateAddress replaces an address of the customer. The default address can
+// only be replaced by making another address the default,
+// constant.ErrDefaultAddressRequired is returned when it is unset.
+func (c customerAddressUseCase) UpdateAddress(ctx context.Context, customerID, id int, request customer.AddressRequest) (*customer.AddressResponse, error) {
+	mapper := customer.NewCustomerMapper()
+	var entity = mapper.AddressRequestToEntity(request, customerID, id)
+
+	err := c.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := c.addressPgRepository.LockCustomer(ctx, customerID); err != nil {
+			return err
+		}
+
+		current, err := c.addressPgRepository.FindAddressByID(ctx, customerID, id)
+		if err != nil {
+			return err
+		}
+		if current.IsDefault && !entity.IsDefault {
+			return constant.ErrDefaultAddressRequired
+		}
+		if entity.IsDefault && !current.IsDefault {
+			if err := c.addressPgRepository.ClearDefault(ctx, customerID); err != nil {
+				return err
+			}
+		}
+
+		return c.addressPgRepository.Update(ctx, entity)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := mapper.ToAddressResponse(entity)
+	return &result, nil
+}
+
+func (c customerAddressUseCase) GetAddressByID(ctx context.Context, customerID, id int) (*customer.AddressResponse, error) {
+	data, err := c.addressPgRepository.FindAddressByID(ctx, customerID, id)
+	if err != nil {
+		return nil, err
+	}
+	result := customer.NewCustomerMapper().ToAddressResponse(data)
+	return &result, nil
+}
+
+func (c customerAddressUseCase) GetAddresses(ctx context.Context, customerID int) ([]customer.AddressResponse, error) {
+	if _, err := c.pgRepository.FindOneCustomerByID(ctx, customerID); err != nil {
+		return nil, err
+	}
+	data, err := c.addressPgRepository.FindAddresses(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+	return customer.NewCustomerMapper().ToAddressResponses(data), nil
+}
+
+// DeleteAddress deletes an address of the customer, the oldest remaining address
+// becomes the default when the default address is deleted.
+func (c customerAddressUseCase) DeleteAddress(ctx context.Context, customerID, id int) error {
+	return c.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := c.addressPgRepository.LockCustomer(ctx, customerID); err != nil {
+			return err
+		}
+
+		current, err := c.addressPgRepository.FindAddressByID(ctx, customerID, id)
+		if err != nil {
+			return err
+		}
+		if err := c.addressPgRepository.Delete(ctx, customerID, id); err != nil {
+			return err
+		}
+		if !current.IsDefault {
+			return nil
+		}
+
+		addresses, err := c.addressPgRepository.FindAddresses(ctx, customerID)
+		if err != nil || len(addresses) == 0 {
+			return err
+		}
+		addresses[0].IsDefault = true
+		return c.addressPgRepository.Update(ctx, addresses[0])
+	})
+}
@@ -0,0 +1,189 @@
+package usecase
+
+import (
+	"context"
+	"github.com/alpakih/point-of-sales/internal/constant"
+	"github.com/alpakih/point-of-sales/internal/customer"
+	"github.com/alpakih/point-of-sales/internal/customer/mocks"
+	"github.com/alpakih/point-of-sales/internal/domain"
+	dbMocks "github.com/alpakih/point-of-sales/pkg/database/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+	"testing"
+)
+
+func TestCustomerAddressUseCase_StoreAddress(t *testing.T) {
+	mockCustomerRepository := new(mocks.PgRepository)
+	mockAddressRepository := new(mocks.AddressPgRepository)
+	mockTxManager := new(dbMocks.TxManager)
+	mockTxManager.On("WithinTransaction", mock.Anything, mock.Anything).Return(func(ctx context.Context, fn func(context.Context) error) error {
+		return fn(ctx)
+	})
+	request := customer.AddressRequest{
+		Label:      "Home",
+		Recipient:  "Alice",
+		Phone:      "0877-6677-7001",
+		Street:     "Jl. Merdeka 1",
+		Village:    "Babakan",
+		District:   "Bandung Wetan",
+		City:       "Bandung",
+		Province:   "Jawa Barat",
+		PostalCode: "40115",
+	}
+
+	t.Run("first-address", func(t *testing.T) {
+		mockAddressRepository.On("LockCustomer", mock.Anything, 1).Return(nil).Once()
+		mockAddressRepository.On("FindAddresses", mock.Anything, 1).Return([]domain.CustomerAddress{}, nil).Once()
+		mockAddressRepository.On("Create", mock.Anything, mock.MatchedBy(func(entity *domain.CustomerAddress) bool {
+			return entity.CustomerID == 1 && entity.IsDefault && entity.Phone == "+6287766777001"
+		})).Return(nil).Once()
+
+		u := NewCustomerAddressUseCase(mockCustomerRepository, mockAddressRepository, mockTxManager)
+
+		data, err := u.StoreAddress(context.TODO(), 1, request)
+
+		assert.NoError(t, err)
+		assert.True(t, data.IsDefault)
+		mockAddressRepository.AssertExpectations(t)
+	})
+
+	t.Run("new-default", func(t *testing.T) {
+		tempRequest := request
+		tempRequest.IsDefault = true
+
+		mockAddressRepository.On("LockCustomer", mock.Anything, 1).Return(nil).Once()
+		mockAddressRepository.On("FindAddresses", mock.Anything, 1).Return([]domain.CustomerAddress{{ID: 1, CustomerID: 1, IsDefault: true}}, nil).Once()
+		mockAddressRepository.On("ClearDefault", mock.Anything, 1).Return(nil).Once()
+		mockAddressRepository.On("Create", mock.Anything, mock.AnythingOfType("*domain.CustomerAddress")).Return(nil).Once()
+
+		u := NewCustomerAddressUseCase(mockCustomerRepository, mockAddressRepository, mockTxManager)
+
+		data, err := u.StoreAddress(context.TODO(), 1, tempRequest)
+
+		assert.NoError(t, err)
+		assert.True(t, data.IsDefault)
+		mockAddressRepository.AssertExpectations(t)
+	})
+
+	t.Run("customer-not-found", func(t *testing.T) {
+		mockAddressRepository.On("LockCustomer", mock.Anything, 2).Return(gorm.ErrRecordNotFound).Once()
+
+		u := NewCustomerAddressUseCase(mockCustomerRepository, mockAddressRepository, mockTxManager)
+
+		_, err := u.StoreAddress(context.TODO(), 2, request)
+
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+		mockAddressRepository.AssertExpectations(t)
+	})
+}
+
+func TestCustomerAddressUseCase_UpdateAddress(t *testing.T) {
+	mockCustomerRepository := new(mocks.PgRepository)
+	mockAddressRepository := new(mocks.AddressPgRepository)
+	mockTxManager := new(dbMocks.TxManager)
+	mockTxManager.On("WithinTransaction", mock.Anything, mock.Anything).Return(func(ctx context.Context, fn func(context.Context) error) error {
+		return fn(ctx)
+	})
+	request := customer.AddressRequest{Label: "Office", City: "Jakarta"}
+
+	t.Run("make-default", func(t *testing.T) {
+		tempRequest := request
+		tempRequest.IsDefault = true
+
+		mockAddressRepository.On("LockCustomer", mock.Anything, 1).Return(nil).Once()
+		mockAddressRepository.On("FindAddressByID", mock.Anything, 1, 2).Return(domain.CustomerAddress{ID: 2, CustomerID: 1}, nil).Once()
+		mockAddressRepository.On("ClearDefault", mock.Anything, 1).Return(nil).Once()
+		mockAddressRepository.On("Update", mock.Anything, mock.MatchedBy(func(entity domain.CustomerAddress) bool {
+			return entity.ID == 2 && entity.CustomerID == 1 && entity.IsDefault && entity.City == "Jakarta"
+		})).Return(nil).Once()
+
+		u := NewCustomerAddressUseCase(mockCustomerRepository, mockAddressRepository, mockTxManager)
+
+		data, err := u.UpdateAddress(context.TODO(), 1, 2, tempRequest)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2, data.ID)
+		mockAddressRepository.AssertExpectations(t)
+	})
+
+	t.Run("unset-default", func(t *testing.T) {
+		mockAddressRepository.On("LockCustomer", mock.Anything, 1).Return(nil).Once()
+		mockAddressRepository.On("FindAddressByID", mock.Anything, 1, 1).Return(domain.CustomerAddress{ID: 1, CustomerID: 1, IsDefault: true}, nil).Once()
+
+		u := NewCustomerAddressUseCase(mockCustomerRepository, mockAddressRepository, mockTxManager)
+
+		_, err := u.UpdateAddress(context.TODO(), 1, 1, request)
+
+		assert.ErrorIs(t, err, constant.ErrDefaultAddressRequired)
+		mockAddressRepository.AssertExpectations(t)
+	})
+}
+
+func TestCustomerAddressUseCase_DeleteAddress(t *testing.T) {
+	mockCustomerRepository := new(mocks.PgRepository)
+	mockAddressRepository := new(mocks.AddressPgRepository)
+	mockTxManager := new(dbMocks.TxManager)
+	mockTxManager.On("WithinTransaction", mock.Anything, mock.Anything).Return(func(ctx context.Context, fn func(context.Context) error) error {
+		return fn(ctx)
+	})
+
+	t.Run("default-address", func(t *testing.T) {
+		mockAddressRepository.On("LockCustomer", mock.Anything, 1).Return(nil).Once()
+		mockAddressRepository.On("FindAddressByID", mock.Anything, 1, 1).Return(domain.CustomerAddress{ID: 1, CustomerID: 1, IsDefault: true}, nil).Once()
+		mockAddressRepository.On("Delete", mock.Anything, 1, 1).Return(nil).Once()
+		mockAddressRepository.On("FindAddresses", mock.Anything, 1).Return([]domain.CustomerAddress{{ID: 2, CustomerID: 1}, {ID: 3, CustomerID: 1}}, nil).Once()
+		mockAddressRepository.On("Update", mock.Anything, domain.CustomerAddress{ID: 2, CustomerID: 1, IsDefault: true}).Return(nil).Once()
+
+		u := NewCustomerAddressUseCase(mockCustomerRepository, mockAddressRepository, mockTxManager)
+
+		err := u.DeleteAddress(context.TODO(), 1, 1)
+
+		assert.NoError(t, err)
+		mockAddressRepository.AssertExpectations(t)
+	})
+
+	t.Run("other-address", func(t *testing.T) {
+		mockAddressRepository.On("LockCustomer", mock.Anything, 1).Return(nil).Once()
+		mockAddressRepository.On("FindAddressByID", mock.Anything, 1, 3).Return(domain.CustomerAddress{ID: 3, CustomerID: 1}, nil).Once()
+		mockAddressRepository.On("Delete", mock.Anything, 1, 3).Return(nil).Once()
+
+		u := NewCustomerAddressUseCase(mockCustomerRepository, mockAddressRepository, mockTxManager)
+
+		err := u.DeleteAddress(context.TODO(), 1, 3)
+
+		assert.NoError(t, err)
+		mockAddressRepository.AssertExpectations(t)
+	})
+}
+
+func TestCustomerAddressUseCase_GetAddresses(t *testing.T) {
+	mockCustomerRepository := new(mocks.PgRepository)
+	mockAddressRepository := new(mocks.AddressPgRepository)
+	mockTxManager := new(dbMocks.TxManager)
+
+	t.Run("success", func(t *testing.T) {
+		mockCustomerRepository.On("FindOneCustomerByID", mock.Anything, 1).Return(domain.Customer{ID: 1}, nil).Once()
+		mockAddressRepository.On("FindAddresses", mock.Anything, 1).Return([]domain.CustomerAddress{{ID: 2, IsDefault: true}, {ID: 1}}, nil).Once()
+
+		u := NewCustomerAddressUseCase(mockCustomerRepository, mockAddressRepository, mockTxManager)
+
+		data, err := u.GetAddresses(context.TODO(), 1)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []customer.AddressResponse{{ID: 2, IsDefault: true}, {ID: 1}}, data)
+		mockCustomerRepository.AssertExpectations(t)
+		mockAddressRepository.AssertExpectations(t)
+	})
+
+	t.Run("customer-not-found", func(t *testing.T) {
+		mockCustomerRepository.On("FindOneCustomerByID", mock.Anything, 2).Return(domain.Customer{}, gorm.ErrRecordNotFound).Once()
+
+		u := NewCustomerAddressUseCase(mockCustomerRepository, mockAddressRepository, mockTxManager)
+
+		_, err := u.GetAddresses(context.TODO(), 2)
+
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+		mockCustomerRepository.AssertExpectations(t)
+	})
+}
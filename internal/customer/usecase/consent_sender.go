@@ -0,0 +1,35 @@
+package usecase
+
+import (
+	"context"
+	"github.com/alpakih/point-of-sales/internal/customer"
+)
+
+type consentSender struct {
+	consentUseCase customer.ConsentUseCase
+	sender         customer.Sender
+}
+
+// NewConsentSender wraps sender so that it only sends to the active customers
+// who consented to marketing on the channel.
+func NewConsentSender(consentUseCase customer.ConsentUseCase, sender customer.Sender) customer.Sender {
+	return &consentSender{
+		consentUseCase: consentUseCase,
+		sender:         sender,
+	}
+}
+
+// Send sends the message to the customers, among customerIDs, who consented to
+// marketing on the channel, nothing is sent when none did.
+// constant.ErrInvalidConsentChannel is returned when the channel isn't a consent
+// channel.
+func (c consentSender) Send(ctx context.Context, channel string, customerIDs []int, message string) error {
+	consented, err := c.consentUseCase.FilterConsented(ctx, channel, customerIDs)
+	if err != nil {
+		return err
+	}
+	if len(consented) == 0 {
+		return nil
+	}
+	return c.sender.Send(ctx, channel, consented, message)
+}
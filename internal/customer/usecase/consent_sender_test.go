@@ -0,0 +1,41 @@
+package usecase
+
+import (
+	"context"
+	"github.com/alpakih/point-of-sales/internal/constant"
+	"github.com/alpakih/point-of-sales/internal/customer/mocks"
+	"github.com/alpakih/point-of-sales/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+)
+
+func TestConsentSender_Send(t *testing.T) {
+	mockConsentUseCase := new(mocks.ConsentUseCase)
+	mockSender := new(mocks.Sender)
+	sender := NewConsentSender(mockConsentUseCase, mockSender)
+
+	t.Run("consented", func(t *testing.T) {
+		mockConsentUseCase.On("FilterConsented", mock.Anything, domain.ConsentChannelSMS, []int{1, 2, 3}).Return([]int{1, 3}, nil).Once()
+		mockSender.On("Send", mock.Anything, domain.ConsentChannelSMS, []int{1, 3}, "promo").Return(nil).Once()
+
+		assert.NoError(t, sender.Send(context.TODO(), domain.ConsentChannelSMS, []int{1, 2, 3}, "promo"))
+		mockConsentUseCase.AssertExpectations(t)
+		mockSender.AssertExpectations(t)
+	})
+
+	t.Run("none-consented", func(t *testing.T) {
+		mockConsentUseCase.On("FilterConsented", mock.Anything, domain.ConsentChannelEmail, []int{2}).Return([]int{}, nil).Once()
+
+		assert.NoError(t, sender.Send(context.TODO(), domain.ConsentChannelEmail, []int{2}, "promo"))
+		mockSender.AssertNumberOfCalls(t, "Send", 1)
+	})
+
+	t.Run("invalid-channel", func(t *testing.T) {
+		mockConsentUseCase.On("FilterConsented", mock.Anything, "fax", []int{1}).Return(nil, constant.ErrInvalidConsentChannel).Once()
+
+		err := sender.Send(context.TODO(), "fax", []int{1}, "promo")
+		assert.ErrorIs(t, err, constant.ErrInvalidConsentChannel)
+		mockSender.AssertNumberOfCalls(t, "Send", 1)
+	})
+}
@@ -0,0 +1,107 @@
+package usecase
+
+import (
+	"context"
+	"github.com/alpakih/point-of-sales/internal/constant"
+	"github.com/alpakih/point-of-sales/internal/customer"
+	"github.com/alpakih/point-of-sales/internal/domain"
+	"github.com/alpakih/point-of-sales/pkg/database"
+	"time"
+)
+
+type customerConsentUseCase struct {
+	pgRepository        customer.PgRepository
+	consentPgRepository customer.ConsentPgRepository
+	txManager           database.TxManager
+}
+
+func NewCustomerConsentUseCase(pgRepository customer.PgRepository, consentPgRepository customer.ConsentPgRepository,
+	txManager database.TxManager) customer.ConsentUseCase {
+	return &customerConsentUseCase{
+		pgRepository:        pgRepository,
+		consentPgRepository: consentPgRepository,
+		txManager:           txManager,
+	}
+}
+
+// GetConsents returns the consent of the customer on every channel and its
+// changes, the latest first.
+func (c customerConsentUseCase) GetConsents(ctx context.Context, customerID int) (*customer.ConsentsResponse, error) {
+	if _, err := c.pgRepository.FindOneCustomerByID(ctx, customerID); err != nil {
+		return nil, err
+	}
+
+	consents, err := c.consentPgRepository.FindConsents(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+	result := customer.NewCustomerMapper().ToConsentsResponse(consents)
+	return &result, nil
+}
+
+// UpdateConsents grants or withdraws the consents of the customer, only the
+// consents that change are recorded so that the history keeps when and where
+// each of them was given or withdrawn.
+func (c customerConsentUseCase) UpdateConsents(ctx context.Context, customerID int, request customer.ConsentRequest) (*customer.ConsentsResponse, error) {
+	var consents []domain.CustomerConsent
+
+	err := c.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := c.consentPgRepository.LockCustomer(ctx, customerID); err != nil {
+			return err
+		}
+		var err error
+		if consents, err = c.consentPgRepository.FindConsents(ctx, customerID); err != nil {
+			return err
+		}
+
+		// a channel never consented to is withdrawn
+		var granted = make(map[string]bool)
+		for _, v := range consents {
+			granted[v.Channel] = v.Granted
+		}
+		// a channel listed more than once is changed once, to its last value
+		var requested = make(map[string]bool)
+		var channels []string
+		for _, v := range request.Consents {
+			if _, ok := requested[v.Channel]; !ok {
+				channels = append(channels, v.Channel)
+			}
+			requested[v.Channel] = *v.Granted
+		}
+		var now = time.Now()
+		var changes []domain.CustomerConsent
+		for _, channel := range channels {
+			if granted[channel] == requested[channel] {
+				continue
+			}
+			changes = append(changes, domain.CustomerConsent{
+				CustomerID: customerID,
+				Channel:    channel,
+				Granted:    requested[channel],
+				Source:     request.Source,
+				CreatedAt:  now,
+			})
+		}
+		if err := c.consentPgRepository.Append(ctx, changes); err != nil {
+			return err
+		}
+		consents = append(consents, changes...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := customer.NewCustomerMapper().ToConsentsResponse(consents)
+	return &result, nil
+}
+
+// FilterConsented returns the customers, among customerIDs, who consented to
+// marketing on the channel and are still active. constant.ErrInvalidConsentChannel
+// is returned when the channel isn't a consent channel.
+func (c customerConsentUseCase) FilterConsented(ctx context.Context, channel string, customerIDs []int) ([]int, error) {
+	if !domain.IsConsentChannel(channel) {
+		return nil, constant.ErrInvalidConsentChannel
+	}
+	return c.consentPgRepository.FindConsentedCustomerIDs(ctx, channel, customerIDs)
+}
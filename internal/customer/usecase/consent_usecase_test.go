@@ -0,0 +1,113 @@
+package usecase
+
+import (
+	"context"
+	"github.com/alpakih/point-of-sales/internal/constant"
+	"github.com/alpakih/point-of-sales/internal/customer"
+	"github.com/alpakih/point-of-sales/internal/customer/mocks"
+	"github.com/alpakih/point-of-sales/internal/domain"
+	dbMocks "github.com/alpakih/point-of-sales/pkg/database/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+	"testing"
+	"time"
+)
+
+func TestCustomerConsentUseCase_UpdateConsents(t *testing.T) {
+	mockCustomerRepository := new(mocks.PgRepository)
+	mockConsentRepository := new(mocks.ConsentPgRepository)
+	mockTxManager := new(dbMocks.TxManager)
+	mockTxManager.On("WithinTransaction", mock.Anything, mock.Anything).Return(func(ctx context.Context, fn func(context.Context) error) error {
+		return fn(ctx)
+	})
+	granted, withdrawn := true, false
+
+	t.Run("success", func(t *testing.T) {
+		mockConsentRepository.On("LockCustomer", mock.Anything, 1).Return(nil).Once()
+		mockConsentRepository.On("FindConsents", mock.Anything, 1).Return([]domain.CustomerConsent{
+			{ID: 1, CustomerID: 1, Channel: domain.ConsentChannelEmail, Granted: true, Source: domain.ConsentSourcePOS, CreatedAt: time.Now()},
+		}, nil).Once()
+		// email is already granted and whatsapp never was, only the sms grant is recorded
+		mockConsentRepository.On("Append", mock.Anything, mock.MatchedBy(func(consents []domain.CustomerConsent) bool {
+			return len(consents) == 1 && consents[0].Channel == domain.ConsentChannelSMS && consents[0].Granted &&
+				consents[0].Source == domain.ConsentSourceWeb
+		})).Return(nil).Once()
+
+		u := NewCustomerConsentUseCase(mockCustomerRepository, mockConsentRepository, mockTxManager)
+
+		data, err := u.UpdateConsents(context.TODO(), 1, customer.ConsentRequest{Source: domain.ConsentSourceWeb, Consents: []customer.ConsentChange{
+			{Channel: domain.ConsentChannelEmail, Granted: &granted},
+			{Channel: domain.ConsentChannelSMS, Granted: &granted},
+			{Channel: domain.ConsentChannelWhatsApp, Granted: &withdrawn},
+		}})
+
+		assert.NoError(t, err)
+		assert.Len(t, data.History, 2)
+		assert.Equal(t, domain.ConsentChannelSMS, data.History[0].Channel)
+		assert.Equal(t, customer.ConsentStatus{Channel: domain.ConsentChannelWhatsApp}, data.Consents[2])
+		assert.True(t, data.Consents[0].Granted)
+		assert.True(t, data.Consents[1].Granted)
+		mockConsentRepository.AssertExpectations(t)
+	})
+
+	t.Run("duplicate-channel", func(t *testing.T) {
+		mockConsentRepository.On("LockCustomer", mock.Anything, 1).Return(nil).Once()
+		mockConsentRepository.On("FindConsents", mock.Anything, 1).Return([]domain.CustomerConsent{}, nil).Once()
+		// sms listed twice records a single change, to its last value
+		mockConsentRepository.On("Append", mock.Anything, mock.MatchedBy(func(consents []domain.CustomerConsent) bool {
+			return len(consents) == 1 && consents[0].Channel == domain.ConsentChannelSMS && consents[0].Granted
+		})).Return(nil).Once()
+
+		u := NewCustomerConsentUseCase(mockCustomerRepository, mockConsentRepository, mockTxManager)
+
+		data, err := u.UpdateConsents(context.TODO(), 1, customer.ConsentRequest{Source: domain.ConsentSourcePOS, Consents: []customer.ConsentChange{
+			{Channel: domain.ConsentChannelSMS, Granted: &granted},
+			{Channel: domain.ConsentChannelSMS, Granted: &granted},
+			{Channel: domain.ConsentChannelEmail, Granted: &granted},
+			{Channel: domain.ConsentChannelEmail, Granted: &withdrawn},
+		}})
+
+		assert.NoError(t, err)
+		assert.Len(t, data.History, 1)
+		mockConsentRepository.AssertExpectations(t)
+	})
+
+	t.Run("not-found", func(t *testing.T) {
+		mockConsentRepository.On("LockCustomer", mock.Anything, 2).Return(gorm.ErrRecordNotFound).Once()
+
+		u := NewCustomerConsentUseCase(mockCustomerRepository, mockConsentRepository, mockTxManager)
+
+		_, err := u.UpdateConsents(context.TODO(), 2, customer.ConsentRequest{Source: domain.ConsentSourcePOS, Consents: []customer.ConsentChange{
+			{Channel: domain.ConsentChannelEmail, Granted: &granted},
+		}})
+
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	})
+}
+
+func TestCustomerConsentUseCase_FilterConsented(t *testing.T) {
+	mockCustomerRepository := new(mocks.PgRepository)
+	mockConsentRepository := new(mocks.ConsentPgRepository)
+	mockTxManager := new(dbMocks.TxManager)
+
+	t.Run("success", func(t *testing.T) {
+		mockConsentRepository.On("FindConsentedCustomerIDs", mock.Anything, domain.ConsentChannelSMS, []int{1, 2, 3}).Return([]int{2}, nil).Once()
+
+		u := NewCustomerConsentUseCase(mockCustomerRepository, mockConsentRepository, mockTxManager)
+
+		ids, err := u.FilterConsented(context.TODO(), domain.ConsentChannelSMS, []int{1, 2, 3})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []int{2}, ids)
+		mockConsentRepository.AssertExpectations(t)
+	})
+
+	t.Run("invalid-channel", func(t *testing.T) {
+		u := NewCustomerConsentUseCase(mockCustomerRepository, mockConsentRepository, mockTxManager)
+
+		_, err := u.FilterConsented(context.TODO(), "fax", []int{1})
+
+		assert.ErrorIs(t, err, constant.ErrInvalidConsentChannel)
+	})
+}
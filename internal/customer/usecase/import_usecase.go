@@ -0,0 +1,164 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"github.com/alpakih/point-of-sales/internal/constant"
+	"github.com/alpakih/point-of-sales/internal/customer"
+	"github.com/alpakih/point-of-sales/pkg/database"
+	"github.com/alpakih/point-of-sales/pkg/phone"
+	"github.com/alpakih/point-of-sales/pkg/validator"
+	"github.com/beego/beego/v2/core/logs"
+	"golang.org/x/crypto/bcrypt"
+	"time"
+)
+
+// importProgressInterval number of rows between the saves of the job progress.
+const importProgressInterval = 100
+
+type customerImportUseCase struct {
+	pgRepository        customer.PgRepository
+	importJobRepository customer.ImportJobRepository
+	txManager           database.TxManager
+}
+
+func NewCustomerImportUseCase(pgRepository customer.PgRepository, importJobRepository customer.ImportJobRepository, txManager database.TxManager) customer.ImportUseCase {
+	return &customerImportUseCase{
+		pgRepository:        pgRepository,
+		importJobRepository: importJobRepository,
+		txManager:           txManager,
+	}
+}
+
+// ImportCustomers starts the import of the rows in the background and returns
+// its pending job. A dry run validates the rows without creating the customers.
+func (c customerImportUseCase) ImportCustomers(ctx context.Context, rows []customer.ImportRow, dryRun bool) (*customer.ImportJob, error) {
+	id, err := newImportJobID()
+	if err != nil {
+		return nil, err
+	}
+	job := customer.ImportJob{
+		ID:        id,
+		Status:    customer.ImportStatusPending,
+		DryRun:    dryRun,
+		Total:     len(rows),
+		CreatedAt: time.Now(),
+	}
+	if err := c.importJobRepository.Save(ctx, job); err != nil {
+		return nil, err
+	}
+
+	// the import outlives the request, it must not be canceled with it
+	go func() {
+		if err := c.runImport(context.Background(), job, rows); err != nil {
+			logs.Error("customer import %s failed: %v", job.ID, err)
+		}
+	}()
+
+	return &job, nil
+}
+
+func (c customerImportUseCase) GetImportJob(ctx context.Context, id string) (*customer.ImportJob, error) {
+	job, err := c.importJobRepository.FindImportJobByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// runImport processes the rows in order, each one in its own transaction, and
+// records the errors of the rejected rows. Emails and mobile phones repeated in
+// the file are rejected from their second row, dry run included. The job is
+// marked failed with the error or the panic stopping the import.
+func (c customerImportUseCase) runImport(ctx context.Context, job customer.ImportJob, rows []customer.ImportRow) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("customer import panicked: %v", r)
+		}
+		if err != nil {
+			finishedAt := time.Now()
+			job.Status = customer.ImportStatusFailed
+			job.Error = err.Error()
+			job.FinishedAt = &finishedAt
+			if saveErr := c.importJobRepository.Save(ctx, job); saveErr != nil {
+				err = fmt.Errorf("%w, saving the failed job: %v", err, saveErr)
+			}
+		}
+	}()
+
+	job.Status = customer.ImportStatusRunning
+	if err := c.importJobRepository.Save(ctx, job); err != nil {
+		return err
+	}
+
+	emails := make(map[string]bool, len(rows))
+	mobilePhones := make(map[string]bool, len(rows))
+	for i, row := range rows {
+		if err := c.importRow(ctx, row, job.DryRun, emails, mobilePhones); err != nil {
+			job.Failed++
+			job.Errors = append(job.Errors, customer.ImportRowError{
+				Row:         row.Row,
+				Email:       row.Request.Email,
+				MobilePhone: row.Request.MobilePhone,
+				Err:         err,
+			})
+		} else {
+			job.Imported++
+		}
+		job.Processed++
+
+		if (i+1)%importProgressInterval == 0 {
+			if err := c.importJobRepository.Save(ctx, job); err != nil {
+				return err
+			}
+		}
+	}
+
+	finishedAt := time.Now()
+	job.Status = customer.ImportStatusDone
+	job.FinishedAt = &finishedAt
+	return c.importJobRepository.Save(ctx, job)
+}
+
+func (c customerImportUseCase) importRow(ctx context.Context, row customer.ImportRow, dryRun bool, emails, mobilePhones map[string]bool) error {
+	if err := validator.Validate.ValidateStruct(row.Request); err != nil {
+		return err
+	}
+	if emails[row.Request.Email] {
+		return constant.ErrEmailAlreadyExist
+	}
+	mobilePhone := phone.Format(row.Request.MobilePhone)
+	if mobilePhones[mobilePhone] {
+		return constant.ErrMobilePhoneAlreadyExist
+	}
+	emails[row.Request.Email] = true
+	mobilePhones[mobilePhone] = true
+
+	if dryRun {
+		return checkDuplicates(ctx, c.pgRepository, row.Request.Email, mobilePhone, 0)
+	}
+
+	var entity = customer.NewCustomerMapper().CustomerStoreRequestToEntity(row.Request)
+	password, err := bcrypt.GenerateFromPassword([]byte(entity.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	entity.Password = string(password)
+
+	return c.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := checkDuplicates(ctx, c.pgRepository, entity.Email, entity.MobilePhone, 0); err != nil {
+			return err
+		}
+		return c.pgRepository.Create(ctx, &entity)
+	})
+}
+
+func newImportJobID() (string, error) {
+	var id = make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(id), nil
+}
@@ -0,0 +1,119 @@
+package usecase
+
+import (
+	"context"
+	"github.com/alpakih/point-of-sales/internal/constant"
+	"github.com/alpakih/point-of-sales/internal/customer"
+	"github.com/alpakih/point-of-sales/internal/customer/mocks"
+	"github.com/alpakih/point-of-sales/internal/customer/repository/memory"
+	"github.com/alpakih/point-of-sales/internal/domain"
+	dbMocks "github.com/alpakih/point-of-sales/pkg/database/mocks"
+	validatorGo "github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+	"time"
+)
+
+func TestCustomerImportUseCase_ImportCustomers(t *testing.T) {
+	mockTxManager := new(dbMocks.TxManager)
+	mockTxManager.On("WithinTransaction", mock.Anything, mock.Anything).Return(func(ctx context.Context, fn func(context.Context) error) error {
+		return fn(ctx)
+	})
+	rows := []customer.ImportRow{
+		{Row: 2, Request: customer.StoreRequest{Name: "Alice", Email: "alice@test.com", MobilePhone: "087766777001", Password: "secret"}},
+		{Row: 3, Request: customer.StoreRequest{Name: "Bob", Email: "not an email", MobilePhone: "087766777002", Password: "secret"}},
+		{Row: 4, Request: customer.StoreRequest{Name: "Alice", Email: "alice@test.com", MobilePhone: "087766777003", Password: "secret"}},
+		{Row: 5, Request: customer.StoreRequest{Name: "Carol", Email: "carol@test.com", MobilePhone: "087766777004", Password: "secret"}},
+	}
+
+	t.Run("dry-run", func(t *testing.T) {
+		mockCustomerRepository := new(mocks.PgRepository)
+		mockCustomerRepository.On("CheckDuplicate", mock.Anything, "email =?", "alice@test.com").Return(int64(0), nil).Once()
+		mockCustomerRepository.On("CheckDuplicate", mock.Anything, "mobile_phone =?", "+6287766777001").Return(int64(0), nil).Once()
+		mockCustomerRepository.On("CheckDuplicate", mock.Anything, "email =?", "carol@test.com").Return(int64(1), nil).Once()
+
+		jobRepository := memory.NewImportJobMemoryRepository(time.Hour)
+		u := NewCustomerImportUseCase(mockCustomerRepository, jobRepository, mockTxManager)
+
+		job, err := u.ImportCustomers(context.TODO(), rows, true)
+		assert.NoError(t, err)
+		assert.Equal(t, customer.ImportStatusPending, job.Status)
+
+		assert.Eventually(t, func() bool {
+			job, err = u.GetImportJob(context.TODO(), job.ID)
+			return err == nil && job.Status == customer.ImportStatusDone
+		}, 5*time.Second, 10*time.Millisecond)
+
+		assert.Equal(t, 4, job.Processed)
+		assert.Equal(t, 1, job.Imported)
+		assert.Equal(t, 3, job.Failed)
+
+		var validationErrors validatorGo.ValidationErrors
+		assert.Equal(t, 3, job.Errors[0].Row)
+		assert.ErrorAs(t, job.Errors[0].Err, &validationErrors)
+		assert.Equal(t, 4, job.Errors[1].Row)
+		assert.ErrorIs(t, job.Errors[1].Err, constant.ErrEmailAlreadyExist)
+		assert.Equal(t, 5, job.Errors[2].Row)
+		assert.ErrorIs(t, job.Errors[2].Err, constant.ErrEmailAlreadyExist)
+		mockCustomerRepository.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		mockCustomerRepository.AssertExpectations(t)
+	})
+
+	t.Run("import", func(t *testing.T) {
+		mockCustomerRepository := new(mocks.PgRepository)
+		mockCustomerRepository.On("CheckDuplicate", mock.Anything, mock.Anything, mock.Anything).Return(int64(0), nil)
+		mockCustomerRepository.On("Create", mock.Anything, mock.MatchedBy(func(entity *domain.Customer) bool {
+			return entity.Password != "secret"
+		})).Return(nil).Twice()
+
+		jobRepository := memory.NewImportJobMemoryRepository(time.Hour)
+		u := customerImportUseCase{pgRepository: mockCustomerRepository, importJobRepository: jobRepository, txManager: mockTxManager}
+
+		job := customer.ImportJob{ID: "job", Total: len(rows)}
+		assert.NoError(t, u.runImport(context.TODO(), job, rows))
+
+		result, err := u.GetImportJob(context.TODO(), "job")
+		assert.NoError(t, err)
+		assert.Equal(t, customer.ImportStatusDone, result.Status)
+		assert.NotNil(t, result.FinishedAt)
+		assert.Equal(t, 2, result.Imported)
+		assert.Equal(t, 2, result.Failed)
+		mockCustomerRepository.AssertExpectations(t)
+	})
+
+	t.Run("panic", func(t *testing.T) {
+		mockCustomerRepository := new(mocks.PgRepository)
+		mockCustomerRepository.On("CheckDuplicate", mock.Anything, mock.Anything, mock.Anything).Return(int64(0), nil)
+		mockCustomerRepository.On("Create", mock.Anything, mock.Anything).Return(nil).Once()
+		mockCustomerRepository.On("Create", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			panic("connection lost")
+		}).Once()
+
+		jobRepository := memory.NewImportJobMemoryRepository(time.Hour)
+		u := NewCustomerImportUseCase(mockCustomerRepository, jobRepository, mockTxManager)
+
+		job, err := u.ImportCustomers(context.TODO(), rows, false)
+		assert.NoError(t, err)
+
+		assert.Eventually(t, func() bool {
+			job, err = u.GetImportJob(context.TODO(), job.ID)
+			return err == nil && job.Status == customer.ImportStatusFailed
+		}, 5*time.Second, 10*time.Millisecond)
+
+		assert.Equal(t, "customer import panicked: connection lost", job.Error)
+		assert.NotNil(t, job.FinishedAt)
+		// the rows processed before the panic are kept
+		assert.Equal(t, 3, job.Processed)
+		assert.Equal(t, 1, job.Imported)
+		assert.Equal(t, 2, job.Failed)
+		mockCustomerRepository.AssertExpectations(t)
+	})
+
+	t.Run("job-not-found", func(t *testing.T) {
+		u := NewCustomerImportUseCase(new(mocks.PgRepository), memory.NewImportJobMemoryRepository(time.Hour), mockTxManager)
+
+		_, err := u.GetImportJob(context.TODO(), "unknown")
+		assert.ErrorIs(t, err, constant.ErrImportJobNotFound)
+	})
+}
@@ -0,0 +1,264 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"github.com/alpakih/point-of-sales/internal/constant"
+	"github.com/alpakih/point-of-sales/internal/customer"
+	"github.com/alpakih/point-of-sales/internal/domain"
+	"github.com/alpakih/point-of-sales/pkg/database"
+	"gorm.io/gorm"
+	"time"
+)
+
+type customerLoyaltyUseCase struct {
+	pgRepository           customer.PgRepository
+	loyaltyPgRepository    customer.LoyaltyPgRepository
+	membershipPgRepository customer.MembershipPgRepository
+	txManager              database.TxManager
+	rule                   customer.LoyaltyRule
+}
+
+func NewCustomerLoyaltyUseCase(pgRepository customer.PgRepository, loyaltyPgRepository customer.LoyaltyPgRepository,
+	membershipPgRepository customer.MembershipPgRepository, txManager database.TxManager, rule customer.LoyaltyRule) customer.LoyaltyUseCase {
+	return &customerLoyaltyUseCase{
+		pgRepository:           pgRepository,
+		loyaltyPgRepository:    loyaltyPgRepository,
+		membershipPgRepository: membershipPgRepository,
+		txManager:              txManager,
+		rule:                   rule,
+	}
+}
+
+// GetLoyalty returns the points the customer can redeem and its ledger.
+func (c customerLoyaltyUseCase) GetLoyalty(ctx context.Context, customerID int) (*customer.LoyaltyResponse, error) {
+	if _, err := c.pgRepository.FindOneCustomerByID(ctx, customerID); err != nil {
+		return nil, err
+	}
+	entries, err := c.loyaltyPgRepository.FindEntries(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	ledger := customer.ReplayLoyaltyLedger(entries)
+	return &customer.LoyaltyResponse{
+		Balance:    ledger.Balance(now),
+		NextExpiry: ledger.NextExpiry(now),
+		History:    customer.NewCustomerMapper().ToLoyaltyEntryResponses(entries),
+	}, nil
+}
+
+// EarnPoints credits the points earned by a sale, multiplied by the multiplier
+// of the membership tier of the customer. The sale is recorded with its amount
+// and lines even when it earns no point, a sale without amount appends nothing.
+// constant.ErrLoyaltyReferenceExists is returned when the sale already earned
+// points.
+func (c customerLoyaltyUseCase) EarnPoints(ctx context.Context, customerID int, request customer.EarnRequest) (*customer.LoyaltyEntryResponse, error) {
+	now := time.Now()
+	expiresAt := c.rule.ExpiresAt(now)
+	var entry = domain.LoyaltyEntry{
+		CustomerID: customerID,
+		Type:       domain.LoyaltyEntryEarn,
+		Reference:  request.Reference,
+		ExpiresAt:  &expiresAt,
+		CreatedAt:  now,
+	}
+	for _, line := range request.Lines {
+		entry.Amount += line.Amount
+		entry.Lines = append(entry.Lines, domain.LoyaltyEntryLine{
+			Product:  line.Product,
+			Category: line.Category,
+			Quantity: line.Quantity,
+			Amount:   line.Amount,
+		})
+	}
+
+	err := c.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := c.loyaltyPgRepository.LockCustomer(ctx, customerID); err != nil {
+			return err
+		}
+		tiers, err := c.membershipPgRepository.FindCurrentTiers(ctx, []int{customerID})
+		if err != nil {
+			return err
+		}
+		var tier = c.rule.Tiers.Base()
+		if len(tiers) > 0 {
+			tier = c.rule.Tiers.Current(tiers[0].Tier)
+		}
+		entry.Points = c.rule.EarnedPoints(request.Lines, tier)
+		if entry.Amount == 0 {
+			return nil
+		}
+		entries, err := c.loyaltyPgRepository.Append(ctx, []domain.LoyaltyEntry{entry})
+		if err != nil {
+			return err
+		}
+		entry = entries[0]
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := customer.NewCustomerMapper().ToLoyaltyEntryResponse(entry)
+	return &result, nil
+}
+
+// RedeemPoints debits the points tendered for a sale and returns the amount
+// they pay for. The points expired in the meantime are expired first,
+// constant.ErrInsufficientPoints is returned when the balance is lower than
+// the points.
+func (c customerLoyaltyUseCase) RedeemPoints(ctx context.Context, customerID int, request customer.RedeemRequest) (*customer.RedemptionResponse, error) {
+	now := time.Now()
+	var entry = domain.LoyaltyEntry{
+		CustomerID: customerID,
+		Type:       domain.LoyaltyEntryRedeem,
+		Points:     -request.Points,
+		Reference:  request.Reference,
+		CreatedAt:  now,
+	}
+	var balance int
+
+	err := c.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := c.loyaltyPgRepository.LockCustomer(ctx, customerID); err != nil {
+			return err
+		}
+		entries, err := c.loyaltyPgRepository.FindEntries(ctx, customerID)
+		if err != nil {
+			return err
+		}
+
+		ledger := customer.ReplayLoyaltyLedger(entries)
+		balance = ledger.Balance(now)
+		if balance < request.Points {
+			return constant.ErrInsufficientPoints
+		}
+
+		appended, err := c.loyaltyPgRepository.Append(ctx, append(expireEntries(ledger, customerID, now), entry))
+		if err != nil {
+			return err
+		}
+		entry = appended[len(appended)-1]
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &customer.RedemptionResponse{
+		Entry:   customer.NewCustomerMapper().ToLoyaltyEntryResponse(entry),
+		Amount:  int64(request.Points) * c.rule.PointValue,
+		Balance: balance - request.Points,
+	}, nil
+}
+
+// ReversePoints reverses the points earned and redeemed by a refunded sale, the
+// redeemed points are credited back with a new expiry. gorm.ErrRecordNotFound
+// is returned when the sale has no entry left to reverse.
+func (c customerLoyaltyUseCase) ReversePoints(ctx context.Context, customerID int, request customer.ReversalRequest) ([]customer.LoyaltyEntryResponse, error) {
+	now := time.Now()
+	var reversals []domain.LoyaltyEntry
+
+	err := c.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := c.loyaltyPgRepository.LockCustomer(ctx, customerID); err != nil {
+			return err
+		}
+		entries, err := c.loyaltyPgRepository.FindEntries(ctx, customerID)
+		if err != nil {
+			return err
+		}
+
+		var reversed = make(map[int]bool)
+		for _, entry := range entries {
+			if entry.Type == domain.LoyaltyEntryReversal && entry.RelatedEntryID != nil {
+				reversed[*entry.RelatedEntryID] = true
+			}
+		}
+		for _, entry := range entries {
+			if entry.Reference != request.Reference || reversed[entry.ID] ||
+				(entry.Type != domain.LoyaltyEntryEarn && entry.Type != domain.LoyaltyEntryRedeem) {
+				continue
+			}
+			entryID := entry.ID
+			var reversal = domain.LoyaltyEntry{
+				CustomerID:     customerID,
+				Type:           domain.LoyaltyEntryReversal,
+				Points:         -entry.Points,
+				Amount:         -entry.Amount,
+				Reference:      request.Reference,
+				RelatedEntryID: &entryID,
+				CreatedAt:      now,
+			}
+			if reversal.Points > 0 {
+				expiresAt := c.rule.ExpiresAt(now)
+				reversal.ExpiresAt = &expiresAt
+			}
+			reversals = append(reversals, reversal)
+		}
+		if len(reversals) == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		reversals, err = c.loyaltyPgRepository.Append(ctx, reversals)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	mapper := customer.NewCustomerMapper()
+	var result = make([]customer.LoyaltyEntryResponse, len(reversals))
+	for k, v := range reversals {
+		result[k] = mapper.ToLoyaltyEntryResponse(v)
+	}
+	return result, nil
+}
+
+// ExpirePoints expires the points left of the credits expiring after from, up
+// to to, and returns the number of expiry entries appended. The ledgers of the
+// deleted customers are left as is.
+func (c customerLoyaltyUseCase) ExpirePoints(ctx context.Context, from, to time.Time) (int, error) {
+	ids, err := c.loyaltyPgRepository.FindCustomerIDsWithExpiringPoints(ctx, from, to)
+	if err != nil {
+		return 0, err
+	}
+
+	var expired int
+	for _, id := range ids {
+		err := c.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+			if err := c.loyaltyPgRepository.LockCustomer(ctx, id); err != nil {
+				return err
+			}
+			entries, err := c.loyaltyPgRepository.FindEntries(ctx, id)
+			if err != nil {
+				return err
+			}
+
+			appended, err := c.loyaltyPgRepository.Append(ctx, expireEntries(customer.ReplayLoyaltyLedger(entries), id, to))
+			expired += len(appended)
+			return err
+		})
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return expired, err
+		}
+	}
+	return expired, nil
+}
+
+// expireEntries entries expiring the points left of the lots of the ledger
+// expired at t.
+func expireEntries(ledger customer.LoyaltyLedger, customerID int, t time.Time) []domain.LoyaltyEntry {
+	var entries []domain.LoyaltyEntry
+	for _, lot := range ledger.Expired(t) {
+		entryID := lot.EntryID
+		entries = append(entries, domain.LoyaltyEntry{
+			CustomerID:     customerID,
+			Type:           domain.LoyaltyEntryExpire,
+			Points:         -lot.Remaining,
+			RelatedEntryID: &entryID,
+			CreatedAt:      t,
+		})
+	}
+	return entries
+}
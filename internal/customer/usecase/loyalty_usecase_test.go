@@ -0,0 +1,270 @@
+package usecase
+
+import (
+	"context"
+	"github.com/alpakih/point-of-sales/internal/constant"
+	"github.com/alpakih/point-of-sales/internal/customer"
+	"github.com/alpakih/point-of-sales/internal/customer/mocks"
+	"github.com/alpakih/point-of-sales/internal/domain"
+	dbMocks "github.com/alpakih/point-of-sales/pkg/database/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+	"testing"
+	"time"
+)
+
+var loyaltyRule = customer.LoyaltyRule{
+	AmountPerPoint:     10000,
+	PointValue:         100,
+	ExcludedCategories: []string{"tobacco"},
+	ExpiryMonths:       12,
+	Tiers: customer.MembershipTiers{
+		{Name: "silver", Multiplier: 1},
+		{Name: "gold", MinSpend: 5000000, Multiplier: 1.5, Discount: 5},
+	},
+}
+
+func TestCustomerLoyaltyUseCase_EarnPoints(t *testing.T) {
+	mockCustomerRepository := new(mocks.PgRepository)
+	mockLoyaltyRepository := new(mocks.LoyaltyPgRepository)
+	mockMembershipRepository := new(mocks.MembershipPgRepository)
+	mockTxManager := new(dbMocks.TxManager)
+	mockTxManager.On("WithinTransaction", mock.Anything, mock.Anything).Return(func(ctx context.Context, fn func(context.Context) error) error {
+		return fn(ctx)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		mockLoyaltyRepository.On("LockCustomer", mock.Anything, 1).Return(nil).Once()
+		mockMembershipRepository.On("FindCurrentTiers", mock.Anything, []int{1}).Return([]domain.CustomerTierChange{{CustomerID: 1, Tier: "gold"}}, nil).Once()
+		mockLoyaltyRepository.On("Append", mock.Anything, mock.MatchedBy(func(entries []domain.LoyaltyEntry) bool {
+			return len(entries) == 1 && entries[0].Type == domain.LoyaltyEntryEarn && entries[0].Points == 4 && entries[0].Amount == 84000 &&
+				entries[0].Reference == "S-1" && entries[0].ExpiresAt.Sub(entries[0].CreatedAt) > 360*24*time.Hour &&
+				len(entries[0].Lines) == 3 && entries[0].Lines[0].Product == "Nasi Goreng" && entries[0].Lines[0].Quantity == 1
+		})).Return(func(ctx context.Context, entries []domain.LoyaltyEntry) []domain.LoyaltyEntry {
+			entries[0].ID = 10
+			return entries
+		}, nil).Once()
+
+		u := NewCustomerLoyaltyUseCase(mockCustomerRepository, mockLoyaltyRepository, mockMembershipRepository, mockTxManager, loyaltyRule)
+
+		// 3 points of the eligible Rp34.000 multiplied by 1.5
+		data, err := u.EarnPoints(context.TODO(), 1, customer.EarnRequest{Reference: "S-1", Lines: []customer.EarnLine{
+			{Product: "Nasi Goreng", Category: "food", Quantity: 1, Amount: 25000},
+			{Category: "Tobacco", Amount: 50000},
+			{Category: "drink", Amount: 9000},
+		}})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 10, data.ID)
+		assert.Equal(t, 4, data.Points)
+		mockLoyaltyRepository.AssertExpectations(t)
+		mockMembershipRepository.AssertExpectations(t)
+	})
+
+	t.Run("no-points", func(t *testing.T) {
+		mockLoyaltyRepository.On("LockCustomer", mock.Anything, 1).Return(nil).Once()
+		mockMembershipRepository.On("FindCurrentTiers", mock.Anything, []int{1}).Return([]domain.CustomerTierChange{}, nil).Once()
+		mockLoyaltyRepository.On("Append", mock.Anything, mock.MatchedBy(func(entries []domain.LoyaltyEntry) bool {
+			return len(entries) == 1 && entries[0].Points == 0 && entries[0].Amount == 9999
+		})).Return(func(ctx context.Context, entries []domain.LoyaltyEntry) []domain.LoyaltyEntry {
+			return entries
+		}, nil).Once()
+
+		u := NewCustomerLoyaltyUseCase(mockCustomerRepository, mockLoyaltyRepository, mockMembershipRepository, mockTxManager, loyaltyRule)
+
+		data, err := u.EarnPoints(context.TODO(), 1, customer.EarnRequest{Reference: "S-2", Lines: []customer.EarnLine{{Amount: 9999}}})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 0, data.Points)
+		mockLoyaltyRepository.AssertExpectations(t)
+	})
+
+	t.Run("reference-exists", func(t *testing.T) {
+		mockLoyaltyRepository.On("LockCustomer", mock.Anything, 1).Return(nil).Once()
+		mockMembershipRepository.On("FindCurrentTiers", mock.Anything, []int{1}).Return([]domain.CustomerTierChange{}, nil).Once()
+		mockLoyaltyRepository.On("Append", mock.Anything, mock.Anything).Return(nil, constant.ErrLoyaltyReferenceExists).Once()
+
+		u := NewCustomerLoyaltyUseCase(mockCustomerRepository, mockLoyaltyRepository, mockMembershipRepository, mockTxManager, loyaltyRule)
+
+		_, err := u.EarnPoints(context.TODO(), 1, customer.EarnRequest{Reference: "S-1", Lines: []customer.EarnLine{{Amount: 10000}}})
+
+		assert.ErrorIs(t, err, constant.ErrLoyaltyReferenceExists)
+		mockLoyaltyRepository.AssertExpectations(t)
+	})
+}
+
+func TestCustomerLoyaltyUseCase_RedeemPoints(t *testing.T) {
+	mockCustomerRepository := new(mocks.PgRepository)
+	mockLoyaltyRepository := new(mocks.LoyaltyPgRepository)
+	mockMembershipRepository := new(mocks.MembershipPgRepository)
+	mockTxManager := new(dbMocks.TxManager)
+	mockTxManager.On("WithinTransaction", mock.Anything, mock.Anything).Return(func(ctx context.Context, fn func(context.Context) error) error {
+		return fn(ctx)
+	})
+	expired, live := time.Now().AddDate(0, 0, -1), time.Now().AddDate(0, 1, 0)
+	entries := []domain.LoyaltyEntry{
+		{ID: 1, CustomerID: 1, Type: domain.LoyaltyEntryEarn, Points: 5, ExpiresAt: &expired},
+		{ID: 2, CustomerID: 1, Type: domain.LoyaltyEntryEarn, Points: 8, ExpiresAt: &live},
+	}
+
+	t.Run("success", func(t *testing.T) {
+		mockLoyaltyRepository.On("LockCustomer", mock.Anything, 1).Return(nil).Once()
+		mockLoyaltyRepository.On("FindEntries", mock.Anything, 1).Return(entries, nil).Once()
+		mockLoyaltyRepository.On("Append", mock.Anything, mock.MatchedBy(func(entries []domain.LoyaltyEntry) bool {
+			return len(entries) == 2 &&
+				entries[0].Type == domain.LoyaltyEntryExpire && entries[0].Points == -5 && *entries[0].RelatedEntryID == 1 &&
+				entries[1].Type == domain.LoyaltyEntryRedeem && entries[1].Points == -6 && entries[1].Reference == "S-9"
+		})).Return(func(ctx context.Context, entries []domain.LoyaltyEntry) []domain.LoyaltyEntry {
+			return entries
+		}, nil).Once()
+
+		u := NewCustomerLoyaltyUseCase(mockCustomerRepository, mockLoyaltyRepository, mockMembershipRepository, mockTxManager, loyaltyRule)
+
+		data, err := u.RedeemPoints(context.TODO(), 1, customer.RedeemRequest{Reference: "S-9", Points: 6})
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(600), data.Amount)
+		assert.Equal(t, 2, data.Balance)
+		mockLoyaltyRepository.AssertExpectations(t)
+	})
+
+	t.Run("insufficient-points", func(t *testing.T) {
+		mockLoyaltyRepository.On("LockCustomer", mock.Anything, 1).Return(nil).Once()
+		mockLoyaltyRepository.On("FindEntries", mock.Anything, 1).Return(entries, nil).Once()
+
+		u := NewCustomerLoyaltyUseCase(mockCustomerRepository, mockLoyaltyRepository, mockMembershipRepository, mockTxManager, loyaltyRule)
+
+		_, err := u.RedeemPoints(context.TODO(), 1, customer.RedeemRequest{Reference: "S-9", Points: 9})
+
+		assert.ErrorIs(t, err, constant.ErrInsufficientPoints)
+		mockLoyaltyRepository.AssertExpectations(t)
+	})
+}
+
+func TestCustomerLoyaltyUseCase_ReversePoints(t *testing.T) {
+	mockCustomerRepository := new(mocks.PgRepository)
+	mockLoyaltyRepository := new(mocks.LoyaltyPgRepository)
+	mockMembershipRepository := new(mocks.MembershipPgRepository)
+	mockTxManager := new(dbMocks.TxManager)
+	mockTxManager.On("WithinTransaction", mock.Anything, mock.Anything).Return(func(ctx context.Context, fn func(context.Context) error) error {
+		return fn(ctx)
+	})
+	live := time.Now().AddDate(0, 1, 0)
+	earnID := 1
+	entries := []domain.LoyaltyEntry{
+		{ID: 1, CustomerID: 1, Type: domain.LoyaltyEntryEarn, Points: 5, Reference: "S-1", ExpiresAt: &live},
+		{ID: 2, CustomerID: 1, Type: domain.LoyaltyEntryReversal, Points: -5, Reference: "S-1", RelatedEntryID: &earnID},
+		{ID: 3, CustomerID: 1, Type: domain.LoyaltyEntryEarn, Points: 4, Reference: "S-2", ExpiresAt: &live},
+		{ID: 4, CustomerID: 1, Type: domain.LoyaltyEntryRedeem, Points: -3, Reference: "S-2"},
+	}
+
+	t.Run("success", func(t *testing.T) {
+		mockLoyaltyRepository.On("LockCustomer", mock.Anything, 1).Return(nil).Once()
+		mockLoyaltyRepository.On("FindEntries", mock.Anything, 1).Return(entries, nil).Once()
+		mockLoyaltyRepository.On("Append", mock.Anything, mock.MatchedBy(func(entries []domain.LoyaltyEntry) bool {
+			return len(entries) == 2 &&
+				entries[0].Points == -4 && *entries[0].RelatedEntryID == 3 && entries[0].ExpiresAt == nil &&
+				entries[1].Points == 3 && *entries[1].RelatedEntryID == 4 && entries[1].ExpiresAt != nil
+		})).Return(func(ctx context.Context, entries []domain.LoyaltyEntry) []domain.LoyaltyEntry {
+			return entries
+		}, nil).Once()
+
+		u := NewCustomerLoyaltyUseCase(mockCustomerRepository, mockLoyaltyRepository, mockMembershipRepository, mockTxManager, loyaltyRule)
+
+		data, err := u.ReversePoints(context.TODO(), 1, customer.ReversalRequest{Reference: "S-2"})
+
+		assert.NoError(t, err)
+		assert.Len(t, data, 2)
+		assert.Equal(t, domain.LoyaltyEntryReversal, data[0].Type)
+		mockLoyaltyRepository.AssertExpectations(t)
+	})
+
+	t.Run("already-reversed", func(t *testing.T) {
+		mockLoyaltyRepository.On("LockCustomer", mock.Anything, 1).Return(nil).Once()
+		mockLoyaltyRepository.On("FindEntries", mock.Anything, 1).Return(entries, nil).Once()
+
+		u := NewCustomerLoyaltyUseCase(mockCustomerRepository, mockLoyaltyRepository, mockMembershipRepository, mockTxManager, loyaltyRule)
+
+		_, err := u.ReversePoints(context.TODO(), 1, customer.ReversalRequest{Reference: "S-1"})
+
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+		mockLoyaltyRepository.AssertExpectations(t)
+	})
+}
+
+func TestCustomerLoyaltyUseCase_GetLoyalty(t *testing.T) {
+	mockCustomerRepository := new(mocks.PgRepository)
+	mockLoyaltyRepository := new(mocks.LoyaltyPgRepository)
+	mockMembershipRepository := new(mocks.MembershipPgRepository)
+	mockTxManager := new(dbMocks.TxManager)
+	soon, later := time.Now().AddDate(0, 0, 10), time.Now().AddDate(0, 6, 0)
+	earnID := 2
+
+	t.Run("success", func(t *testing.T) {
+		mockCustomerRepository.On("FindOneCustomerByID", mock.Anything, 1).Return(domain.Customer{ID: 1}, nil).Once()
+		mockLoyaltyRepository.On("FindEntries", mock.Anything, 1).Return([]domain.LoyaltyEntry{
+			{ID: 1, Type: domain.LoyaltyEntryEarn, Points: 10, ExpiresAt: &soon},
+			{ID: 2, Type: domain.LoyaltyEntryEarn, Points: 7, ExpiresAt: &later},
+			{ID: 3, Type: domain.LoyaltyEntryRedeem, Points: -4},
+			// the reversal of an earn takes the points of the reversed earn
+			{ID: 4, Type: domain.LoyaltyEntryReversal, Points: -7, RelatedEntryID: &earnID},
+		}, nil).Once()
+
+		u := NewCustomerLoyaltyUseCase(mockCustomerRepository, mockLoyaltyRepository, mockMembershipRepository, mockTxManager, loyaltyRule)
+
+		data, err := u.GetLoyalty(context.TODO(), 1)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 6, data.Balance)
+		assert.Equal(t, 6, data.NextExpiry.Points)
+		assert.Equal(t, []int{4, 3, 2, 1}, []int{data.History[0].ID, data.History[1].ID, data.History[2].ID, data.History[3].ID})
+		mockCustomerRepository.AssertExpectations(t)
+		mockLoyaltyRepository.AssertExpectations(t)
+	})
+
+	t.Run("customer-not-found", func(t *testing.T) {
+		mockCustomerRepository.On("FindOneCustomerByID", mock.Anything, 2).Return(domain.Customer{}, gorm.ErrRecordNotFound).Once()
+
+		u := NewCustomerLoyaltyUseCase(mockCustomerRepository, mockLoyaltyRepository, mockMembershipRepository, mockTxManager, loyaltyRule)
+
+		_, err := u.GetLoyalty(context.TODO(), 2)
+
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+		mockCustomerRepository.AssertExpectations(t)
+	})
+}
+
+func TestCustomerLoyaltyUseCase_ExpirePoints(t *testing.T) {
+	mockCustomerRepository := new(mocks.PgRepository)
+	mockLoyaltyRepository := new(mocks.LoyaltyPgRepository)
+	mockMembershipRepository := new(mocks.MembershipPgRepository)
+	mockTxManager := new(dbMocks.TxManager)
+	mockTxManager.On("WithinTransaction", mock.Anything, mock.Anything).Return(func(ctx context.Context, fn func(context.Context) error) error {
+		return fn(ctx)
+	})
+	now := time.Now()
+	expired := now.AddDate(0, 0, -1)
+
+	mockLoyaltyRepository.On("FindCustomerIDsWithExpiringPoints", mock.Anything, now.AddDate(0, 0, -7), now).Return([]int{1, 2}, nil).Once()
+	mockLoyaltyRepository.On("LockCustomer", mock.Anything, 1).Return(nil).Once()
+	mockLoyaltyRepository.On("FindEntries", mock.Anything, 1).Return([]domain.LoyaltyEntry{
+		{ID: 1, CustomerID: 1, Type: domain.LoyaltyEntryEarn, Points: 5, ExpiresAt: &expired},
+		{ID: 2, CustomerID: 1, Type: domain.LoyaltyEntryRedeem, Points: -2, CreatedAt: expired.AddDate(0, 0, -1)},
+	}, nil).Once()
+	mockLoyaltyRepository.On("Append", mock.Anything, []domain.LoyaltyEntry{
+		{CustomerID: 1, Type: domain.LoyaltyEntryExpire, Points: -3, RelatedEntryID: func() *int { id := 1; return &id }(), CreatedAt: now},
+	}).Return(func(ctx context.Context, entries []domain.LoyaltyEntry) []domain.LoyaltyEntry {
+		return entries
+	}, nil).Once()
+	// deleted customer
+	mockLoyaltyRepository.On("LockCustomer", mock.Anything, 2).Return(gorm.ErrRecordNotFound).Once()
+
+	u := NewCustomerLoyaltyUseCase(mockCustomerRepository, mockLoyaltyRepository, mockMembershipRepository, mockTxManager, loyaltyRule)
+
+	expiredEntries, err := u.ExpirePoints(context.TODO(), now.AddDate(0, 0, -7), now)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, expiredEntries)
+	mockLoyaltyRepository.AssertExpectations(t)
+}
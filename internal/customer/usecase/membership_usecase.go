@@ -0,0 +1,121 @@
+package usecase
+
+import (
+	"context"
+	"github.com/alpakih/point-of-sales/internal/customer"
+	"github.com/alpakih/point-of-sales/internal/domain"
+	"github.com/alpakih/point-of-sales/pkg/database"
+	"time"
+)
+
+// membershipWindowMonths months of spend and points a membership tier is
+// evaluated on.
+const membershipWindowMonths = 12
+
+type customerMembershipUseCase struct {
+	pgRepository           customer.PgRepository
+	membershipPgRepository customer.MembershipPgRepository
+	txManager              database.TxManager
+	tiers                  customer.MembershipTiers
+}
+
+func NewCustomerMembershipUseCase(pgRepository customer.PgRepository, membershipPgRepository customer.MembershipPgRepository,
+	txManager database.TxManager, tiers customer.MembershipTiers) customer.MembershipUseCase {
+	return &customerMembershipUseCase{
+		pgRepository:           pgRepository,
+		membershipPgRepository: membershipPgRepository,
+		txManager:              txManager,
+		tiers:                  tiers,
+	}
+}
+
+// GetMembership returns the current tier of the customer, the tier evaluated by
+// the latest EvaluateTiers, with its rolling spend and points as of now.
+func (c customerMembershipUseCase) GetMembership(ctx context.Context, customerID int) (*customer.MembershipResponse, error) {
+	if _, err := c.pgRepository.FindOneCustomerByID(ctx, customerID); err != nil {
+		return nil, err
+	}
+	changes, err := c.membershipPgRepository.FindTierChanges(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	activities, err := c.membershipPgRepository.FindActivities(ctx, now.AddDate(0, -membershipWindowMonths, 0), now, []int{customerID})
+	if err != nil {
+		return nil, err
+	}
+
+	var tier = c.tiers.Base()
+	if len(changes) > 0 {
+		tier = c.tiers.Current(changes[0].Tier)
+	}
+	var result = customer.MembershipResponse{
+		Tier:       tier.Name,
+		Multiplier: tier.Multiplier,
+		Discount:   tier.Discount,
+		History:    customer.NewCustomerMapper().ToTierChangeResponses(changes),
+	}
+	if len(activities) > 0 {
+		result.Spend = activities[0].Spend
+		result.Points = activities[0].Points
+	}
+	return &result, nil
+}
+
+// EvaluateTiers evaluates the tiers of the active customers on their spend and
+// points of the last 12 months and records the customers whose tier changed,
+// upgraded or downgraded. It returns the number of changes.
+func (c customerMembershipUseCase) EvaluateTiers(ctx context.Context, now time.Time) (int, error) {
+	var changes []domain.CustomerTierChange
+
+	err := c.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		activities, err := c.membershipPgRepository.FindActivities(ctx, now.AddDate(0, -membershipWindowMonths, 0), now, nil)
+		if err != nil {
+			return err
+		}
+		current, err := c.membershipPgRepository.FindCurrentTiers(ctx, nil)
+		if err != nil {
+			return err
+		}
+
+		var tiers = make(map[int]string)
+		for _, change := range current {
+			tiers[change.CustomerID] = c.tiers.Current(change.Tier).Name
+		}
+		var evaluated = make(map[int]bool)
+		evaluate := func(activity customer.MembershipActivity) {
+			evaluated[activity.CustomerID] = true
+			previous, ok := tiers[activity.CustomerID]
+			if !ok {
+				previous = c.tiers.Base().Name
+			}
+			tier := c.tiers.Qualify(activity.Spend, activity.Points)
+			if tier.Name == previous {
+				return
+			}
+			changes = append(changes, domain.CustomerTierChange{
+				CustomerID:   activity.CustomerID,
+				Tier:         tier.Name,
+				PreviousTier: previous,
+				Spend:        activity.Spend,
+				Points:       activity.Points,
+				CreatedAt:    now,
+			})
+		}
+
+		for _, activity := range activities {
+			evaluate(activity)
+		}
+		// customers above the lowest tier without sale over the period
+		for _, change := range current {
+			if !evaluated[change.CustomerID] {
+				evaluate(customer.MembershipActivity{CustomerID: change.CustomerID})
+			}
+		}
+		return c.membershipPgRepository.AppendTierChanges(ctx, changes)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(changes), nil
+}
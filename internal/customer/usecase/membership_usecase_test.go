@@ -0,0 +1,109 @@
+package usecase
+
+import (
+	"context"
+	"github.com/alpakih/point-of-sales/internal/customer"
+	"github.com/alpakih/point-of-sales/internal/customer/mocks"
+	"github.com/alpakih/point-of-sales/internal/domain"
+	dbMocks "github.com/alpakih/point-of-sales/pkg/database/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+	"testing"
+	"time"
+)
+
+var membershipTiers = customer.MembershipTiers{
+	{Name: "silver", Multiplier: 1},
+	{Name: "gold", MinSpend: 5000000, MinPoints: 500, Multiplier: 1.25, Discount: 5},
+	{Name: "platinum", MinSpend: 20000000, MinPoints: 2000, Multiplier: 1.5, Discount: 10},
+}
+
+func TestCustomerMembershipUseCase_EvaluateTiers(t *testing.T) {
+	mockCustomerRepository := new(mocks.PgRepository)
+	mockMembershipRepository := new(mocks.MembershipPgRepository)
+	mockTxManager := new(dbMocks.TxManager)
+	mockTxManager.On("WithinTransaction", mock.Anything, mock.Anything).Return(func(ctx context.Context, fn func(context.Context) error) error {
+		return fn(ctx)
+	})
+	now := time.Now()
+
+	mockMembershipRepository.On("FindActivities", mock.Anything, now.AddDate(-1, 0, 0), now, []int(nil)).Return([]customer.MembershipActivity{
+		{CustomerID: 1, Spend: 6000000, Points: 100},
+		{CustomerID: 2, Spend: 100000, Points: 2500},
+		{CustomerID: 3, Spend: 7000000, Points: 700},
+		{CustomerID: 4, Spend: 100000, Points: 10},
+	}, nil).Once()
+	mockMembershipRepository.On("FindCurrentTiers", mock.Anything, []int(nil)).Return([]domain.CustomerTierChange{
+		{CustomerID: 3, Tier: "gold"},
+		{CustomerID: 5, Tier: "platinum"},
+	}, nil).Once()
+	mockMembershipRepository.On("AppendTierChanges", mock.Anything, []domain.CustomerTierChange{
+		{CustomerID: 1, Tier: "gold", PreviousTier: "silver", Spend: 6000000, Points: 100, CreatedAt: now},
+		{CustomerID: 2, Tier: "platinum", PreviousTier: "silver", Spend: 100000, Points: 2500, CreatedAt: now},
+		{CustomerID: 5, Tier: "silver", PreviousTier: "platinum", CreatedAt: now},
+	}).Return(nil).Once()
+
+	u := NewCustomerMembershipUseCase(mockCustomerRepository, mockMembershipRepository, mockTxManager, membershipTiers)
+
+	changed, err := u.EvaluateTiers(context.TODO(), now)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, changed)
+	mockMembershipRepository.AssertExpectations(t)
+}
+
+func TestCustomerMembershipUseCase_GetMembership(t *testing.T) {
+	mockCustomerRepository := new(mocks.PgRepository)
+	mockMembershipRepository := new(mocks.MembershipPgRepository)
+	mockTxManager := new(dbMocks.TxManager)
+
+	t.Run("success", func(t *testing.T) {
+		mockCustomerRepository.On("FindOneCustomerByID", mock.Anything, 1).Return(domain.Customer{ID: 1}, nil).Once()
+		mockMembershipRepository.On("FindTierChanges", mock.Anything, 1).Return([]domain.CustomerTierChange{
+			{CustomerID: 1, Tier: "gold", PreviousTier: "platinum"},
+			{CustomerID: 1, Tier: "platinum", PreviousTier: "silver"},
+		}, nil).Once()
+		mockMembershipRepository.On("FindActivities", mock.Anything, mock.Anything, mock.Anything, []int{1}).Return([]customer.MembershipActivity{
+			{CustomerID: 1, Spend: 6000000, Points: 600},
+		}, nil).Once()
+
+		u := NewCustomerMembershipUseCase(mockCustomerRepository, mockMembershipRepository, mockTxManager, membershipTiers)
+
+		data, err := u.GetMembership(context.TODO(), 1)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "gold", data.Tier)
+		assert.Equal(t, 5.0, data.Discount)
+		assert.Equal(t, int64(6000000), data.Spend)
+		assert.Len(t, data.History, 2)
+		mockMembershipRepository.AssertExpectations(t)
+	})
+
+	t.Run("lowest-tier", func(t *testing.T) {
+		mockCustomerRepository.On("FindOneCustomerByID", mock.Anything, 2).Return(domain.Customer{ID: 2}, nil).Once()
+		mockMembershipRepository.On("FindTierChanges", mock.Anything, 2).Return([]domain.CustomerTierChange{}, nil).Once()
+		mockMembershipRepository.On("FindActivities", mock.Anything, mock.Anything, mock.Anything, []int{2}).Return([]customer.MembershipActivity{}, nil).Once()
+
+		u := NewCustomerMembershipUseCase(mockCustomerRepository, mockMembershipRepository, mockTxManager, membershipTiers)
+
+		data, err := u.GetMembership(context.TODO(), 2)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "silver", data.Tier)
+		assert.Equal(t, 1.0, data.Multiplier)
+		assert.Empty(t, data.History)
+		mockMembershipRepository.AssertExpectations(t)
+	})
+
+	t.Run("customer-not-found", func(t *testing.T) {
+		mockCustomerRepository.On("FindOneCustomerByID", mock.Anything, 3).Return(domain.Customer{}, gorm.ErrRecordNotFound).Once()
+
+		u := NewCustomerMembershipUseCase(mockCustomerRepository, mockMembershipRepository, mockTxManager, membershipTiers)
+
+		_, err := u.GetMembership(context.TODO(), 3)
+
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+		mockCustomerRepository.AssertExpectations(t)
+	})
+}
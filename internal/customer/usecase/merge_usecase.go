@@ -0,0 +1,103 @@
+package usecase
+
+import (
+	"context"
+	"github.com/alpakih/point-of-sales/internal/constant"
+	"github.com/alpakih/point-of-sales/internal/customer"
+	"github.com/alpakih/point-of-sales/internal/domain"
+	"github.com/alpakih/point-of-sales/pkg/phone"
+	"github.com/alpakih/point-of-sales/pkg/utils"
+	"sort"
+	"strings"
+)
+
+const (
+	// maxDuplicateCandidates number of customers compared by FindDuplicateCandidates.
+	maxDuplicateCandidates = 100
+	// duplicateNameSimilarity similarity from which two names are considered the same.
+	duplicateNameSimilarity = 0.8
+)
+
+// FindDuplicateCandidates returns the other customers which may be the same
+// person: same email ignoring case, same normalized mobile phone or a similar
+// name. Candidates matching on more criteria come first.
+func (c customerUseCase) FindDuplicateCandidates(ctx context.Context, id int) ([]customer.DuplicateCandidate, error) {
+	entity, err := c.pgRepository.FindOneCustomerByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	entities, err := c.pgRepository.FindDuplicateCandidates(ctx, entity, maxDuplicateCandidates)
+	if err != nil {
+		return nil, err
+	}
+
+	mapper := customer.NewCustomerMapper()
+	var candidates = make([]customer.DuplicateCandidate, 0, len(entities))
+	for _, v := range entities {
+		candidate := customer.DuplicateCandidate{
+			Customer:       mapper.ToCustomerResponse(v),
+			Reasons:        []string{},
+			NameSimilarity: utils.Similarity(utils.NormalizeName(entity.Name), utils.NormalizeName(v.Name)),
+		}
+		if strings.EqualFold(strings.TrimSpace(entity.Email), strings.TrimSpace(v.Email)) {
+			candidate.Reasons = append(candidate.Reasons, customer.DuplicateReasonEmail)
+		}
+		if samePhone(entity.MobilePhone, v.MobilePhone) {
+			candidate.Reasons = append(candidate.Reasons, customer.DuplicateReasonMobilePhone)
+		}
+		if candidate.NameSimilarity >= duplicateNameSimilarity {
+			candidate.Reasons = append(candidate.Reasons, customer.DuplicateReasonName)
+		}
+		if len(candidate.Reasons) > 0 {
+			candidates = append(candidates, candidate)
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if len(candidates[i].Reasons) != len(candidates[j].Reasons) {
+			return len(candidates[i].Reasons) > len(candidates[j].Reasons)
+		}
+		return candidates[i].NameSimilarity > candidates[j].NameSimilarity
+	})
+	return candidates, nil
+}
+
+// MergeCustomers merges the customers of the request into the survivor in one
+// transaction and returns the survivor.
+func (c customerUseCase) MergeCustomers(ctx context.Context, request customer.MergeRequest) (*customer.Response, error) {
+	for _, id := range request.MergedIDs {
+		if id == request.SurvivorID {
+			return nil, constant.ErrMergeIntoItself
+		}
+	}
+
+	var survivor domain.Customer
+	err := c.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		if survivor, err = c.pgRepository.FindOneCustomerByID(ctx, request.SurvivorID); err != nil {
+			return err
+		}
+
+		var merged = make([]domain.Customer, len(request.MergedIDs))
+		for k, id := range request.MergedIDs {
+			if merged[k], err = c.pgRepository.FindOneCustomerByID(ctx, id); err != nil {
+				return err
+			}
+		}
+
+		return c.pgRepository.Merge(ctx, survivor, merged)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	survivor.Version++
+	response := customer.NewCustomerMapper().ToCustomerResponse(survivor)
+	return &response, nil
+}
+
+// samePhone compares two mobile phones in their E.164 form.
+func samePhone(a, b string) bool {
+	return a != "" && phone.Format(a) == phone.Format(b)
+}
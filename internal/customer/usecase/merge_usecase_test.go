@@ -0,0 +1,90 @@
+package usecase
+
+import (
+	"context"
+	"github.com/alpakih/point-of-sales/internal/constant"
+	"github.com/alpakih/point-of-sales/internal/customer"
+	"github.com/alpakih/point-of-sales/internal/customer/mocks"
+	"github.com/alpakih/point-of-sales/internal/domain"
+	dbMocks "github.com/alpakih/point-of-sales/pkg/database/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+	"testing"
+)
+
+func TestCustomerUseCase_FindDuplicateCandidates(t *testing.T) {
+	mockCustomerRepository := new(mocks.PgRepository)
+	mockTxManager := new(dbMocks.TxManager)
+	john := domain.Customer{ID: 1, Name: "John  Smith", Email: "john@test.com", MobilePhone: "087766777001", Version: 1}
+
+	mockCustomerRepository.On("FindOneCustomerByID", mock.Anything, 1).Return(john, nil).Once()
+	mockCustomerRepository.On("FindDuplicateCandidates", mock.Anything, john, maxDuplicateCandidates).Return([]domain.Customer{
+		{ID: 2, Name: "Johnny", Email: "johnny@test.com", MobilePhone: "+62 877-6677-7001", Version: 1},
+		{ID: 3, Name: "Jhon Smith", Email: "JOHN@test.com", MobilePhone: "087766777003", Version: 1},
+		{ID: 4, Name: "Mary Smith", Email: "mary@test.com", MobilePhone: "087766777004", Version: 1},
+		{ID: 5, Name: "john smith", Email: "smith@test.com", MobilePhone: "087766777005", Version: 1},
+	}, nil).Once()
+
+	u := NewCustomerUseCase(mockCustomerRepository, mockTxManager)
+
+	candidates, err := u.FindDuplicateCandidates(context.TODO(), 1)
+
+	assert.NoError(t, err)
+	var ids []int
+	for _, candidate := range candidates {
+		ids = append(ids, candidate.Customer.ID)
+	}
+	assert.Equal(t, []int{3, 5, 2}, ids)
+	assert.Equal(t, []string{customer.DuplicateReasonEmail, customer.DuplicateReasonName}, candidates[0].Reasons)
+	assert.Equal(t, []string{customer.DuplicateReasonName}, candidates[1].Reasons)
+	assert.Equal(t, 1.0, candidates[1].NameSimilarity)
+	assert.Equal(t, []string{customer.DuplicateReasonMobilePhone}, candidates[2].Reasons)
+	mockCustomerRepository.AssertExpectations(t)
+}
+
+func TestCustomerUseCase_MergeCustomers(t *testing.T) {
+	mockCustomerRepository := new(mocks.PgRepository)
+	mockTxManager := new(dbMocks.TxManager)
+	mockTxManager.On("WithinTransaction", mock.Anything, mock.Anything).Return(func(ctx context.Context, fn func(context.Context) error) error {
+		return fn(ctx)
+	})
+	survivor := domain.Customer{ID: 1, Name: "John Smith", Email: "john@test.com", MobilePhone: "087766777001", Version: 3}
+	merged := domain.Customer{ID: 2, Name: "Jhon Smith", Email: "jhon@test.com", MobilePhone: "087766777002", Version: 1}
+
+	t.Run("success", func(t *testing.T) {
+		mockCustomerRepository.On("FindOneCustomerByID", mock.Anything, 1).Return(survivor, nil).Once()
+		mockCustomerRepository.On("FindOneCustomerByID", mock.Anything, 2).Return(merged, nil).Once()
+		mockCustomerRepository.On("Merge", mock.Anything, survivor, []domain.Customer{merged}).Return(nil).Once()
+
+		u := NewCustomerUseCase(mockCustomerRepository, mockTxManager)
+
+		data, err := u.MergeCustomers(context.TODO(), customer.MergeRequest{SurvivorID: 1, MergedIDs: []int{2}})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, data.ID)
+		assert.Equal(t, 4, data.Version)
+		mockCustomerRepository.AssertExpectations(t)
+	})
+
+	t.Run("into-itself", func(t *testing.T) {
+		u := NewCustomerUseCase(mockCustomerRepository, mockTxManager)
+
+		_, err := u.MergeCustomers(context.TODO(), customer.MergeRequest{SurvivorID: 1, MergedIDs: []int{2, 1}})
+
+		assert.ErrorIs(t, err, constant.ErrMergeIntoItself)
+		mockCustomerRepository.AssertExpectations(t)
+	})
+
+	t.Run("merged-not-found", func(t *testing.T) {
+		mockCustomerRepository.On("FindOneCustomerByID", mock.Anything, 1).Return(survivor, nil).Once()
+		mockCustomerRepository.On("FindOneCustomerByID", mock.Anything, 3).Return(domain.Customer{}, gorm.ErrRecordNotFound).Once()
+
+		u := NewCustomerUseCase(mockCustomerRepository, mockTxManager)
+
+		_, err := u.MergeCustomers(context.TODO(), customer.MergeRequest{SurvivorID: 1, MergedIDs: []int{3}})
+
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+		mockCustomerRepository.AssertExpectations(t)
+	})
+}
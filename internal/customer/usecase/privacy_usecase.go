@@ -0,0 +1,59 @@
+package usecase
+
+import (
+	"context"
+	"github.com/alpakih/point-of-sales/internal/customer"
+	"github.com/alpakih/point-of-sales/internal/domain"
+	"github.com/alpakih/point-of-sales/pkg/database"
+	"time"
+)
+
+type customerPrivacyUseCase struct {
+	privacyPgRepository customer.PrivacyPgRepository
+	txManager           database.TxManager
+}
+
+func NewCustomerPrivacyUseCase(privacyPgRepository customer.PrivacyPgRepository, txManager database.TxManager) customer.PrivacyUseCase {
+	return &customerPrivacyUseCase{
+		privacyPgRepository: privacyPgRepository,
+		txManager:           txManager,
+	}
+}
+
+// ExportPersonalData returns everything stored about the customer, soft-deleted
+// or erased customers included. gorm.ErrRecordNotFound is returned when it
+// doesn't exist.
+func (c customerPrivacyUseCase) ExportPersonalData(ctx context.Context, customerID int) (*customer.DataExport, error) {
+	data, err := c.privacyPgRepository.FindPersonalData(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+	result := customer.NewCustomerMapper().ToDataExport(data, time.Now())
+	return &result, nil
+}
+
+// ErasePersonalData anonymizes the personal data of the customer and records
+// who erased it, its sales and ledgers are kept for accounting.
+// gorm.ErrRecordNotFound is returned when it doesn't exist.
+func (c customerPrivacyUseCase) ErasePersonalData(ctx context.Context, customerID int, request customer.ErasureRequest) (*customer.ErasureResponse, error) {
+	var erasure = domain.CustomerErasure{
+		CustomerID:   customerID,
+		PerformedBy:  request.PerformedBy,
+		AuthorizedBy: request.AuthorizedBy,
+		Reason:       request.Reason,
+		CreatedAt:    time.Now(),
+	}
+
+	err := c.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := c.privacyPgRepository.LockCustomer(ctx, customerID); err != nil {
+			return err
+		}
+		return c.privacyPgRepository.Erase(ctx, &erasure)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := customer.NewCustomerMapper().ToErasureResponse(erasure)
+	return &result, nil
+}
@@ -0,0 +1,108 @@
+package usecase
+
+import (
+	"context"
+	"github.com/alpakih/point-of-sales/internal/customer"
+	"github.com/alpakih/point-of-sales/internal/customer/mocks"
+	"github.com/alpakih/point-of-sales/internal/domain"
+	dbMocks "github.com/alpakih/point-of-sales/pkg/database/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+	"testing"
+)
+
+func TestCustomerPrivacyUseCase_ExportPersonalData(t *testing.T) {
+	mockPrivacyRepository := new(mocks.PrivacyPgRepository)
+	mockTxManager := new(dbMocks.TxManager)
+
+	t.Run("success", func(t *testing.T) {
+		earnID := 1
+		mockPrivacyRepository.On("FindPersonalData", mock.Anything, 1).Return(customer.PersonalData{
+			Customer: domain.Customer{ID: 1, Name: "name", Email: "email@test.com", Password: "password",
+				Addresses: []domain.CustomerAddress{{ID: 1, CustomerID: 1, Label: "home"}}},
+			LoyaltyEntries: []domain.LoyaltyEntry{
+				{ID: 1, Type: domain.LoyaltyEntryEarn, Points: 5, Amount: 50000, Reference: "S-1"},
+				{ID: 2, Type: domain.LoyaltyEntryEarn, Points: 2, Amount: 20000, Reference: "S-2",
+					Lines: []domain.LoyaltyEntryLine{{Product: "Kopi Susu", Quantity: 1, Amount: 20000}}},
+				{ID: 3, Type: domain.LoyaltyEntryReversal, Points: -5, Amount: -50000, Reference: "S-1", RelatedEntryID: &earnID},
+			},
+			WalletEntries: []domain.WalletEntry{{ID: 1, Type: domain.WalletEntryTopUp, Amount: 10000, Balance: 10000}},
+			Segments:      []domain.CustomerSegment{{ID: 1, Name: "vip"}},
+			Consents: []domain.CustomerConsent{
+				{ID: 1, Channel: domain.ConsentChannelSMS, Granted: true, Source: domain.ConsentSourcePOS},
+				{ID: 2, Channel: domain.ConsentChannelSMS, Granted: false, Source: domain.ConsentSourceWeb},
+			},
+			Merges: []domain.CustomerMerge{{SurvivorID: 1, MergedID: 2, Email: "other@test.com"}},
+		}, nil).Once()
+
+		u := NewCustomerPrivacyUseCase(mockPrivacyRepository, mockTxManager)
+
+		data, err := u.ExportPersonalData(context.TODO(), 1)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "email@test.com", data.Profile.Email)
+		assert.Len(t, *data.Profile.Addresses, 1)
+		assert.Len(t, data.Sales, 2)
+		assert.Equal(t, "S-2", data.Sales[0].Reference)
+		assert.Len(t, data.Loyalty, 3)
+		assert.Equal(t, int64(10000), data.Wallet.Balance)
+		assert.Equal(t, []string{"vip"}, data.Segments)
+		assert.Len(t, data.Consents.History, 2)
+		assert.False(t, data.Consents.Consents[1].Granted)
+		assert.Equal(t, domain.ConsentSourceWeb, data.Consents.Consents[1].Source)
+		assert.Equal(t, 2, data.Merges[0].MergedID)
+		assert.Len(t, data.Files(), 9)
+		mockPrivacyRepository.AssertExpectations(t)
+	})
+
+	t.Run("not-found", func(t *testing.T) {
+		mockPrivacyRepository.On("FindPersonalData", mock.Anything, 2).Return(customer.PersonalData{}, gorm.ErrRecordNotFound).Once()
+
+		u := NewCustomerPrivacyUseCase(mockPrivacyRepository, mockTxManager)
+
+		_, err := u.ExportPersonalData(context.TODO(), 2)
+
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	})
+}
+
+func TestCustomerPrivacyUseCase_ErasePersonalData(t *testing.T) {
+	mockPrivacyRepository := new(mocks.PrivacyPgRepository)
+	mockTxManager := new(dbMocks.TxManager)
+	mockTxManager.On("WithinTransaction", mock.Anything, mock.Anything).Return(func(ctx context.Context, fn func(context.Context) error) error {
+		return fn(ctx)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		mockPrivacyRepository.On("LockCustomer", mock.Anything, 1).Return(nil).Once()
+		mockPrivacyRepository.On("Erase", mock.Anything, mock.MatchedBy(func(erasure *domain.CustomerErasure) bool {
+			return erasure.CustomerID == 1 && erasure.PerformedBy == "A-01" && erasure.AuthorizedBy == "key-0123456789ab" && erasure.Reason == "data subject request" && !erasure.CreatedAt.IsZero()
+		})).Return(func(ctx context.Context, erasure *domain.CustomerErasure) error {
+			erasure.ID = 10
+			return nil
+		}).Once()
+
+		u := NewCustomerPrivacyUseCase(mockPrivacyRepository, mockTxManager)
+
+		data, err := u.ErasePersonalData(context.TODO(), 1, customer.ErasureRequest{PerformedBy: "A-01", Reason: "data subject request",
+			AuthorizedBy: "key-0123456789ab"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 10, data.ID)
+		assert.Equal(t, "A-01", data.PerformedBy)
+		assert.Equal(t, "key-0123456789ab", data.AuthorizedBy)
+		mockPrivacyRepository.AssertExpectations(t)
+	})
+
+	t.Run("not-found", func(t *testing.T) {
+		mockPrivacyRepository.On("LockCustomer", mock.Anything, 2).Return(gorm.ErrRecordNotFound).Once()
+
+		u := NewCustomerPrivacyUseCase(mockPrivacyRepository, mockTxManager)
+
+		_, err := u.ErasePersonalData(context.TODO(), 2, customer.ErasureRequest{PerformedBy: "A-01"})
+
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+		mockPrivacyRepository.AssertNumberOfCalls(t, "Erase", 1)
+	})
+}
@@ -0,0 +1,83 @@
+package usecase
+
+import (
+	"context"
+	"github.com/alpakih/point-of-sales/internal/customer"
+	"github.com/alpakih/point-of-sales/pkg/utils"
+	"time"
+)
+
+// DefaultLifespanYears number of years a customer is expected to keep buying.
+const DefaultLifespanYears = 3
+
+// favouriteProductsLimit number of favourite products of the summary.
+const favouriteProductsLimit = 5
+
+type customerSaleUseCase struct {
+	pgRepository     customer.PgRepository
+	salePgRepository customer.SalePgRepository
+	lifespanYears    int
+}
+
+func NewCustomerSaleUseCase(pgRepository customer.PgRepository, salePgRepository customer.SalePgRepository, lifespanYears int) customer.SaleUseCase {
+	return &customerSaleUseCase{
+		pgRepository:     pgRepository,
+		salePgRepository: salePgRepository,
+		lifespanYears:    lifespanYears,
+	}
+}
+
+// GetSales returns the page of the sales of the customer, the latest first,
+// gorm.ErrRecordNotFound is returned when it doesn't exist.
+func (c customerSaleUseCase) GetSales(ctx context.Context, customerID int, query utils.PaginationQuery) (*customer.SalePaginationResponse, error) {
+	if _, err := c.pgRepository.FindOneCustomerByID(ctx, customerID); err != nil {
+		return nil, err
+	}
+
+	paginator, err := c.salePgRepository.FindSales(ctx, customerID, query)
+	if err != nil {
+		return nil, err
+	}
+
+	pagination := customer.NewCustomerMapper().ToSalePaginationResponse(paginator)
+	return &pagination, nil
+}
+
+// GetSummary returns the purchase summary of the customer. The lifetime value is
+// the average basket times the number of sales a year, counted over a year at
+// least since the first sale, times the lifespan of a customer.
+func (c customerSaleUseCase) GetSummary(ctx context.Context, customerID int) (*customer.SummaryResponse, error) {
+	if _, err := c.pgRepository.FindOneCustomerByID(ctx, customerID); err != nil {
+		return nil, err
+	}
+
+	summary, err := c.salePgRepository.FindSaleSummary(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+	var result = customer.SummaryResponse{
+		Visits:            summary.Visits,
+		TotalSpend:        summary.Spend,
+		LastVisit:         summary.LastVisit,
+		FavouriteProducts: []customer.FavouriteProduct{},
+	}
+	if summary.Visits == 0 {
+		return &result, nil
+	}
+
+	products, err := c.salePgRepository.FindFavouriteProducts(ctx, customerID, favouriteProductsLimit)
+	if err != nil {
+		return nil, err
+	}
+	if len(products) > 0 {
+		result.FavouriteProducts = products
+	}
+
+	result.AverageBasket = summary.Spend / int64(summary.Visits)
+	years := time.Since(*summary.FirstVisit).Hours() / 24 / 365
+	if years < 1 {
+		years = 1
+	}
+	result.LifetimeValue = int64(float64(result.AverageBasket) * float64(summary.Visits) / years * float64(c.lifespanYears))
+	return &result, nil
+}
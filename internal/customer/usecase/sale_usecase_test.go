@@ -0,0 +1,96 @@
+package usecase
+
+import (
+	"context"
+	"github.com/alpakih/point-of-sales/internal/customer"
+	"github.com/alpakih/point-of-sales/internal/customer/mocks"
+	"github.com/alpakih/point-of-sales/internal/domain"
+	"github.com/alpakih/point-of-sales/pkg/database"
+	"github.com/alpakih/point-of-sales/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+	"testing"
+	"time"
+)
+
+func TestCustomerSaleUseCase_GetSales(t *testing.T) {
+	mockCustomerRepository := new(mocks.PgRepository)
+	mockSaleRepository := new(mocks.SalePgRepository)
+
+	t.Run("success", func(t *testing.T) {
+		query := utils.PaginationQuery{Page: 1, Size: 10}
+		mockCustomerRepository.On("FindOneCustomerByID", mock.Anything, 1).Return(domain.Customer{ID: 1}, nil).Once()
+		mockSaleRepository.On("FindSales", mock.Anything, 1, query).Return(&database.Paginator{
+			Records: &[]domain.LoyaltyEntry{{ID: 3, Type: domain.LoyaltyEntryEarn, Amount: 50000, Reference: "S-1", Points: 5,
+				Lines:          []domain.LoyaltyEntryLine{{Product: "Kopi Susu", Quantity: 2, Amount: 50000}},
+				RelatedEntries: []domain.LoyaltyEntry{{Type: domain.LoyaltyEntryReversal, Amount: -50000}},
+			}},
+			Total: 1, PageSize: 10, CurrentPage: 1, MaxPage: 1,
+		}, nil).Once()
+
+		u := NewCustomerSaleUseCase(mockCustomerRepository, mockSaleRepository, DefaultLifespanYears)
+
+		data, err := u.GetSales(context.TODO(), 1, query)
+
+		assert.NoError(t, err)
+		assert.Len(t, data.Data, 1)
+		assert.Equal(t, int64(50000), data.Data[0].RefundedAmount)
+		assert.Equal(t, "Kopi Susu", data.Data[0].Lines[0].Product)
+		mockSaleRepository.AssertExpectations(t)
+	})
+
+	t.Run("not-found", func(t *testing.T) {
+		mockCustomerRepository.On("FindOneCustomerByID", mock.Anything, 2).Return(domain.Customer{}, gorm.ErrRecordNotFound).Once()
+
+		u := NewCustomerSaleUseCase(mockCustomerRepository, mockSaleRepository, DefaultLifespanYears)
+
+		_, err := u.GetSales(context.TODO(), 2, utils.PaginationQuery{})
+
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	})
+}
+
+func TestCustomerSaleUseCase_GetSummary(t *testing.T) {
+	mockCustomerRepository := new(mocks.PgRepository)
+	mockSaleRepository := new(mocks.SalePgRepository)
+
+	t.Run("success", func(t *testing.T) {
+		firstVisit := time.Now().AddDate(-2, 0, 0)
+		lastVisit := time.Now().AddDate(0, 0, -1)
+		products := []customer.FavouriteProduct{{Product: "Kopi Susu", Quantity: 12, Amount: 240000}}
+		mockCustomerRepository.On("FindOneCustomerByID", mock.Anything, 1).Return(domain.Customer{ID: 1}, nil).Once()
+		mockSaleRepository.On("FindSaleSummary", mock.Anything, 1).Return(customer.SaleSummary{
+			Visits: 20, Spend: 1000000, FirstVisit: &firstVisit, LastVisit: &lastVisit,
+		}, nil).Once()
+		mockSaleRepository.On("FindFavouriteProducts", mock.Anything, 1, favouriteProductsLimit).Return(products, nil).Once()
+
+		u := NewCustomerSaleUseCase(mockCustomerRepository, mockSaleRepository, DefaultLifespanYears)
+
+		data, err := u.GetSummary(context.TODO(), 1)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 20, data.Visits)
+		assert.Equal(t, int64(50000), data.AverageBasket)
+		assert.Equal(t, &lastVisit, data.LastVisit)
+		assert.Equal(t, products, data.FavouriteProducts)
+		// 10 sales a year over 3 years
+		assert.InDelta(t, 1500000, data.LifetimeValue, 5000)
+		mockSaleRepository.AssertExpectations(t)
+	})
+
+	t.Run("no-sale", func(t *testing.T) {
+		mockCustomerRepository.On("FindOneCustomerByID", mock.Anything, 2).Return(domain.Customer{ID: 2}, nil).Once()
+		mockSaleRepository.On("FindSaleSummary", mock.Anything, 2).Return(customer.SaleSummary{}, nil).Once()
+
+		u := NewCustomerSaleUseCase(mockCustomerRepository, mockSaleRepository, DefaultLifespanYears)
+
+		data, err := u.GetSummary(context.TODO(), 2)
+
+		assert.NoError(t, err)
+		assert.Zero(t, data.LifetimeValue)
+		assert.Nil(t, data.LastVisit)
+		assert.Empty(t, data.FavouriteProducts)
+		mockSaleRepository.AssertNotCalled(t, "FindFavouriteProducts", mock.Anything, 2, mock.Anything)
+	})
+}
@@ -0,0 +1,256 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"github.com/alpakih/point-of-sales/internal/constant"
+	"github.com/alpakih/point-of-sales/internal/customer"
+	"github.com/alpakih/point-of-sales/internal/domain"
+	"github.com/alpakih/point-of-sales/pkg/database"
+	"gorm.io/gorm"
+	"time"
+)
+
+// segmentBatchSize number of customers a dynamic segment is evaluated on at once.
+const segmentBatchSize = 500
+
+type customerSegmentUseCase struct {
+	pgRepository           customer.PgRepository
+	segmentPgRepository    customer.SegmentPgRepository
+	membershipPgRepository customer.MembershipPgRepository
+	txManager              database.TxManager
+	tiers                  customer.MembershipTiers
+}
+
+func NewCustomerSegmentUseCase(pgRepository customer.PgRepository, segmentPgRepository customer.SegmentPgRepository,
+	membershipPgRepository customer.MembershipPgRepository, txManager database.TxManager, tiers customer.MembershipTiers) customer.SegmentUseCase {
+	return &customerSegmentUseCase{
+		pgRepository:           pgRepository,
+		segmentPgRepository:    segmentPgRepository,
+		membershipPgRepository: membershipPgRepository,
+		txManager:              txManager,
+		tiers:                  tiers,
+	}
+}
+
+// StoreSegment creates a segment, the rule of a dynamic segment is parsed and a
+// *customer.SegmentRuleError returned when it is invalid. A dynamic segment has
+// no member until it is evaluated.
+func (c customerSegmentUseCase) StoreSegment(ctx context.Context, request customer.SegmentRequest) (*customer.SegmentResponse, error) {
+	mapper := customer.NewCustomerMapper()
+	if err := validateSegmentRule(request); err != nil {
+		return nil, err
+	}
+
+	var entity = mapper.SegmentRequestToEntity(request, 0)
+	if err := c.segmentPgRepository.Create(ctx, &entity); err != nil {
+		return nil, err
+	}
+	result := mapper.ToSegmentResponse(entity)
+	return &result, nil
+}
+
+// UpdateSegment replaces a segment, the members of a dynamic segment are those
+// of its previous rule until it is evaluated again.
+func (c customerSegmentUseCase) UpdateSegment(ctx context.Context, id int, request customer.SegmentRequest) (*customer.SegmentResponse, error) {
+	if err := validateSegmentRule(request); err != nil {
+		return nil, err
+	}
+	if err := c.segmentPgRepository.Update(ctx, customer.NewCustomerMapper().SegmentRequestToEntity(request, id)); err != nil {
+		return nil, err
+	}
+	return c.GetSegmentByID(ctx, id)
+}
+
+func validateSegmentRule(request customer.SegmentRequest) error {
+	if request.Type != domain.CustomerSegmentDynamic {
+		return nil
+	}
+	_, err := customer.ParseSegmentRule(request.Rule)
+	return err
+}
+
+func (c customerSegmentUseCase) GetSegmentByID(ctx context.Context, id int) (*customer.SegmentResponse, error) {
+	data, err := c.segmentPgRepository.FindSegmentByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	result := customer.NewCustomerMapper().ToSegmentResponse(data)
+	return &result, nil
+}
+
+func (c customerSegmentUseCase) GetSegments(ctx context.Context) ([]customer.SegmentResponse, error) {
+	data, err := c.segmentPgRepository.FindSegments(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	mapper := customer.NewCustomerMapper()
+	var result = make([]customer.SegmentResponse, len(data))
+	for k, v := range data {
+		result[k] = mapper.ToSegmentResponse(v)
+	}
+	return result, nil
+}
+
+func (c customerSegmentUseCase) DeleteSegment(ctx context.Context, id int) error {
+	return c.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		return c.segmentPgRepository.Delete(ctx, id)
+	})
+}
+
+// AddSegmentMembers adds active customers to a static segment,
+// constant.ErrSegmentNotStatic is returned for a dynamic segment and
+// gorm.ErrRecordNotFound when a customer doesn't exist.
+func (c customerSegmentUseCase) AddSegmentMembers(ctx context.Context, id int, request customer.SegmentMembersRequest) (*customer.SegmentResponse, error) {
+	segment, err := c.segmentPgRepository.FindSegmentByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if segment.Type != domain.CustomerSegmentStatic {
+		return nil, constant.ErrSegmentNotStatic
+	}
+	for _, customerID := range request.CustomerIDs {
+		if _, err := c.pgRepository.FindOneCustomerByID(ctx, customerID); err != nil {
+			return nil, err
+		}
+	}
+	if err := c.segmentPgRepository.AddMembers(ctx, id, request.CustomerIDs); err != nil {
+		return nil, err
+	}
+	return c.GetSegmentByID(ctx, id)
+}
+
+// RemoveSegmentMember removes a customer from a static segment,
+// constant.ErrSegmentNotStatic is returned for a dynamic segment.
+func (c customerSegmentUseCase) RemoveSegmentMember(ctx context.Context, id, customerID int) error {
+	segment, err := c.segmentPgRepository.FindSegmentByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if segment.Type != domain.CustomerSegmentStatic {
+		return constant.ErrSegmentNotStatic
+	}
+	return c.segmentPgRepository.RemoveMember(ctx, id, customerID)
+}
+
+// EvaluateSegment replaces the members of a dynamic segment by the active
+// customers matching its rule, constant.ErrSegmentNotDynamic is returned for a
+// static segment.
+func (c customerSegmentUseCase) EvaluateSegment(ctx context.Context, id int) (*customer.SegmentResponse, error) {
+	segment, err := c.segmentPgRepository.FindSegmentByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if segment.Type != domain.CustomerSegmentDynamic {
+		return nil, constant.ErrSegmentNotDynamic
+	}
+	if err := c.evaluate(ctx, segment, time.Now()); err != nil {
+		return nil, err
+	}
+	return c.GetSegmentByID(ctx, id)
+}
+
+// EvaluateSegments evaluates every dynamic segment and returns the number of
+// segments evaluated. The segments whose rule is no longer valid are skipped.
+func (c customerSegmentUseCase) EvaluateSegments(ctx context.Context) (int, error) {
+	segments, err := c.segmentPgRepository.FindSegments(ctx, domain.CustomerSegmentDynamic)
+	if err != nil {
+		return 0, err
+	}
+
+	var evaluated int
+	for _, segment := range segments {
+		err := c.evaluate(ctx, segment, time.Now())
+		var ruleError *customer.SegmentRuleError
+		if errors.As(err, &ruleError) {
+			continue
+		}
+		if err != nil {
+			return evaluated, err
+		}
+		evaluated++
+	}
+	return evaluated, nil
+}
+
+// evaluate evaluates the rule of the segment on the active customers by
+// batches and replaces its members.
+func (c customerSegmentUseCase) evaluate(ctx context.Context, segment domain.CustomerSegment, now time.Time) error {
+	rule, err := customer.ParseSegmentRule(segment.Rule)
+	if err != nil {
+		return err
+	}
+
+	var members []int
+	err = c.pgRepository.FindCustomersInBatches(ctx, customer.ListQuery{}, segmentBatchSize, func(entities []domain.Customer) error {
+		attributes, err := c.segmentAttributes(ctx, entities, rule.Windows(), now)
+		if err != nil {
+			return err
+		}
+		for _, v := range attributes {
+			if rule.Match(v, now) {
+				members = append(members, v.Customer.ID)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		err := c.segmentPgRepository.ReplaceMembers(ctx, segment.ID, members, now)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// deleted meanwhile
+			return nil
+		}
+		return err
+	})
+}
+
+// segmentAttributes reads the attributes of the customers a rule is evaluated
+// on, with the spend and visits of the windows in days.
+func (c customerSegmentUseCase) segmentAttributes(ctx context.Context, entities []domain.Customer, windows []int, now time.Time) ([]customer.SegmentAttributes, error) {
+	var ids = make([]int, len(entities))
+	var attributes = make([]customer.SegmentAttributes, len(entities))
+	var index = make(map[int]int, len(entities))
+	for k, v := range entities {
+		ids[k] = v.ID
+		index[v.ID] = k
+		attributes[k] = customer.SegmentAttributes{
+			Customer: v,
+			Tier:     c.tiers.Base().Name,
+			Spend:    make(map[int]int64),
+			Visits:   make(map[int]int),
+		}
+	}
+
+	tiers, err := c.membershipPgRepository.FindCurrentTiers(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range tiers {
+		attributes[index[v.CustomerID]].Tier = c.tiers.Current(v.Tier).Name
+	}
+
+	for _, window := range windows {
+		stats, err := c.segmentPgRepository.FindSaleStats(ctx, ids, now.AddDate(0, 0, -window), now)
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range stats {
+			attributes[index[v.CustomerID]].Spend[window] = v.Spend
+			attributes[index[v.CustomerID]].Visits[window] = v.Visits
+		}
+	}
+
+	visits, err := c.segmentPgRepository.FindLastVisits(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range visits {
+		createdAt := v.CreatedAt
+		attributes[index[v.CustomerID]].LastVisit = &createdAt
+	}
+	return attributes, nil
+}
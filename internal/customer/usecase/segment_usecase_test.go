@@ -0,0 +1,137 @@
+package usecase
+
+import (
+	"context"
+	"github.com/alpakih/point-of-sales/internal/constant"
+	"github.com/alpakih/point-of-sales/internal/customer"
+	"github.com/alpakih/point-of-sales/internal/customer/mocks"
+	"github.com/alpakih/point-of-sales/internal/domain"
+	dbMocks "github.com/alpakih/point-of-sales/pkg/database/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+	"time"
+)
+
+func TestCustomerSegmentUseCase_StoreSegment(t *testing.T) {
+	mockCustomerRepository := new(mocks.PgRepository)
+	mockSegmentRepository := new(mocks.SegmentPgRepository)
+	mockMembershipRepository := new(mocks.MembershipPgRepository)
+	mockTxManager := new(dbMocks.TxManager)
+
+	t.Run("success", func(t *testing.T) {
+		mockSegmentRepository.On("Create", mock.Anything, mock.AnythingOfType("*domain.CustomerSegment")).Return(func(ctx context.Context, entity *domain.CustomerSegment) error {
+			entity.ID = 1
+			return nil
+		}).Once()
+
+		u := NewCustomerSegmentUseCase(mockCustomerRepository, mockSegmentRepository, mockMembershipRepository, mockTxManager, membershipTiers)
+
+		data, err := u.StoreSegment(context.TODO(), customer.SegmentRequest{Name: "regulars", Type: domain.CustomerSegmentDynamic, Rule: "visits_30d >= 2"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, data.ID)
+		assert.Equal(t, "visits_30d >= 2", data.Rule)
+		mockSegmentRepository.AssertExpectations(t)
+	})
+
+	t.Run("invalid-rule", func(t *testing.T) {
+		u := NewCustomerSegmentUseCase(mockCustomerRepository, mockSegmentRepository, mockMembershipRepository, mockTxManager, membershipTiers)
+
+		_, err := u.StoreSegment(context.TODO(), customer.SegmentRequest{Name: "regulars", Type: domain.CustomerSegmentDynamic, Rule: "visits_30d >="})
+
+		var ruleError *customer.SegmentRuleError
+		assert.ErrorAs(t, err, &ruleError)
+		mockSegmentRepository.AssertNumberOfCalls(t, "Create", 1)
+	})
+}
+
+func TestCustomerSegmentUseCase_EvaluateSegment(t *testing.T) {
+	mockCustomerRepository := new(mocks.PgRepository)
+	mockSegmentRepository := new(mocks.SegmentPgRepository)
+	mockMembershipRepository := new(mocks.MembershipPgRepository)
+	mockTxManager := new(dbMocks.TxManager)
+	mockTxManager.On("WithinTransaction", mock.Anything, mock.Anything).Return(func(ctx context.Context, fn func(context.Context) error) error {
+		return fn(ctx)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		segment := domain.CustomerSegment{ID: 1, Name: "gold regulars", Type: domain.CustomerSegmentDynamic,
+			Rule: `tier = "gold" and (spend_30d >= 1jt or visits_30d >= 3) and last_visit_days <= 14`}
+		lastVisit := time.Now().AddDate(0, 0, -2)
+		mockSegmentRepository.On("FindSegmentByID", mock.Anything, 1).Return(segment, nil).Twice()
+		mockCustomerRepository.On("FindCustomersInBatches", mock.Anything, customer.ListQuery{}, segmentBatchSize, mock.Anything).Return(func(ctx context.Context, query customer.ListQuery, batchSize int, fn func([]domain.Customer) error) error {
+			return fn([]domain.Customer{{ID: 1, Name: "Alice"}, {ID: 2, Name: "Bob"}, {ID: 3, Name: "Carol"}, {ID: 4, Name: "Dave"}})
+		}).Once()
+		mockMembershipRepository.On("FindCurrentTiers", mock.Anything, []int{1, 2, 3, 4}).Return([]domain.CustomerTierChange{
+			{CustomerID: 1, Tier: "gold"},
+			{CustomerID: 2, Tier: "gold"},
+			{CustomerID: 4, Tier: "gold"},
+		}, nil).Once()
+		mockSegmentRepository.On("FindSaleStats", mock.Anything, []int{1, 2, 3, 4}, mock.Anything, mock.Anything).Return([]customer.SaleStats{
+			{CustomerID: 1, Spend: 1500000, Visits: 1},
+			{CustomerID: 2, Spend: 300000, Visits: 1},
+			{CustomerID: 3, Spend: 2000000, Visits: 4},
+			{CustomerID: 4, Spend: 200000, Visits: 3},
+		}, nil).Once()
+		mockSegmentRepository.On("FindLastVisits", mock.Anything, []int{1, 2, 3, 4}).Return([]domain.LoyaltyEntry{
+			{CustomerID: 1, CreatedAt: lastVisit},
+			{CustomerID: 2, CreatedAt: lastVisit},
+			{CustomerID: 3, CreatedAt: lastVisit},
+			{CustomerID: 4, CreatedAt: lastVisit},
+		}, nil).Once()
+		mockSegmentRepository.On("ReplaceMembers", mock.Anything, 1, []int{1, 4}, mock.AnythingOfType("time.Time")).Return(nil).Once()
+
+		u := NewCustomerSegmentUseCase(mockCustomerRepository, mockSegmentRepository, mockMembershipRepository, mockTxManager, membershipTiers)
+
+		_, err := u.EvaluateSegment(context.TODO(), 1)
+
+		assert.NoError(t, err)
+		mockSegmentRepository.AssertExpectations(t)
+		mockMembershipRepository.AssertExpectations(t)
+	})
+
+	t.Run("static", func(t *testing.T) {
+		mockSegmentRepository.On("FindSegmentByID", mock.Anything, 2).Return(domain.CustomerSegment{ID: 2, Type: domain.CustomerSegmentStatic}, nil).Once()
+
+		u := NewCustomerSegmentUseCase(mockCustomerRepository, mockSegmentRepository, mockMembershipRepository, mockTxManager, membershipTiers)
+
+		_, err := u.EvaluateSegment(context.TODO(), 2)
+
+		assert.ErrorIs(t, err, constant.ErrSegmentNotDynamic)
+	})
+}
+
+func TestCustomerSegmentUseCase_AddSegmentMembers(t *testing.T) {
+	mockCustomerRepository := new(mocks.PgRepository)
+	mockSegmentRepository := new(mocks.SegmentPgRepository)
+	mockMembershipRepository := new(mocks.MembershipPgRepository)
+	mockTxManager := new(dbMocks.TxManager)
+
+	t.Run("success", func(t *testing.T) {
+		segment := domain.CustomerSegment{ID: 1, Name: "vip", Type: domain.CustomerSegmentStatic}
+		mockSegmentRepository.On("FindSegmentByID", mock.Anything, 1).Return(segment, nil).Twice()
+		mockCustomerRepository.On("FindOneCustomerByID", mock.Anything, 1).Return(domain.Customer{ID: 1}, nil).Once()
+		mockCustomerRepository.On("FindOneCustomerByID", mock.Anything, 2).Return(domain.Customer{ID: 2}, nil).Once()
+		mockSegmentRepository.On("AddMembers", mock.Anything, 1, []int{1, 2}).Return(nil).Once()
+
+		u := NewCustomerSegmentUseCase(mockCustomerRepository, mockSegmentRepository, mockMembershipRepository, mockTxManager, membershipTiers)
+
+		data, err := u.AddSegmentMembers(context.TODO(), 1, customer.SegmentMembersRequest{CustomerIDs: []int{1, 2}})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "vip", data.Name)
+		mockSegmentRepository.AssertExpectations(t)
+	})
+
+	t.Run("dynamic", func(t *testing.T) {
+		mockSegmentRepository.On("FindSegmentByID", mock.Anything, 2).Return(domain.CustomerSegment{ID: 2, Type: domain.CustomerSegmentDynamic}, nil).Once()
+
+		u := NewCustomerSegmentUseCase(mockCustomerRepository, mockSegmentRepository, mockMembershipRepository, mockTxManager, membershipTiers)
+
+		_, err := u.AddSegmentMembers(context.TODO(), 2, customer.SegmentMembersRequest{CustomerIDs: []int{1}})
+
+		assert.ErrorIs(t, err, constant.ErrSegmentNotStatic)
+		mockSegmentRepository.AssertNotCalled(t, "AddMembers", mock.Anything, 2, mock.Anything)
+	})
+}
@@ -2,19 +2,31 @@ package usecase
 
 import (
 	"context"
+	"encoding/json"
 	"github.com/alpakih/point-of-sales/internal/constant"
 	"github.com/alpakih/point-of-sales/internal/customer"
+	"github.com/alpakih/point-of-sales/internal/domain"
+	"github.com/alpakih/point-of-sales/pkg/database"
+	"github.com/alpakih/point-of-sales/pkg/phone"
+	"github.com/alpakih/point-of-sales/pkg/utils"
+	"github.com/alpakih/point-of-sales/pkg/validator"
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 	"strings"
 )
 
+// exportBatchSize number of customers read at once by ExportCustomers.
+const exportBatchSize = 500
+
 type customerUseCase struct {
 	pgRepository customer.PgRepository
+	txManager    database.TxManager
 }
 
-func NewCustomerUseCase(pgRepository customer.PgRepository) customer.UseCase {
+func NewCustomerUseCase(pgRepository customer.PgRepository, txManager database.TxManager) customer.UseCase {
 	return &customerUseCase{
 		pgRepository: pgRepository,
+		txManager:    txManager,
 	}
 }
 
@@ -28,76 +40,138 @@ func (c customerUseCase) StoreCustomer(ctx context.Context, request customer.Sto
 		entity.Password = string(password)
 	}
 
-	// check duplicate email
-	if countEmail, err := c.pgRepository.CheckDuplicate(ctx, "email =?", entity.Email); err != nil {
-		return nil, err
-	} else {
-		if countEmail > 0 {
-			return nil, constant.ErrEmailAlreadyExist
+	err := c.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := checkDuplicates(ctx, c.pgRepository, entity.Email, entity.MobilePhone, 0); err != nil {
+			return err
 		}
+
+		return c.pgRepository.Create(ctx, &entity)
+	})
+
+	if err != nil {
+		return nil, err
 	}
 
-	// check duplicate mobile phone
-	countMobilePhone, err := c.pgRepository.CheckDuplicate(ctx, "mobile_phone =?", entity.MobilePhone)
+	result := customer.NewCustomerMapper().ToCustomerResponse(entity)
+
+	return &result, nil
+}
+
+func (c customerUseCase) UpdateCustomer(ctx context.Context, request customer.UpdateRequest, id, version int) (*customer.Response, error) {
+
+	// a lagging replica would resolve the version the client just read to a stale one
+	data, err := c.pgRepository.FindOneCustomerByID(database.WithPrimary(ctx), id)
 
 	if err != nil {
 		return nil, err
 	}
 
-	if countMobilePhone > 0 {
-		return nil, constant.ErrMobilePhoneAlreadyExist
+	if version == utils.AnyVersion {
+		version = data.Version
+	} else if data.Version != version {
+		return nil, constant.ErrVersionMismatch
+	}
+
+	var entity = customer.NewCustomerMapper().CustomerUpdateRequestToEntity(request, data.ID, version)
+
+	if !strings.EqualFold(entity.Password, "") {
+		password, err := bcrypt.GenerateFromPassword([]byte(entity.Password), bcrypt.DefaultCost)
+		if err != nil {
+			return nil, err
+		}
+		entity.Password = string(password)
 	}
 
-	if err := c.pgRepository.Create(ctx, &entity); err != nil {
+	err = c.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := checkDuplicates(ctx, c.pgRepository, entity.Email, entity.MobilePhone, id); err != nil {
+			return err
+		}
+
+		return c.pgRepository.Update(ctx, entity)
+	})
+
+	if err != nil {
 		return nil, err
 	}
 
+	entity.Version = version + 1
 	result := customer.NewCustomerMapper().ToCustomerResponse(entity)
 
 	return &result, nil
 }
 
-func (c customerUseCase) UpdateCustomer(ctx context.Context, request customer.UpdateRequest, id int) error {
+// PatchCustomer applies a JSON Merge Patch to the customer. Only the changed
+// columns are written and checked for duplicates, the patched document must pass
+// the validation of customer.PatchRequest.
+func (c customerUseCase) PatchCustomer(ctx context.Context, patch []byte, id, version int) (*customer.Response, error) {
 
-	data, err := c.pgRepository.FindOneCustomerByID(ctx, id)
+	data, err := c.pgRepository.FindOneCustomerByID(database.WithPrimary(ctx), id)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	var entity = customer.NewCustomerMapper().CustomerUpdateRequestToEntity(request, data.ID)
+	if version == utils.AnyVersion {
+		version = data.Version
+	} else if data.Version != version {
+		return nil, constant.ErrVersionMismatch
+	}
 
-	if !strings.EqualFold(entity.Password, "") {
+	mapper := customer.NewCustomerMapper()
+	document, err := json.Marshal(mapper.ToPatchRequest(data))
+	if err != nil {
+		return nil, err
+	}
+	if document, err = utils.MergePatch(document, patch); err != nil {
+		return nil, err
+	}
+	var request customer.PatchRequest
+	if err := json.Unmarshal(document, &request); err != nil {
+		return nil, err
+	}
+	if err := validator.Validate.ValidateStruct(request); err != nil {
+		return nil, err
+	}
+
+	entity, columns := mapper.CustomerPatchRequestToEntity(request, data)
+	if len(columns) == 0 {
+		result := mapper.ToCustomerResponse(data)
+		return &result, nil
+	}
+
+	if utils.ItemExists(columns, "password") {
 		password, err := bcrypt.GenerateFromPassword([]byte(entity.Password), bcrypt.DefaultCost)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		entity.Password = string(password)
 	}
 
-	// check duplicate email
-	if countEmail, err := c.pgRepository.CheckDuplicate(ctx, "email =? and id <> ?", entity.Email, id); err != nil {
-		return err
-	} else {
-		if countEmail > 0 {
-			return constant.ErrEmailAlreadyExist
+	err = c.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		if utils.ItemExists(columns, "email") || utils.ItemExists(columns, "mobile_phone") {
+			if err := checkDuplicates(ctx, c.pgRepository, entity.Email, entity.MobilePhone, id); err != nil {
+				return err
+			}
 		}
-	}
 
-	// check duplicate mobile phone
-	if countMobilePhone, err := c.pgRepository.CheckDuplicate(ctx, "mobile_phone =? and id <> ?", entity.MobilePhone, id); err != nil {
-		return err
-	} else {
-		if countMobilePhone > 0 {
-			return constant.ErrMobilePhoneAlreadyExist
-		}
+		return c.pgRepository.Update(ctx, entity, columns...)
+	})
+
+	if err != nil {
+		return nil, err
 	}
 
-	return c.pgRepository.Update(ctx, entity)
+	entity.Version = version + 1
+	result := mapper.ToCustomerResponse(entity)
+
+	return &result, nil
 }
 
-func (c customerUseCase) GetCustomerByID(ctx context.Context, id int) (*customer.Response, error) {
-	data, err := c.pgRepository.FindOneCustomerByID(ctx, id)
+func (c customerUseCase) GetCustomerByID(ctx context.Context, id int, fieldset utils.FieldsetQuery) (*customer.Response, error) {
+	if err := customer.ResponseFieldset.Validate(fieldset); err != nil {
+		return nil, err
+	}
+	data, err := c.pgRepository.FindOneCustomerByID(ctx, id, customer.ResponseFieldset.Preloads(fieldset)...)
 	if err != nil {
 		return nil, err
 	}
@@ -105,9 +179,12 @@ func (c customerUseCase) GetCustomerByID(ctx context.Context, id int) (*customer
 	return &result, nil
 }
 
-func (c customerUseCase) GetCustomers(ctx context.Context, page, size int, search, order string) (*customer.PaginationResponse, error) {
+func (c customerUseCase) GetCustomers(ctx context.Context, query customer.ListQuery) (*customer.PaginationResponse, error) {
+	if err := customer.ResponseFieldset.Validate(query.FieldsetQuery); err != nil {
+		return nil, err
+	}
 
-	paginator, err := c.pgRepository.FindCustomers(ctx, page, size, search, order)
+	paginator, err := c.pgRepository.FindCustomers(ctx, query)
 
 	if err != nil {
 		return nil, err
@@ -117,11 +194,89 @@ func (c customerUseCase) GetCustomers(ctx context.Context, page, size int, searc
 	return &pagination, nil
 }
 
+// ExportCustomers reads the customers of the listing query by batches of
+// exportBatchSize, the mapped batches are passed to fn as they are read.
+func (c customerUseCase) ExportCustomers(ctx context.Context, query customer.ListQuery, fn func([]customer.Response) error) error {
+	if err := customer.ResponseFieldset.Validate(query.FieldsetQuery); err != nil {
+		return err
+	}
+
+	mapper := customer.NewCustomerMapper()
+	return c.pgRepository.FindCustomersInBatches(ctx, query, exportBatchSize, func(entities []domain.Customer) error {
+		var data = make([]customer.Response, len(entities))
+		for k, v := range entities {
+			data[k] = mapper.ToCustomerResponse(v)
+		}
+		return fn(data)
+	})
+}
+
 func (c customerUseCase) DeleteCustomer(ctx context.Context, id int) error {
-	data, err := c.pgRepository.FindOneCustomerByID(ctx, id)
+	data, err := c.pgRepository.FindOneCustomerByID(database.WithPrimary(ctx), id)
 
 	if err != nil {
 		return err
 	}
 	return c.pgRepository.Delete(ctx, data.ID)
 }
+
+// RestoreCustomer restores a soft-deleted customer, its email and mobile phone
+// must not have been registered by another customer meanwhile.
+func (c customerUseCase) RestoreCustomer(ctx context.Context, id int) (*customer.Response, error) {
+	data, err := c.pgRepository.FindDeletedCustomerByID(database.WithPrimary(ctx), id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := checkDuplicates(ctx, c.pgRepository, data.Email, data.MobilePhone, id); err != nil {
+			return err
+		}
+
+		return c.pgRepository.Restore(ctx, id)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	data.Version++
+	data.DeletedAt = gorm.DeletedAt{}
+	result := customer.NewCustomerMapper().ToCustomerResponse(data)
+
+	return &result, nil
+}
+
+// PurgeCustomer permanently deletes the customer, soft-deleted or not.
+func (c customerUseCase) PurgeCustomer(ctx context.Context, id int) error {
+	return c.pgRepository.Purge(ctx, id)
+}
+
+// checkDuplicates applies the duplicate rules of the customer email and mobile
+// phone, the customer with the given id is excluded when it isn't zero. Mobile
+// phones are compared in their E.164 form.
+func checkDuplicates(ctx context.Context, pgRepository customer.PgRepository, email, mobilePhone string, id int) error {
+	mobilePhone = phone.Format(mobilePhone)
+	emailArgs := []interface{}{"email =?", email}
+	mobilePhoneArgs := []interface{}{"mobile_phone =?", mobilePhone}
+	if id != 0 {
+		emailArgs = []interface{}{"email =? and id <> ?", email, id}
+		mobilePhoneArgs = []interface{}{"mobile_phone =? and id <> ?", mobilePhone, id}
+	}
+
+	// check duplicate email
+	if countEmail, err := pgRepository.CheckDuplicate(ctx, emailArgs...); err != nil {
+		return err
+	} else if countEmail > 0 {
+		return constant.ErrEmailAlreadyExist
+	}
+
+	// check duplicate mobile phone
+	if countMobilePhone, err := pgRepository.CheckDuplicate(ctx, mobilePhoneArgs...); err != nil {
+		return err
+	} else if countMobilePhone > 0 {
+		return constant.ErrMobilePhoneAlreadyExist
+	}
+	return nil
+}
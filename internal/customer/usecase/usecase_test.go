@@ -5,17 +5,28 @@ import (
 	"github.com/alpakih/point-of-sales/internal/constant"
 	"github.com/alpakih/point-of-sales/internal/customer"
 	"github.com/alpakih/point-of-sales/internal/customer/mocks"
+	"github.com/alpakih/point-of-sales/internal/domain"
+	"github.com/alpakih/point-of-sales/pkg/database"
+	dbMocks "github.com/alpakih/point-of-sales/pkg/database/mocks"
+	"github.com/alpakih/point-of-sales/pkg/utils"
+	validatorGo "github.com/go-playground/validator/v10"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
 	"testing"
+	"time"
 )
 
 func TestCustomerUseCase_StoreCustomer(t *testing.T) {
 	mockCustomerRepository := new(mocks.PgRepository)
+	mockTxManager := new(dbMocks.TxManager)
+	mockTxManager.On("WithinTransaction", mock.Anything, mock.Anything).Return(func(ctx context.Context, fn func(context.Context) error) error {
+		return fn(ctx)
+	})
 	mockDataCustomerRequest := customer.StoreRequest{
 		Name:        "name",
 		Email:       "email@test.com",
-		MobilePhone: "087666777876",
+		MobilePhone: "087766777876",
 		Password:    "123321",
 	}
 
@@ -28,7 +39,7 @@ func TestCustomerUseCase_StoreCustomer(t *testing.T) {
 
 		mockCustomerRepository.On("Create", mock.Anything, mock.AnythingOfType("*domain.Customer")).Return(nil).Once()
 
-		u := NewCustomerUseCase(mockCustomerRepository)
+		u := NewCustomerUseCase(mockCustomerRepository, mockTxManager)
 
 		data, err := u.StoreCustomer(context.TODO(), tempMockCustomer)
 
@@ -37,6 +48,27 @@ func TestCustomerUseCase_StoreCustomer(t *testing.T) {
 		mockCustomerRepository.AssertExpectations(t)
 	})
 
+	t.Run("canonical-mobile-phone", func(t *testing.T) {
+		tempMockCustomer := mockDataCustomerRequest
+		tempMockCustomer.MobilePhone = "62 877-6677-7876"
+
+		mockCustomerRepository.On("CheckDuplicate", mock.Anything, "email =?", mock.Anything).Return(int64(0), nil).Once()
+
+		mockCustomerRepository.On("CheckDuplicate", mock.Anything, "mobile_phone =?", "+6287766777876").Return(int64(0), nil).Once()
+
+		mockCustomerRepository.On("Create", mock.Anything, mock.MatchedBy(func(entity *domain.Customer) bool {
+			return entity.MobilePhone == "+6287766777876"
+		})).Return(nil).Once()
+
+		u := NewCustomerUseCase(mockCustomerRepository, mockTxManager)
+
+		data, err := u.StoreCustomer(context.TODO(), tempMockCustomer)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "+6287766777876", data.MobilePhone)
+		mockCustomerRepository.AssertExpectations(t)
+	})
+
 	t.Run("existing-mobile-phone", func(t *testing.T) {
 		tempMockCustomer := mockDataCustomerRequest
 
@@ -44,7 +76,7 @@ func TestCustomerUseCase_StoreCustomer(t *testing.T) {
 
 		mockCustomerRepository.On("CheckDuplicate", mock.Anything, "mobile_phone =?", mock.Anything).Return(int64(1), nil).Once()
 
-		u := NewCustomerUseCase(mockCustomerRepository)
+		u := NewCustomerUseCase(mockCustomerRepository, mockTxManager)
 
 		data, err := u.StoreCustomer(context.TODO(), tempMockCustomer)
 
@@ -59,7 +91,7 @@ func TestCustomerUseCase_StoreCustomer(t *testing.T) {
 
 		mockCustomerRepository.On("CheckDuplicate", mock.Anything, "email =?", tempMockCustomer.Email).Return(int64(1), constant.ErrEmailAlreadyExist).Once()
 
-		u := NewCustomerUseCase(mockCustomerRepository)
+		u := NewCustomerUseCase(mockCustomerRepository, mockTxManager)
 
 		data, err := u.StoreCustomer(context.TODO(), tempMockCustomer)
 
@@ -69,3 +101,371 @@ func TestCustomerUseCase_StoreCustomer(t *testing.T) {
 		mockCustomerRepository.AssertExpectations(t)
 	})
 }
+
+func TestCustomerUseCase_UpdateCustomer(t *testing.T) {
+	mockCustomerRepository := new(mocks.PgRepository)
+	mockTxManager := new(dbMocks.TxManager)
+	mockTxManager.On("WithinTransaction", mock.Anything, mock.Anything).Return(func(ctx context.Context, fn func(context.Context) error) error {
+		return fn(ctx)
+	})
+	mockDataCustomerRequest := customer.UpdateRequest{
+		Name:        "name",
+		Email:       "email@test.com",
+		MobilePhone: "087766777876",
+	}
+	mockDataCustomer := domain.Customer{
+		ID:          1,
+		Name:        "name",
+		Email:       "email@test.com",
+		MobilePhone: "087766777876",
+		Version:     2,
+	}
+
+	t.Run("success", func(t *testing.T) {
+		mockCustomerRepository.On("FindOneCustomerByID", mock.Anything, 1).Return(mockDataCustomer, nil).Once()
+
+		mockCustomerRepository.On("CheckDuplicate", mock.Anything, "email =? and id <> ?", mock.Anything, 1).Return(int64(0), nil).Once()
+
+		mockCustomerRepository.On("CheckDuplicate", mock.Anything, "mobile_phone =? and id <> ?", mock.Anything, 1).Return(int64(0), nil).Once()
+
+		mockCustomerRepository.On("Update", mock.Anything, mock.MatchedBy(func(entity domain.Customer) bool {
+			return entity.Version == 2
+		})).Return(nil).Once()
+
+		u := NewCustomerUseCase(mockCustomerRepository, mockTxManager)
+
+		data, err := u.UpdateCustomer(context.TODO(), mockDataCustomerRequest, 1, 2)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 3, data.Version)
+		assert.Equal(t, mockDataCustomerRequest.Email, data.Email)
+		mockCustomerRepository.AssertExpectations(t)
+	})
+
+	t.Run("version-mismatch", func(t *testing.T) {
+		mockCustomerRepository.On("FindOneCustomerByID", mock.Anything, 1).Return(mockDataCustomer, nil).Once()
+
+		u := NewCustomerUseCase(mockCustomerRepository, mockTxManager)
+
+		_, err := u.UpdateCustomer(context.TODO(), mockDataCustomerRequest, 1, 1)
+
+		assert.ErrorIs(t, err, constant.ErrVersionMismatch)
+		mockCustomerRepository.AssertExpectations(t)
+	})
+
+	t.Run("any-version", func(t *testing.T) {
+		mockCustomerRepository.On("FindOneCustomerByID", mock.Anything, 1).Return(mockDataCustomer, nil).Once()
+
+		mockCustomerRepository.On("CheckDuplicate", mock.Anything, "email =? and id <> ?", mock.Anything, 1).Return(int64(0), nil).Once()
+
+		mockCustomerRepository.On("CheckDuplicate", mock.Anything, "mobile_phone =? and id <> ?", mock.Anything, 1).Return(int64(0), nil).Once()
+
+		// If-Match: * updates the current version
+		mockCustomerRepository.On("Update", mock.Anything, mock.MatchedBy(func(entity domain.Customer) bool {
+			return entity.Version == 2
+		})).Return(nil).Once()
+
+		u := NewCustomerUseCase(mockCustomerRepository, mockTxManager)
+
+		data, err := u.UpdateCustomer(context.TODO(), mockDataCustomerRequest, 1, utils.AnyVersion)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 3, data.Version)
+		mockCustomerRepository.AssertExpectations(t)
+	})
+
+	t.Run("stale-replica", func(t *testing.T) {
+		// the replica still holds version 1, the version read by the client is on the primary
+		staleCustomer := mockDataCustomer
+		staleCustomer.Version = 1
+		mockReplicaRepository := new(mocks.PgRepository)
+		mockReplicaRepository.On("FindOneCustomerByID", mock.MatchedBy(database.UsePrimary), 1).Return(mockDataCustomer, nil).Once()
+		mockReplicaRepository.On("FindOneCustomerByID", mock.Anything, 1).Return(staleCustomer, nil).Maybe()
+
+		mockReplicaRepository.On("CheckDuplicate", mock.Anything, "email =? and id <> ?", mock.Anything, 1).Return(int64(0), nil).Once()
+
+		mockReplicaRepository.On("CheckDuplicate", mock.Anything, "mobile_phone =? and id <> ?", mock.Anything, 1).Return(int64(0), nil).Once()
+
+		mockReplicaRepository.On("Update", mock.Anything, mock.MatchedBy(func(entity domain.Customer) bool {
+			return entity.Version == 2
+		})).Return(nil).Once()
+
+		u := NewCustomerUseCase(mockReplicaRepository, mockTxManager)
+
+		data, err := u.UpdateCustomer(context.TODO(), mockDataCustomerRequest, 1, 2)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 3, data.Version)
+		mockReplicaRepository.AssertExpectations(t)
+	})
+}
+
+func TestCustomerUseCase_PatchCustomer(t *testing.T) {
+	mockCustomerRepository := new(mocks.PgRepository)
+	mockTxManager := new(dbMocks.TxManager)
+	mockTxManager.On("WithinTransaction", mock.Anything, mock.Anything).Return(func(ctx context.Context, fn func(context.Context) error) error {
+		return fn(ctx)
+	})
+	mockDataCustomer := domain.Customer{
+		ID:          1,
+		Name:        "name",
+		Email:       "email@test.com",
+		MobilePhone: "+6287766777876",
+		Password:    "hashed",
+		Version:     2,
+	}
+
+	t.Run("success", func(t *testing.T) {
+		mockCustomerRepository.On("FindOneCustomerByID", mock.Anything, 1).Return(mockDataCustomer, nil).Once()
+
+		mockCustomerRepository.On("CheckDuplicate", mock.Anything, "email =? and id <> ?", "new@test.com", 1).Return(int64(0), nil).Once()
+		mockCustomerRepository.On("CheckDuplicate", mock.Anything, "mobile_phone =? and id <> ?", "+6287766777876", 1).Return(int64(0), nil).Once()
+
+		mockCustomerRepository.On("Update", mock.Anything, mock.MatchedBy(func(entity domain.Customer) bool {
+			return entity.Email == "new@test.com" && entity.Name == "name" && entity.Version == 2
+		}), "email").Return(nil).Once()
+
+		u := NewCustomerUseCase(mockCustomerRepository, mockTxManager)
+
+		data, err := u.PatchCustomer(context.TODO(), []byte(`{"email":"new@test.com","version":10}`), 1, 2)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "new@test.com", data.Email)
+		assert.Equal(t, "+6287766777876", data.MobilePhone)
+		assert.Equal(t, 3, data.Version)
+		mockCustomerRepository.AssertExpectations(t)
+	})
+
+	t.Run("null-required-field", func(t *testing.T) {
+		mockCustomerRepository.On("FindOneCustomerByID", mock.Anything, 1).Return(mockDataCustomer, nil).Once()
+
+		u := NewCustomerUseCase(mockCustomerRepository, mockTxManager)
+
+		_, err := u.PatchCustomer(context.TODO(), []byte(`{"name":null}`), 1, 2)
+
+		var validationErrors validatorGo.ValidationErrors
+		assert.ErrorAs(t, err, &validationErrors)
+		assert.Equal(t, "name", validationErrors[0].Field())
+		mockCustomerRepository.AssertExpectations(t)
+	})
+
+	t.Run("unchanged", func(t *testing.T) {
+		mockCustomerRepository.On("FindOneCustomerByID", mock.Anything, 1).Return(mockDataCustomer, nil).Once()
+
+		u := NewCustomerUseCase(mockCustomerRepository, mockTxManager)
+
+		data, err := u.PatchCustomer(context.TODO(), []byte(`{"name":"name"}`), 1, 2)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2, data.Version)
+		mockCustomerRepository.AssertExpectations(t)
+	})
+
+	t.Run("stale-replica", func(t *testing.T) {
+		staleCustomer := mockDataCustomer
+		staleCustomer.Version = 1
+		mockReplicaRepository := new(mocks.PgRepository)
+		mockReplicaRepository.On("FindOneCustomerByID", mock.MatchedBy(database.UsePrimary), 1).Return(mockDataCustomer, nil).Once()
+		mockReplicaRepository.On("FindOneCustomerByID", mock.Anything, 1).Return(staleCustomer, nil).Maybe()
+
+		mockReplicaRepository.On("Update", mock.Anything, mock.MatchedBy(func(entity domain.Customer) bool {
+			return entity.Name == "new name" && entity.Version == 2
+		}), "name").Return(nil).Once()
+
+		u := NewCustomerUseCase(mockReplicaRepository, mockTxManager)
+
+		// If-Match: * resolves to the version of the primary
+		data, err := u.PatchCustomer(context.TODO(), []byte(`{"name":"new name"}`), 1, utils.AnyVersion)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 3, data.Version)
+		mockReplicaRepository.AssertExpectations(t)
+	})
+}
+
+func TestCustomerUseCase_GetCustomerByID(t *testing.T) {
+	mockCustomerRepository := new(mocks.PgRepository)
+	mockTxManager := new(dbMocks.TxManager)
+	mockDataCustomer := domain.Customer{
+		ID:          1,
+		Name:        "name",
+		Email:       "email@test.com",
+		MobilePhone: "087766777876",
+		Password:    "123321",
+		Version:     1,
+	}
+
+	t.Run("success", func(t *testing.T) {
+		mockCustomerRepository.On("FindOneCustomerByID", mock.Anything, 1).Return(mockDataCustomer, nil).Once()
+
+		u := NewCustomerUseCase(mockCustomerRepository, mockTxManager)
+
+		fieldset := utils.FieldsetQuery{Fields: []string{"id", "name"}}
+		data, err := u.GetCustomerByID(context.TODO(), 1, fieldset)
+
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{"id": 1, "name": "name"}, customer.NewCustomerMapper().ToSparseResponse(*data, fieldset))
+		mockCustomerRepository.AssertExpectations(t)
+	})
+
+	t.Run("include addresses", func(t *testing.T) {
+		tempMockCustomer := mockDataCustomer
+		tempMockCustomer.Addresses = []domain.CustomerAddress{{ID: 3, Label: "Office"}, {ID: 4, Label: "Home", IsDefault: true}}
+		mockCustomerRepository.On("FindOneCustomerByID", mock.Anything, 1, "Addresses").Return(tempMockCustomer, nil).Once()
+
+		u := NewCustomerUseCase(mockCustomerRepository, mockTxManager)
+
+		fieldset := utils.FieldsetQuery{Fields: []string{"id"}, Include: []string{"addresses"}}
+		data, err := u.GetCustomerByID(context.TODO(), 1, fieldset)
+
+		assert.NoError(t, err)
+		addresses := []customer.AddressResponse{{ID: 4, Label: "Home", IsDefault: true}, {ID: 3, Label: "Office"}}
+		assert.Equal(t, map[string]interface{}{"id": 1, "addresses": &addresses}, customer.NewCustomerMapper().ToSparseResponse(*data, fieldset))
+		mockCustomerRepository.AssertExpectations(t)
+	})
+
+	t.Run("include loyalty", func(t *testing.T) {
+		soon := time.Now().AddDate(0, 1, 0).Truncate(time.Second)
+		later := time.Now().AddDate(1, 0, 0).Truncate(time.Second)
+		tempMockCustomer := mockDataCustomer
+		// preloaded entries are not ordered, the redemption takes the points expiring first
+		tempMockCustomer.LoyaltyEntries = []domain.LoyaltyEntry{
+			{ID: 3, Type: domain.LoyaltyEntryRedeem, Points: -30, CreatedAt: time.Now()},
+			{ID: 2, Type: domain.LoyaltyEntryEarn, Points: 100, ExpiresAt: &later},
+			{ID: 1, Type: domain.LoyaltyEntryEarn, Points: 50, ExpiresAt: &soon},
+		}
+		mockCustomerRepository.On("FindOneCustomerByID", mock.Anything, 1, "LoyaltyEntries").Return(tempMockCustomer, nil).Once()
+
+		u := NewCustomerUseCase(mockCustomerRepository, mockTxManager)
+
+		fieldset := utils.FieldsetQuery{Fields: []string{"id"}, Include: []string{"loyalty"}}
+		data, err := u.GetCustomerByID(context.TODO(), 1, fieldset)
+
+		assert.NoError(t, err)
+		loyalty := &customer.LoyaltyBalanceResponse{Balance: 120, NextExpiry: &customer.LoyaltyExpiry{Points: 20, ExpiresAt: soon}}
+		assert.Equal(t, map[string]interface{}{"id": 1, "loyalty": loyalty}, customer.NewCustomerMapper().ToSparseResponse(*data, fieldset))
+		mockCustomerRepository.AssertExpectations(t)
+	})
+
+	t.Run("field not allowed", func(t *testing.T) {
+		u := NewCustomerUseCase(mockCustomerRepository, mockTxManager)
+
+		_, err := u.GetCustomerByID(context.TODO(), 1, utils.FieldsetQuery{Fields: []string{"password"}})
+
+		var fieldsetError *utils.FieldsetError
+		assert.ErrorAs(t, err, &fieldsetError)
+		assert.Equal(t, "fields", fieldsetError.Param)
+		assert.Equal(t, "password", fieldsetError.Value)
+	})
+
+	t.Run("include not allowed", func(t *testing.T) {
+		u := NewCustomerUseCase(mockCustomerRepository, mockTxManager)
+
+		_, err := u.GetCustomerByID(context.TODO(), 1, utils.FieldsetQuery{Include: []string{"orders"}})
+
+		var fieldsetError *utils.FieldsetError
+		assert.ErrorAs(t, err, &fieldsetError)
+		assert.Equal(t, "include", fieldsetError.Param)
+	})
+}
+
+func TestCustomerUseCase_RestoreCustomer(t *testing.T) {
+	mockCustomerRepository := new(mocks.PgRepository)
+	mockTxManager := new(dbMocks.TxManager)
+	mockTxManager.On("WithinTransaction", mock.Anything, mock.Anything).Return(func(ctx context.Context, fn func(context.Context) error) error {
+		return fn(ctx)
+	})
+	mockDataCustomer := domain.Customer{
+		ID:          1,
+		Name:        "name",
+		Email:       "email@test.com",
+		MobilePhone: "087766777876",
+		Version:     2,
+		DeletedAt:   gorm.DeletedAt{Time: time.Now(), Valid: true},
+	}
+
+	t.Run("success", func(t *testing.T) {
+		mockCustomerRepository.On("FindDeletedCustomerByID", mock.Anything, 1).Return(mockDataCustomer, nil).Once()
+
+		mockCustomerRepository.On("CheckDuplicate", mock.Anything, "email =? and id <> ?", "email@test.com", 1).Return(int64(0), nil).Once()
+
+		mockCustomerRepository.On("CheckDuplicate", mock.Anything, "mobile_phone =? and id <> ?", "+6287766777876", 1).Return(int64(0), nil).Once()
+
+		mockCustomerRepository.On("Restore", mock.Anything, 1).Return(nil).Once()
+
+		u := NewCustomerUseCase(mockCustomerRepository, mockTxManager)
+
+		data, err := u.RestoreCustomer(context.TODO(), 1)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 3, data.Version)
+		assert.Nil(t, data.DeletedAt)
+		mockCustomerRepository.AssertExpectations(t)
+	})
+
+	t.Run("email-registered-meanwhile", func(t *testing.T) {
+		mockCustomerRepository.On("FindDeletedCustomerByID", mock.Anything, 1).Return(mockDataCustomer, nil).Once()
+
+		mockCustomerRepository.On("CheckDuplicate", mock.Anything, "email =? and id <> ?", "email@test.com", 1).Return(int64(1), nil).Once()
+
+		u := NewCustomerUseCase(mockCustomerRepository, mockTxManager)
+
+		_, err := u.RestoreCustomer(context.TODO(), 1)
+
+		assert.ErrorIs(t, err, constant.ErrEmailAlreadyExist)
+		mockCustomerRepository.AssertExpectations(t)
+	})
+
+	t.Run("not-deleted", func(t *testing.T) {
+		mockCustomerRepository.On("FindDeletedCustomerByID", mock.Anything, 2).Return(domain.Customer{}, gorm.ErrRecordNotFound).Once()
+
+		u := NewCustomerUseCase(mockCustomerRepository, mockTxManager)
+
+		_, err := u.RestoreCustomer(context.TODO(), 2)
+
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+		mockCustomerRepository.AssertExpectations(t)
+	})
+}
+
+func TestCustomerUseCase_ExportCustomers(t *testing.T) {
+	mockCustomerRepository := new(mocks.PgRepository)
+	mockTxManager := new(dbMocks.TxManager)
+
+	t.Run("success", func(t *testing.T) {
+		query := customer.ListQuery{PaginationQuery: utils.PaginationQuery{Search: "name"}}
+		mockCustomerRepository.On("FindCustomersInBatches", mock.Anything, query, exportBatchSize, mock.Anything).Return(func(ctx context.Context, query customer.ListQuery, batchSize int, fn func([]domain.Customer) error) error {
+			if err := fn([]domain.Customer{{ID: 1, Name: "name", Password: "password", Version: 1}}); err != nil {
+				return err
+			}
+			return fn([]domain.Customer{{ID: 2, Name: "name 2", Password: "password", Version: 1}})
+		}).Once()
+
+		u := NewCustomerUseCase(mockCustomerRepository, mockTxManager)
+
+		var data []customer.Response
+		err := u.ExportCustomers(context.TODO(), query, func(responses []customer.Response) error {
+			data = append(data, responses...)
+			return nil
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []customer.Response{{ID: 1, Name: "name", Version: 1}, {ID: 2, Name: "name 2", Version: 1}}, data)
+		mockCustomerRepository.AssertExpectations(t)
+	})
+
+	t.Run("unknown-field", func(t *testing.T) {
+		query := customer.ListQuery{}
+		query.SetFields("name,password")
+
+		u := NewCustomerUseCase(mockCustomerRepository, mockTxManager)
+
+		err := u.ExportCustomers(context.TODO(), query, func(responses []customer.Response) error {
+			return nil
+		})
+
+		var fieldsetError *utils.FieldsetError
+		assert.ErrorAs(t, err, &fieldsetError)
+		mockCustomerRepository.AssertNotCalled(t, "FindCustomersInBatches", mock.Anything, query, exportBatchSize, mock.Anything)
+	})
+}
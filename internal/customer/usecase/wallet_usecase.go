@@ -0,0 +1,120 @@
+package usecase
+
+import (
+	"context"
+	"github.com/alpakih/point-of-sales/internal/constant"
+	"github.com/alpakih/point-of-sales/internal/customer"
+	"github.com/alpakih/point-of-sales/internal/domain"
+	"github.com/alpakih/point-of-sales/pkg/database"
+	"time"
+)
+
+type customerWalletUseCase struct {
+	pgRepository       customer.PgRepository
+	walletPgRepository customer.WalletPgRepository
+	txManager          database.TxManager
+}
+
+func NewCustomerWalletUseCase(pgRepository customer.PgRepository, walletPgRepository customer.WalletPgRepository,
+	txManager database.TxManager) customer.WalletUseCase {
+	return &customerWalletUseCase{
+		pgRepository:       pgRepository,
+		walletPgRepository: walletPgRepository,
+		txManager:          txManager,
+	}
+}
+
+// GetWallet returns the balance of the wallet of the customer and its ledger.
+func (c customerWalletUseCase) GetWallet(ctx context.Context, customerID int) (*customer.WalletResponse, error) {
+	if _, err := c.pgRepository.FindOneCustomerByID(ctx, customerID); err != nil {
+		return nil, err
+	}
+
+	entries, err := c.walletPgRepository.FindEntries(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+	result := customer.NewCustomerMapper().ToWalletResponse(entries)
+	return &result, nil
+}
+
+// TopUp credits the amount paid by the customer, constant.ErrWalletReferenceExists
+// is returned when the payment was already credited.
+func (c customerWalletUseCase) TopUp(ctx context.Context, customerID int, request customer.WalletRequest) (*customer.WalletEntryResponse, error) {
+	return c.append(ctx, domain.WalletEntry{
+		CustomerID: customerID,
+		Type:       domain.WalletEntryTopUp,
+		Amount:     request.Amount,
+		Reference:  request.Reference,
+		Reason:     request.Reason,
+		StaffID:    request.StaffID,
+	})
+}
+
+// Spend debits the amount tendered for a sale, constant.ErrInsufficientBalance
+// is returned when the balance is lower than the amount and
+// constant.ErrWalletReferenceExists when the sale was already paid by the wallet.
+func (c customerWalletUseCase) Spend(ctx context.Context, customerID int, request customer.WalletRequest) (*customer.WalletEntryResponse, error) {
+	return c.append(ctx, domain.WalletEntry{
+		CustomerID: customerID,
+		Type:       domain.WalletEntrySpend,
+		Amount:     -request.Amount,
+		Reference:  request.Reference,
+		Reason:     request.Reason,
+		StaffID:    request.StaffID,
+	})
+}
+
+// Refund credits the amount of a refunded sale as store credit,
+// constant.ErrWalletReferenceExists is returned when the sale was already
+// refunded to the wallet.
+func (c customerWalletUseCase) Refund(ctx context.Context, customerID int, request customer.WalletRequest) (*customer.WalletEntryResponse, error) {
+	return c.append(ctx, domain.WalletEntry{
+		CustomerID: customerID,
+		Type:       domain.WalletEntryRefund,
+		Amount:     request.Amount,
+		Reference:  request.Reference,
+		Reason:     request.Reason,
+		StaffID:    request.StaffID,
+	})
+}
+
+// Adjust credits or debits the wallet manually, constant.ErrInsufficientBalance
+// is returned when the balance is lower than the amount debited.
+func (c customerWalletUseCase) Adjust(ctx context.Context, customerID int, request customer.WalletAdjustmentRequest) (*customer.WalletEntryResponse, error) {
+	return c.append(ctx, domain.WalletEntry{
+		CustomerID: customerID,
+		Type:       domain.WalletEntryAdjustment,
+		Amount:     request.Amount,
+		Reference:  request.Reference,
+		Reason:     request.Reason,
+		StaffID:    request.StaffID,
+	})
+}
+
+// append appends the entry with the balance it leaves, the customer is locked so
+// that concurrent entries can't debit the same balance twice.
+func (c customerWalletUseCase) append(ctx context.Context, entry domain.WalletEntry) (*customer.WalletEntryResponse, error) {
+	entry.CreatedAt = time.Now()
+
+	err := c.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := c.walletPgRepository.LockCustomer(ctx, entry.CustomerID); err != nil {
+			return err
+		}
+		balance, err := c.walletPgRepository.FindBalance(ctx, entry.CustomerID)
+		if err != nil {
+			return err
+		}
+		entry.Balance = balance + entry.Amount
+		if entry.Balance < 0 {
+			return constant.ErrInsufficientBalance
+		}
+		return c.walletPgRepository.Append(ctx, &entry)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := customer.NewCustomerMapper().ToWalletEntryResponse(entry)
+	return &result, nil
+}
@@ -0,0 +1,111 @@
+package usecase
+
+import (
+	"context"
+	"github.com/alpakih/point-of-sales/internal/constant"
+	"github.com/alpakih/point-of-sales/internal/customer"
+	"github.com/alpakih/point-of-sales/internal/customer/mocks"
+	"github.com/alpakih/point-of-sales/internal/domain"
+	dbMocks "github.com/alpakih/point-of-sales/pkg/database/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+	"testing"
+)
+
+func TestCustomerWalletUseCase_Spend(t *testing.T) {
+	mockCustomerRepository := new(mocks.PgRepository)
+	mockWalletRepository := new(mocks.WalletPgRepository)
+	mockTxManager := new(dbMocks.TxManager)
+	mockTxManager.On("WithinTransaction", mock.Anything, mock.Anything).Return(func(ctx context.Context, fn func(context.Context) error) error {
+		return fn(ctx)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		mockWalletRepository.On("LockCustomer", mock.Anything, 1).Return(nil).Once()
+		mockWalletRepository.On("FindBalance", mock.Anything, 1).Return(int64(50000), nil).Once()
+		mockWalletRepository.On("Append", mock.Anything, mock.MatchedBy(func(entry *domain.WalletEntry) bool {
+			return entry.Type == domain.WalletEntrySpend && entry.Amount == -30000 && entry.Balance == 20000 &&
+				entry.Reference == "S-1" && entry.StaffID == "K-07"
+		})).Return(func(ctx context.Context, entry *domain.WalletEntry) error {
+			entry.ID = 10
+			return nil
+		}).Once()
+
+		u := NewCustomerWalletUseCase(mockCustomerRepository, mockWalletRepository, mockTxManager)
+
+		data, err := u.Spend(context.TODO(), 1, customer.WalletRequest{Reference: "S-1", Amount: 30000, StaffID: "K-07"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 10, data.ID)
+		assert.Equal(t, int64(20000), data.Balance)
+		mockWalletRepository.AssertExpectations(t)
+	})
+
+	t.Run("insufficient-balance", func(t *testing.T) {
+		mockWalletRepository.On("LockCustomer", mock.Anything, 2).Return(nil).Once()
+		mockWalletRepository.On("FindBalance", mock.Anything, 2).Return(int64(10000), nil).Once()
+
+		u := NewCustomerWalletUseCase(mockCustomerRepository, mockWalletRepository, mockTxManager)
+
+		_, err := u.Spend(context.TODO(), 2, customer.WalletRequest{Reference: "S-2", Amount: 30000})
+
+		assert.ErrorIs(t, err, constant.ErrInsufficientBalance)
+		mockWalletRepository.AssertNumberOfCalls(t, "Append", 1)
+	})
+
+	t.Run("not-found", func(t *testing.T) {
+		mockWalletRepository.On("LockCustomer", mock.Anything, 3).Return(gorm.ErrRecordNotFound).Once()
+
+		u := NewCustomerWalletUseCase(mockCustomerRepository, mockWalletRepository, mockTxManager)
+
+		_, err := u.Spend(context.TODO(), 3, customer.WalletRequest{Reference: "S-3", Amount: 30000})
+
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	})
+}
+
+func TestCustomerWalletUseCase_Adjust(t *testing.T) {
+	mockCustomerRepository := new(mocks.PgRepository)
+	mockWalletRepository := new(mocks.WalletPgRepository)
+	mockTxManager := new(dbMocks.TxManager)
+	mockTxManager.On("WithinTransaction", mock.Anything, mock.Anything).Return(func(ctx context.Context, fn func(context.Context) error) error {
+		return fn(ctx)
+	})
+
+	mockWalletRepository.On("LockCustomer", mock.Anything, 1).Return(nil).Once()
+	mockWalletRepository.On("FindBalance", mock.Anything, 1).Return(int64(50000), nil).Once()
+	mockWalletRepository.On("Append", mock.Anything, mock.MatchedBy(func(entry *domain.WalletEntry) bool {
+		return entry.Type == domain.WalletEntryAdjustment && entry.Amount == -50000 && entry.Balance == 0 &&
+			entry.Reason == "duplicate top-up" && entry.StaffID == "A-01"
+	})).Return(nil).Once()
+
+	u := NewCustomerWalletUseCase(mockCustomerRepository, mockWalletRepository, mockTxManager)
+
+	data, err := u.Adjust(context.TODO(), 1, customer.WalletAdjustmentRequest{Amount: -50000, Reason: "duplicate top-up", StaffID: "A-01"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), data.Balance)
+	mockWalletRepository.AssertExpectations(t)
+}
+
+func TestCustomerWalletUseCase_GetWallet(t *testing.T) {
+	mockCustomerRepository := new(mocks.PgRepository)
+	mockWalletRepository := new(mocks.WalletPgRepository)
+	mockTxManager := new(dbMocks.TxManager)
+
+	mockCustomerRepository.On("FindOneCustomerByID", mock.Anything, 1).Return(domain.Customer{ID: 1}, nil).Once()
+	mockWalletRepository.On("FindEntries", mock.Anything, 1).Return([]domain.WalletEntry{
+		{ID: 1, Type: domain.WalletEntryTopUp, Amount: 50000, Balance: 50000},
+		{ID: 2, Type: domain.WalletEntrySpend, Amount: -20000, Balance: 30000},
+	}, nil).Once()
+
+	u := NewCustomerWalletUseCase(mockCustomerRepository, mockWalletRepository, mockTxManager)
+
+	data, err := u.GetWallet(context.TODO(), 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(30000), data.Balance)
+	assert.Len(t, data.History, 2)
+	assert.Equal(t, 2, data.History[0].ID)
+}
@@ -0,0 +1,15 @@
+package customer
+
+import (
+	"context"
+	"github.com/alpakih/point-of-sales/internal/domain"
+)
+
+// WalletPgRepository stores the wallet ledgers of the customers, entries are
+// appended and never changed.
+type WalletPgRepository interface {
+	LockCustomer(ctx context.Context, customerID int) error
+	FindBalance(ctx context.Context, customerID int) (int64, error)
+	Append(ctx context.Context, entry *domain.WalletEntry) error
+	FindEntries(ctx context.Context, customerID int) ([]domain.WalletEntry, error)
+}
@@ -1,15 +1,24 @@
 package domain
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 type Customer struct {
-	ID          int       `gorm:"primarykey;autoIncrement:true" qsearch:"-"`
-	Name        string    `gorm:"type:varchar(50);column:name" qsearch:"name"`
-	Email       string    `gorm:"type:varchar(100);column:email" qsearch:"email"`
-	MobilePhone string    `gorm:"type:varchar(14);column:mobile_phone" qsearch:"mobile_phone"`
-	Password    string    `gorm:"type:varchar(100);column:password" qsearch:"-"`
-	CreatedAt   time.Time `gorm:"column:created_at" qsearch:"-"`
-	UpdatedAt   time.Time `gorm:"column:updated_at" qsearch:"-"`
+	ID          int               `gorm:"primarykey;autoIncrement:true" qsearch:"-" qsort:"id" qfilter:"eq,ne,in"`
+	Name        string            `gorm:"type:varchar(50);column:name" qsearch:"name" qsort:"name" qfilter:"eq,ne,in,like"`
+	Email       string            `gorm:"type:varchar(100);column:email" qsearch:"email" qsort:"email" qfilter:"eq,ne,in,like"`
+	MobilePhone string            `gorm:"type:varchar(16);column:mobile_phone" qsearch:"mobile_phone" qsort:"mobile_phone" qfilter:"eq,ne,in,like"`
+	Password    string            `gorm:"type:varchar(100);column:password" qsearch:"-" qsort:"-" qfilter:"-"`
+	Version     int               `gorm:"column:version;not null;default:1" qsearch:"-" qsort:"-" qfilter:"-"`
+	CreatedAt   time.Time         `gorm:"column:created_at" qsearch:"-" qsort:"created_at" qfilter:"eq,gt,gte,lt,lte"`
+	UpdatedAt   time.Time         `gorm:"column:updated_at" qsearch:"-" qsort:"updated_at" qfilter:"eq,gt,gte,lt,lte"`
+	DeletedAt   gorm.DeletedAt    `gorm:"column:deleted_at;index" qsearch:"-" qsort:"-" qfilter:"is_null"`
+	Addresses   []CustomerAddress `gorm:"foreignKey:CustomerID;constraint:OnDelete:CASCADE" qsearch:"-" qsort:"-" qfilter:"-"`
+	// LoyaltyEntries loyalty ledger of the customer, only preloaded for ?include=loyalty
+	LoyaltyEntries []LoyaltyEntry `gorm:"foreignKey:CustomerID;constraint:-" qsearch:"-" qsort:"-" qfilter:"-"`
 }
 
 // TableName name of table
@@ -0,0 +1,28 @@
+package domain
+
+import "time"
+
+// CustomerAddress delivery and invoicing address of a customer, a customer with
+// addresses has exactly one default address, kept by the transactions locking
+// the customer.
+type CustomerAddress struct {
+	ID         int       `gorm:"primarykey;autoIncrement:true"`
+	CustomerID int       `gorm:"column:customer_id;not null;index"`
+	Label      string    `gorm:"type:varchar(50);column:label"`
+	Recipient  string    `gorm:"type:varchar(50);column:recipient"`
+	Phone      string    `gorm:"type:varchar(16);column:phone"`
+	Street     string    `gorm:"type:varchar(255);column:street"`
+	Village    string    `gorm:"type:varchar(100);column:village"`
+	District   string    `gorm:"type:varchar(100);column:district"`
+	City       string    `gorm:"type:varchar(100);column:city"`
+	Province   string    `gorm:"type:varchar(100);column:province"`
+	PostalCode string    `gorm:"type:varchar(5);column:postal_code"`
+	IsDefault  bool      `gorm:"column:is_default;not null;default:false"`
+	CreatedAt  time.Time `gorm:"column:created_at"`
+	UpdatedAt  time.Time `gorm:"column:updated_at"`
+}
+
+// TableName name of table
+func (r CustomerAddress) TableName() string {
+	return "customer_addresses"
+}
@@ -0,0 +1,47 @@
+package domain
+
+import "time"
+
+// Channels of the marketing consents.
+const (
+	ConsentChannelEmail    = "email"
+	ConsentChannelSMS      = "sms"
+	ConsentChannelWhatsApp = "whatsapp"
+)
+
+// ConsentChannels channels the customers consent to marketing on.
+var ConsentChannels = []string{ConsentChannelEmail, ConsentChannelSMS, ConsentChannelWhatsApp}
+
+// IsConsentChannel reports whether channel is one of ConsentChannels.
+func IsConsentChannel(channel string) bool {
+	for _, v := range ConsentChannels {
+		if v == channel {
+			return true
+		}
+	}
+	return false
+}
+
+// Sources of the marketing consents.
+const (
+	ConsentSourcePOS    = "pos"
+	ConsentSourceWeb    = "web"
+	ConsentSourceImport = "import"
+)
+
+// CustomerConsent change of the marketing consent of a customer on a channel,
+// granted or withdrawn. The changes are append only, the latest change of a
+// channel is the consent of the customer on it.
+type CustomerConsent struct {
+	ID         int       `gorm:"primarykey;autoIncrement:true"`
+	CustomerID int       `gorm:"column:customer_id;not null;index:idx_customer_consents_customer_channel,priority:1"`
+	Channel    string    `gorm:"type:varchar(20);column:channel;not null;index:idx_customer_consents_customer_channel,priority:2"`
+	Granted    bool      `gorm:"column:granted;not null"`
+	Source     string    `gorm:"type:varchar(20);column:source;not null"`
+	CreatedAt  time.Time `gorm:"column:created_at"`
+}
+
+// TableName name of table
+func (r CustomerConsent) TableName() string {
+	return "customer_consents"
+}
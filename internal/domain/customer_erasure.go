@@ -0,0 +1,20 @@
+package domain
+
+import "time"
+
+// CustomerErasure audit record of the erasure of the personal data of a
+// customer, PerformedBy identifies the staff who performed it and AuthorizedBy
+// the admin API key it was requested with.
+type CustomerErasure struct {
+	ID           int       `gorm:"primarykey;autoIncrement:true"`
+	CustomerID   int       `gorm:"column:customer_id;not null;index"`
+	PerformedBy  string    `gorm:"type:varchar(50);column:performed_by;not null"`
+	AuthorizedBy string    `gorm:"type:varchar(50);column:authorized_by"`
+	Reason       string    `gorm:"type:varchar(255);column:reason"`
+	CreatedAt    time.Time `gorm:"column:created_at"`
+}
+
+// TableName name of table
+func (r CustomerErasure) TableName() string {
+	return "customer_erasures"
+}
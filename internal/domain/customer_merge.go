@@ -0,0 +1,20 @@
+package domain
+
+import "time"
+
+// CustomerMerge audit record of a customer merged into a survivor customer, the
+// contact details are those of the merged customer at the time of the merge.
+type CustomerMerge struct {
+	ID          int       `gorm:"primarykey;autoIncrement:true"`
+	SurvivorID  int       `gorm:"column:survivor_id;not null;index"`
+	MergedID    int       `gorm:"column:merged_id;not null;index"`
+	Name        string    `gorm:"type:varchar(50);column:name"`
+	Email       string    `gorm:"type:varchar(100);column:email"`
+	MobilePhone string    `gorm:"type:varchar(16);column:mobile_phone"`
+	CreatedAt   time.Time `gorm:"column:created_at"`
+}
+
+// TableName name of table
+func (r CustomerMerge) TableName() string {
+	return "customer_merges"
+}
@@ -0,0 +1,45 @@
+package domain
+
+import "time"
+
+// Types of the customer segments.
+const (
+	CustomerSegmentStatic  = "static"
+	CustomerSegmentDynamic = "dynamic"
+)
+
+// CustomerSegment group of customers, the members of a static segment are
+// added and removed by hand, those of a dynamic segment are the customers
+// matching its rule when it was last evaluated.
+type CustomerSegment struct {
+	ID          int        `gorm:"primarykey;autoIncrement:true"`
+	Name        string     `gorm:"type:varchar(50);column:name;not null;uniqueIndex:idx_customer_segments_name"`
+	Description string     `gorm:"type:varchar(255);column:description"`
+	Type        string     `gorm:"type:varchar(10);column:type;not null"`
+	Rule        string     `gorm:"type:text;column:rule"`
+	EvaluatedAt *time.Time `gorm:"column:evaluated_at"`
+	CreatedAt   time.Time  `gorm:"column:created_at"`
+	UpdatedAt   time.Time  `gorm:"column:updated_at"`
+	// MemberCount number of members, read only
+	MemberCount int `gorm:"->;-:migration;column:member_count"`
+}
+
+// TableName name of table
+func (r CustomerSegment) TableName() string {
+	return "customer_segments"
+}
+
+// CustomerSegmentMember membership of a customer in a segment.
+type CustomerSegmentMember struct {
+	SegmentID  int       `gorm:"primaryKey;autoIncrement:false;column:segment_id"`
+	CustomerID int       `gorm:"primaryKey;autoIncrement:false;column:customer_id;index"`
+	CreatedAt  time.Time `gorm:"column:created_at"`
+	// Segment and Customer declare the foreign keys, they aren't loaded
+	Segment  CustomerSegment `gorm:"foreignKey:SegmentID;constraint:OnDelete:CASCADE"`
+	Customer Customer        `gorm:"foreignKey:CustomerID;constraint:OnDelete:CASCADE"`
+}
+
+// TableName name of table
+func (r CustomerSegmentMember) TableName() string {
+	return "customer_segment_members"
+}
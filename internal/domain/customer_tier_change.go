@@ -0,0 +1,21 @@
+package domain
+
+import "time"
+
+// CustomerTierChange change of the membership tier of a customer, the latest
+// change of a customer is its current tier. Spend and Points are the rolling
+// spend and points the tier was evaluated on.
+type CustomerTierChange struct {
+	ID           int       `gorm:"primarykey;autoIncrement:true"`
+	CustomerID   int       `gorm:"column:customer_id;not null;index"`
+	Tier         string    `gorm:"type:varchar(20);column:tier;not null"`
+	PreviousTier string    `gorm:"type:varchar(20);column:previous_tier"`
+	Spend        int64     `gorm:"column:spend;not null;default:0"`
+	Points       int       `gorm:"column:points;not null;default:0"`
+	CreatedAt    time.Time `gorm:"column:created_at"`
+}
+
+// TableName name of table
+func (r CustomerTierChange) TableName() string {
+	return "customer_tier_changes"
+}
@@ -0,0 +1,38 @@
+package domain
+
+import "time"
+
+// Types of the loyalty ledger entries.
+const (
+	LoyaltyEntryEarn     = "earn"
+	LoyaltyEntryRedeem   = "redeem"
+	LoyaltyEntryExpire   = "expire"
+	LoyaltyEntryReversal = "reversal"
+	LoyaltyEntryTransfer = "transfer"
+)
+
+// LoyaltyEntry entry of the loyalty ledger of a customer. The ledger is append
+// only, entries are corrected by appending a reversal entry. Entries crediting
+// points carry the time the points expire, entries debiting points of a given
+// credit entry, reversals, expiries and transfers, reference it by RelatedEntryID.
+// Earn entries record the amount and the lines of the sale, their reversals the
+// opposite amount.
+type LoyaltyEntry struct {
+	ID             int        `gorm:"primarykey;autoIncrement:true"`
+	CustomerID     int        `gorm:"column:customer_id;not null;index"`
+	Type           string     `gorm:"type:varchar(20);column:type;not null"`
+	Points         int        `gorm:"column:points;not null"`
+	Amount         int64      `gorm:"column:amount;not null;default:0"`
+	Reference      string     `gorm:"type:varchar(100);column:reference;index"`
+	RelatedEntryID *int       `gorm:"column:related_entry_id;index"`
+	ExpiresAt      *time.Time `gorm:"column:expires_at;index"`
+	CreatedAt      time.Time  `gorm:"column:created_at"`
+
+	Lines          []LoyaltyEntryLine `gorm:"foreignKey:EntryID;constraint:OnDelete:CASCADE"`
+	RelatedEntries []LoyaltyEntry     `gorm:"foreignKey:RelatedEntryID"`
+}
+
+// TableName name of table
+func (r LoyaltyEntry) TableName() string {
+	return "loyalty_entries"
+}
@@ -0,0 +1,17 @@
+package domain
+
+// LoyaltyEntryLine line of the sale of an earn entry, Product is the name or
+// code the point of sale identifies the product by, empty when it sent none.
+type LoyaltyEntryLine struct {
+	ID       int    `gorm:"primarykey;autoIncrement:true"`
+	EntryID  int    `gorm:"column:entry_id;not null;index"`
+	Product  string `gorm:"type:varchar(100);column:product;index"`
+	Category string `gorm:"type:varchar(50);column:category"`
+	Quantity int    `gorm:"column:quantity;not null;default:0"`
+	Amount   int64  `gorm:"column:amount;not null"`
+}
+
+// TableName name of table
+func (r LoyaltyEntryLine) TableName() string {
+	return "loyalty_entry_lines"
+}
@@ -0,0 +1,33 @@
+package domain
+
+import "time"
+
+// Types of the wallet ledger entries.
+const (
+	WalletEntryTopUp      = "topup"
+	WalletEntrySpend      = "spend"
+	WalletEntryRefund     = "refund"
+	WalletEntryAdjustment = "adjustment"
+	WalletEntryTransfer   = "transfer"
+)
+
+// WalletEntry entry of the store credit ledger of a customer. The ledger is
+// append only, Amount credits the wallet when positive and debits it when
+// negative, Balance is the balance of the wallet after the entry and never
+// negative. Top-ups, spends and refunds are recorded once per Reference.
+type WalletEntry struct {
+	ID         int       `gorm:"primarykey;autoIncrement:true"`
+	CustomerID int       `gorm:"column:customer_id;not null;index"`
+	Type       string    `gorm:"type:varchar(20);column:type;not null"`
+	Amount     int64     `gorm:"column:amount;not null"`
+	Balance    int64     `gorm:"column:balance;not null;check:chk_wallet_entries_balance,balance >= 0"`
+	Reference  string    `gorm:"type:varchar(100);column:reference"`
+	Reason     string    `gorm:"type:varchar(255);column:reason"`
+	StaffID    string    `gorm:"type:varchar(50);column:staff_id"`
+	CreatedAt  time.Time `gorm:"column:created_at"`
+}
+
+// TableName name of table
+func (r WalletEntry) TableName() string {
+	return "wallet_entries"
+}
@@ -1,16 +1,26 @@
 package main
 
 import (
+	"context"
+	"github.com/alpakih/point-of-sales/internal/customer"
 	customerHttpHandler "github.com/alpakih/point-of-sales/internal/customer/delivery/http"
+	customerMemoryRepo "github.com/alpakih/point-of-sales/internal/customer/repository/memory"
 	customerPgRepo "github.com/alpakih/point-of-sales/internal/customer/repository/pg"
 	customerUCase "github.com/alpakih/point-of-sales/internal/customer/usecase"
 	"github.com/alpakih/point-of-sales/internal/domain"
 	"github.com/alpakih/point-of-sales/pkg/database"
+	"github.com/beego/beego/v2/core/logs"
 	beego "github.com/beego/beego/v2/server/web"
+	"github.com/beego/beego/v2/task"
 	"github.com/beego/i18n"
 	"strings"
+	"time"
 )
 
+// loyaltyExpiryLookbackDays days before the run of the loyalty expiry task whose
+// expired points are expired by the run.
+const loyaltyExpiryLookbackDays = 7
+
 func main() {
 	var dbSectionConfig map[string]string
 
@@ -25,9 +35,24 @@ func main() {
 		panic(err)
 	}
 
-	if err := db.Conn().AutoMigrate(&domain.Customer{}); err != nil {
+	if err := db.Conn().AutoMigrate(&domain.Customer{}, &domain.CustomerAddress{}, &domain.CustomerMerge{}, &domain.LoyaltyEntry{},
+		&domain.LoyaltyEntryLine{}, &domain.CustomerTierChange{}, &domain.CustomerSegment{}, &domain.CustomerSegmentMember{},
+		&domain.WalletEntry{}, &domain.CustomerErasure{}, &domain.CustomerConsent{}); err != nil {
+		panic(err)
+	}
+	if err := customerPgRepo.MigrateIndexes(context.Background(), db.Conn()); err != nil {
 		panic(err)
 	}
+	// one-off conversion of the mobile phones stored before they were normalized,
+	// enabled for the boot following the upgrade
+	if beego.AppConfig.DefaultBool("migratemobilephones", false) {
+		if converted, skipped, err := customerPgRepo.MigrateMobilePhones(context.Background(), db.Conn()); err != nil {
+			panic(err)
+		} else {
+			logs.Info("customer mobile phones converted to E.164: %d, left as is: %v", converted, skipped)
+		}
+	}
+	database.MaxPageSize = beego.AppConfig.DefaultInt("maxpagesize", database.DefaultMaxPageSize)
 
 	if beego.BConfig.RunMode == "dev" {
 		beego.BConfig.WebConfig.DirectoryIndex = true
@@ -42,9 +67,97 @@ func main() {
 		}
 	}
 
-	customerRepository := customerPgRepo.NewCustomerPgRepository(db.Conn())
-	customerUseCase := customerUCase.NewCustomerUseCase(customerRepository)
-	customerHttpHandler.NewCustomerHandler(customerUseCase)
+	customerRepository := customerPgRepo.NewCustomerPgRepository(db.Conn(),
+		customerPgRepo.RepositoryDuplicateScope(beego.AppConfig.DefaultString("customerduplicatescope", customerPgRepo.DuplicateScopeActive)))
+	customerUseCase := customerUCase.NewCustomerUseCase(customerRepository, database.NewTxManager(db.Conn()))
+	customerImportUseCase := customerUCase.NewCustomerImportUseCase(customerRepository,
+		customerMemoryRepo.NewImportJobMemoryRepository(customerMemoryRepo.DefaultImportJobTTL), database.NewTxManager(db.Conn()))
+	customerAddressUseCase := customerUCase.NewCustomerAddressUseCase(customerRepository,
+		customerPgRepo.NewCustomerAddressPgRepository(db.Conn()), database.NewTxManager(db.Conn()))
+	membershipTiers, err := customer.ParseMembershipTiers(beego.AppConfig.DefaultString("membershiptiers", customer.DefaultMembershipTiers))
+	if err != nil {
+		panic(err)
+	}
+	customerMembershipPgRepository := customerPgRepo.NewCustomerMembershipPgRepository(db.Conn())
+	customerLoyaltyUseCase := customerUCase.NewCustomerLoyaltyUseCase(customerRepository,
+		customerPgRepo.NewCustomerLoyaltyPgRepository(db.Conn()), customerMembershipPgRepository, database.NewTxManager(db.Conn()),
+		loyaltyRuleFromConfig(membershipTiers))
+	customerMembershipUseCase := customerUCase.NewCustomerMembershipUseCase(customerRepository,
+		customerMembershipPgRepository, database.NewTxManager(db.Conn()), membershipTiers)
+	customerSegmentUseCase := customerUCase.NewCustomerSegmentUseCase(customerRepository,
+		customerPgRepo.NewCustomerSegmentPgRepository(db.Conn()), customerMembershipPgRepository, database.NewTxManager(db.Conn()),
+		membershipTiers)
+	customerSaleUseCase := customerUCase.NewCustomerSaleUseCase(customerRepository, customerPgRepo.NewCustomerSalePgRepository(db.Conn()),
+		beego.AppConfig.DefaultInt("customerlifespanyears", customerUCase.DefaultLifespanYears))
+	customerWalletUseCase := customerUCase.NewCustomerWalletUseCase(customerRepository, customerPgRepo.NewCustomerWalletPgRepository(db.Conn()),
+		database.NewTxManager(db.Conn()))
+	customerPrivacyUseCase := customerUCase.NewCustomerPrivacyUseCase(customerPgRepo.NewCustomerPrivacyPgRepository(db.Conn()),
+		database.NewTxManager(db.Conn()))
+	customerConsentUseCase := customerUCase.NewCustomerConsentUseCase(customerRepository, customerPgRepo.NewCustomerConsentPgRepository(db.Conn()),
+		database.NewTxManager(db.Conn()))
+	adminAPIKey := beego.AppConfig.DefaultString("adminapikey", "")
+	customerHttpHandler.NewCustomerHandler(customerUseCase, adminAPIKey)
+	customerHttpHandler.NewCustomerExportHandler(customerUseCase, adminAPIKey)
+	customerHttpHandler.NewCustomerImportHandler(customerImportUseCase)
+	customerHttpHandler.NewCustomerAddressHandler(customerAddressUseCase)
+	customerHttpHandler.NewCustomerLoyaltyHandler(customerLoyaltyUseCase)
+	customerHttpHandler.NewCustomerMembershipHandler(customerMembershipUseCase)
+	customerHttpHandler.NewCustomerSegmentHandler(customerSegmentUseCase)
+	customerHttpHandler.NewCustomerSaleHandler(customerSaleUseCase)
+	customerHttpHandler.NewCustomerWalletHandler(customerWalletUseCase, adminAPIKey)
+	customerHttpHandler.NewCustomerPrivacyHandler(customerPrivacyUseCase, adminAPIKey)
+	customerHttpHandler.NewCustomerConsentHandler(customerConsentUseCase)
+
+	// expires the loyalty points expired since the previous days, in case a run was missed
+	task.AddTask("loyalty-expiry", task.NewTask("loyalty-expiry", beego.AppConfig.DefaultString("loyaltyexpiryspec", "0 0 1 * * *"),
+		func(ctx context.Context) error {
+			now := time.Now()
+			expired, err := customerLoyaltyUseCase.ExpirePoints(ctx, now.AddDate(0, 0, -loyaltyExpiryLookbackDays), now)
+			if err != nil {
+				logs.Error("loyalty points expiry failed: %v", err)
+				return err
+			}
+			logs.Info("loyalty points expiry entries appended: %d", expired)
+			return nil
+		}))
+	task.AddTask("membership-evaluation", task.NewTask("membership-evaluation", beego.AppConfig.DefaultString("membershipevaluationspec", "0 30 1 * * *"),
+		func(ctx context.Context) error {
+			changed, err := customerMembershipUseCase.EvaluateTiers(ctx, time.Now())
+			if err != nil {
+				logs.Error("membership tiers evaluation failed: %v", err)
+				return err
+			}
+			logs.Info("membership tiers changed: %d", changed)
+			return nil
+		}))
+	task.AddTask("segment-evaluation", task.NewTask("segment-evaluation", beego.AppConfig.DefaultString("segmentevaluationspec", "0 0 2 * * *"),
+		func(ctx context.Context) error {
+			evaluated, err := customerSegmentUseCase.EvaluateSegments(ctx)
+			if err != nil {
+				logs.Error("customer segments evaluation failed: %v", err)
+				return err
+			}
+			logs.Info("customer segments evaluated: %d", evaluated)
+			return nil
+		}))
+	task.StartTask()
+	defer task.StopTask()
 
 	beego.Run()
 }
+
+// loyaltyRuleFromConfig reads the loyalty rule from the app config, the excluded
+// categories are separated by |.
+func loyaltyRuleFromConfig(tiers customer.MembershipTiers) customer.LoyaltyRule {
+	var excluded []string
+	if categories := beego.AppConfig.DefaultString("loyaltyexcludedcategories", ""); categories != "" {
+		excluded = strings.Split(categories, "|")
+	}
+	return customer.LoyaltyRule{
+		AmountPerPoint:     beego.AppConfig.DefaultInt64("loyaltyamountperpoint", customer.DefaultLoyaltyAmountPerPoint),
+		PointValue:         beego.AppConfig.DefaultInt64("loyaltypointvalue", customer.DefaultLoyaltyPointValue),
+		ExcludedCategories: excluded,
+		ExpiryMonths:       beego.AppConfig.DefaultInt("loyaltyexpirymonths", customer.DefaultLoyaltyExpiryMonths),
+		Tiers:              tiers,
+	}
+}
@@ -15,6 +15,7 @@ type (
 	ApiResponseInterface interface {
 		Ok(ctx *context.Context, data interface{}) error
 		OkWithPagination(ctx *context.Context, pagination interface{}, data interface{}) error
+		Accepted(ctx *context.Context, data interface{}) error
 		ResponseValidationError(ctx *context.Context, httpStatus int, code, message string, err error) error
 		ResponseError(ctx *context.Context, httpStatus int, code, message string, detailError ...DetailErrors) error
 	}
@@ -60,10 +61,17 @@ func (r ApiResponse) OkWithPagination(ctx *context.Context, pagination interface
 	})
 }
 
-func (r ApiResponse) ResponseValidationError(ctx *context.Context, httpStatus int, code, message string, err error) error {
-	var translator ut.Translator
-	var validationErrors = make([]DetailErrors, 0)
+// Accepted responds to a request whose processing continues in the background.
+func (r ApiResponse) Accepted(ctx *context.Context, data interface{}) error {
+	ctx.Output.SetStatus(http.StatusAccepted)
+	return ctx.Resp(ApiResponse{
+		Data:      data,
+		RequestId: ctx.ResponseWriter.ResponseWriter.Header().Get("X-REQUEST-ID"),
+		TimeStamp: time.Now().Format(time.RFC3339),
+	})
+}
 
+func (r ApiResponse) ResponseValidationError(ctx *context.Context, httpStatus int, code, message string, err error) error {
 	ctx.Output.SetStatus(httpStatus)
 	lang := "id"
 	acceptLang := ctx.Request.Header.Get("Accept-Language")
@@ -71,6 +79,25 @@ func (r ApiResponse) ResponseValidationError(ctx *context.Context, httpStatus in
 	if acceptLang != "" && i18n.IsExist(acceptLang) {
 		lang = acceptLang
 	}
+
+	return ctx.Resp(ApiResponse{
+		Error: Error{
+			Code:    code,
+			Status:  strconv.Itoa(httpStatus),
+			Message: message,
+			Details: ValidationDetails(lang, err),
+		},
+		RequestId: ctx.ResponseWriter.ResponseWriter.Header().Get("X-REQUEST-ID"),
+		TimeStamp: time.Now().Format(time.RFC3339),
+	})
+}
+
+// ValidationDetails translates the errors of validator.Validate to the given
+// language.
+func ValidationDetails(lang string, err error) []DetailErrors {
+	var translator ut.Translator
+	var validationErrors = make([]DetailErrors, 0)
+
 	if trans, found := validator.Validate.GetTranslator(lang); found {
 		translator = trans
 	}
@@ -92,17 +119,7 @@ func (r ApiResponse) ResponseValidationError(ctx *context.Context, httpStatus in
 			})
 		}
 	}
-
-	return ctx.Resp(ApiResponse{
-		Error: Error{
-			Code:    code,
-			Status:  strconv.Itoa(httpStatus),
-			Message: message,
-			Details: validationErrors,
-		},
-		RequestId: ctx.ResponseWriter.ResponseWriter.Header().Get("X-REQUEST-ID"),
-		TimeStamp: time.Now().Format(time.RFC3339),
-	})
+	return validationErrors
 }
 
 func (r ApiResponse) ResponseError(ctx *context.Context, httpStatus int, code, message string, detailErrors ...DetailErrors) error {
@@ -3,14 +3,17 @@ package database
 import (
 	"database/sql"
 	"errors"
-	"fmt"
+	"github.com/jackc/pgx/v4/stdlib"
 	_ "github.com/newrelic/go-agent/v3/integrations/nrmysql"
 	_ "github.com/newrelic/go-agent/v3/integrations/nrpgx"
 	"gorm.io/driver/mysql"
 	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
 	"gorm.io/driver/sqlserver"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
+	"net"
+	"net/url"
 	"time"
 )
 
@@ -18,34 +21,43 @@ const (
 	PostgresDriver  = "postgres"
 	MysqlDriver     = "mysql"
 	SqlServerDriver = "mssql"
+	SqliteDriver    = "sqlite"
 
 	DefaultMaxOpenConnection     = 25
 	DefaultMaxIdleConnection     = 25
 	DefaultMaxLifeTimeConnection = 300
 	DefaultMaxIdleTimeConnection = 300
-)
 
-var templateDsn = map[string]string{
-	PostgresDriver:  "host=%s port=%s user=%s dbname=%s password=%s %s",
-	MysqlDriver:     "%s:%s@(%s:%s)/%s?%s",
-	SqlServerDriver: "sqlserver://%s:%s@%s:%s?database=%s&%s",
-}
+	DefaultReplicaHealthCheckInterval = 10
+)
 
 type Config struct {
-	Driver                string
-	Host                  string
-	Port                  string
-	Name                  string
-	Username              string
-	Password              string
-	Options               string
+	Driver   string
+	Host     string
+	Port     string
+	Name     string
+	Username string
+	Password string
+	Options  string
+	// Deprecated: TemplateDsn is formatted with the unescaped settings, a password
+	// containing a separator of the DSN breaks it. Use URL or the structured
+	// settings instead, it is only honoured when set explicitly.
 	TemplateDsn           string
+	URL                   string
+	PasswordFile          string
+	TLS                   TLSConfig
 	Debug                 bool
 	MaxOpenConnection     int
 	MaxIdleConnection     int
 	MaxLifeTimeConnection int
 	MaxIdleTimeConnection int
 	NewrelicIntegration   bool
+	// Replicas read replica hosts as "host" or "host:port". Replicas share the
+	// credentials and database name of the primary.
+	Replicas                   []string
+	ReplicaHealthCheckInterval int
+
+	urlParams url.Values
 }
 
 func defaultDatabaseConfig() Config {
@@ -57,12 +69,58 @@ func defaultDatabaseConfig() Config {
 		MaxLifeTimeConnection: DefaultMaxLifeTimeConnection,
 		MaxIdleTimeConnection: DefaultMaxIdleTimeConnection,
 		NewrelicIntegration:   false,
+
+		ReplicaHealthCheckInterval: DefaultReplicaHealthCheckInterval,
 	}
 
 	return config
 }
 
 func (r *Config) connectDatabase() (*gorm.DB, error) {
+	gormDB, err := r.openDatabase()
+	if err != nil {
+		return nil, err
+	}
+	dbConn, err := gormDB.DB()
+	if err != nil {
+		return nil, err
+	}
+	if err := dbConn.Ping(); err != nil {
+		return nil, err
+	}
+	r.configurePool(dbConn)
+	return gormDB, nil
+}
+
+// connectReplicas opens a connection pool for every replica host. Replicas are
+// not pinged here, the resolver health check decides whether they receive reads.
+func (r *Config) connectReplicas() (*replicaResolver, error) {
+	var replicas = make([]*replica, 0, len(r.Replicas))
+	for _, host := range r.Replicas {
+		replicaConfig := *r
+		replicaConfig.Replicas = nil
+		if replicaHost, replicaPort, err := net.SplitHostPort(host); err != nil {
+			replicaConfig.Host = host
+		} else {
+			replicaConfig.Host = replicaHost
+			replicaConfig.Port = replicaPort
+		}
+
+		gormDB, err := replicaConfig.openDatabase()
+		if err != nil {
+			return nil, err
+		}
+		dbConn, err := gormDB.DB()
+		if err != nil {
+			return nil, err
+		}
+		replicaConfig.configurePool(dbConn)
+		replicas = append(replicas, &replica{host: host, pool: dbConn})
+	}
+	return newReplicaResolver(replicas, time.Duration(r.ReplicaHealthCheckInterval)*time.Second), nil
+}
+
+func (r *Config) openDatabase() (*gorm.DB, error) {
 	var logLevel = logger.Info
 
 	if !r.Debug {
@@ -88,52 +146,64 @@ func (r *Config) connectDatabase() (*gorm.DB, error) {
 		}
 	}
 
-	if gormDB, err := gorm.Open(
+	return gorm.Open(
 		gormDialect,
 		&gorm.Config{
 			SkipDefaultTransaction: true,
 			PrepareStmt:            true,
 			Logger:                 logger.Default.LogMode(logLevel),
 		},
-	); err != nil {
-		return nil, err
-	} else {
-		dbConn, err := gormDB.DB()
-		if err != nil {
-			return nil, err
-		}
-		if err := dbConn.Ping();err!= nil{
-			return nil, err
-		}
-		dbConn.SetMaxOpenConns(r.MaxOpenConnection)
-		dbConn.SetMaxIdleConns(r.MaxIdleConnection)
-		dbConn.SetConnMaxLifetime(time.Duration(r.MaxLifeTimeConnection) * time.Second)
-		dbConn.SetConnMaxIdleTime(time.Duration(r.MaxIdleTimeConnection) * time.Second)
-		return gormDB, nil
-	}
+	)
+}
+
+func (r *Config) configurePool(dbConn *sql.DB) {
+	dbConn.SetMaxOpenConns(r.MaxOpenConnection)
+	dbConn.SetMaxIdleConns(r.MaxIdleConnection)
+	dbConn.SetConnMaxLifetime(time.Duration(r.MaxLifeTimeConnection) * time.Second)
+	dbConn.SetConnMaxIdleTime(time.Duration(r.MaxIdleTimeConnection) * time.Second)
 }
 
 func (r *Config) newrelicDatabaseConnection() (*sql.DB, error) {
+	dsn, err := r.buildDsnConnection()
+	if err != nil {
+		return nil, err
+	}
 
 	switch r.Driver {
 	case "postgres":
-		return sql.Open("nrpgx", r.buildDsnConnection())
+		if r.TLS.ServerName != "" {
+			return nil, ErrConfigTLSServerNameUnsupported
+		}
+		return sql.Open("nrpgx", dsn)
 	case "mysql":
-		return sql.Open("nrmysql", r.buildDsnConnection())
+		return sql.Open("nrmysql", dsn)
 	default:
 		return nil, errors.New("unsupported driver for newrelic integration")
 	}
 }
 
 func (r *Config) getDialect() (gorm.Dialector, error) {
+	dsn, err := r.buildDsnConnection()
+	if err != nil {
+		return nil, err
+	}
 
 	switch r.Driver {
 	case "postgres":
-		return postgres.Open(r.buildDsnConnection()), nil
+		if r.TLS.ServerName == "" {
+			return postgres.Open(dsn), nil
+		}
+		config, err := r.postgresConnConfig(dsn)
+		if err != nil {
+			return nil, err
+		}
+		return postgres.New(postgres.Config{Conn: stdlib.OpenDB(*config)}), nil
 	case "mssql":
-		return sqlserver.Open(r.buildDsnConnection()), nil
+		return sqlserver.Open(dsn), nil
 	case "mysql":
-		return mysql.Open(r.buildDsnConnection()), nil
+		return mysql.Open(dsn), nil
+	case "sqlite":
+		return sqlite.Open(dsn), nil
 	default:
 		return nil, errors.New("unsupported driver database")
 	}
@@ -145,18 +215,12 @@ func (r *Config) getDialectWithExistingConnection(sqlDb *sql.DB) (gorm.Dialector
 	case "postgres":
 		return postgres.New(postgres.Config{Conn: sqlDb}), nil
 	case "mssql":
-		return mysql.New(mysql.Config{Conn: sqlDb}), nil
-	case "mysql":
 		return sqlserver.New(sqlserver.Config{Conn: sqlDb}), nil
+	case "mysql":
+		return mysql.New(mysql.Config{Conn: sqlDb}), nil
+	case "sqlite":
+		return sqlite.Dialector{Conn: sqlDb}, nil
 	default:
 		return nil, errors.New("unsupported driver database")
 	}
 }
-
-func (r *Config) buildDsnConnection() string {
-	if r.Driver == "postgres" {
-		return fmt.Sprintf(r.TemplateDsn, r.Host, r.Port, r.Username, r.Name, r.Password, r.Options)
-	} else {
-		return fmt.Sprintf(r.TemplateDsn, r.Username, r.Password, r.Host, r.Port, r.Name, r.Options)
-	}
-}
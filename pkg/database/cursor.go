@@ -0,0 +1,230 @@
+package database
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+var (
+	ErrInvalidCursor     = errors.New("cursor is invalid")
+	ErrCursorUnsupported = errors.New("cursor pagination is unsupported for the query")
+)
+
+// Cursors opaque tokens of the adjacent pages in cursor mode, empty when there
+// is no such page.
+type Cursors struct {
+	Next string `json:"next"`
+	Prev string `json:"prev"`
+}
+
+// cursorToken content of an opaque cursor: the sort columns, the values of the
+// boundary record and the direction to read from it.
+type cursorToken struct {
+	Columns  []string          `json:"c"`
+	Values   []json.RawMessage `json:"v"`
+	Backward bool              `json:"b,omitempty"`
+}
+
+func encodeCursor(token cursorToken) (string, error) {
+	content, err := json.Marshal(token)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(content), nil
+}
+
+func decodeCursor(cursor string) (cursorToken, error) {
+	var token cursorToken
+	content, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return token, ErrInvalidCursor
+	}
+	if err := json.Unmarshal(content, &token); err != nil {
+		return token, ErrInvalidCursor
+	}
+	return token, nil
+}
+
+// decodeValues reads the boundary values of a cursor, which must have been
+// issued for the same sort keys.
+func decodeValues(token cursorToken, keys []SortKey, fields []*schema.Field) ([]interface{}, error) {
+	if len(token.Columns) != len(keys) || len(token.Values) != len(keys) {
+		return nil, ErrInvalidCursor
+	}
+	var values = make([]interface{}, len(keys))
+	for i, key := range keys {
+		if token.Columns[i] != key.Column {
+			return nil, ErrInvalidCursor
+		}
+		value := reflect.New(fields[i].FieldType)
+		if err := json.Unmarshal(token.Values[i], value.Interface()); err != nil {
+			return nil, ErrInvalidCursor
+		}
+		values[i] = value.Elem().Interface()
+	}
+	return values, nil
+}
+
+// keysetCondition selects the records positioned after the boundary values in
+// the reading direction:
+//
+//	(a > ? OR (a = ? AND id > ?))
+func keysetCondition(stmt *gorm.Statement, keys []SortKey, values []interface{}, backward bool) clause.Expr {
+	var conditions []string
+	var vars []interface{}
+	for i, key := range keys {
+		var parts []string
+		for j := 0; j < i; j++ {
+			parts = append(parts, stmt.Quote(keys[j].Column)+" = ?")
+			vars = append(vars, values[j])
+		}
+		operator := " > ?"
+		if key.Desc != backward {
+			operator = " < ?"
+		}
+		parts = append(parts, stmt.Quote(key.Column)+operator)
+		vars = append(vars, values[i])
+
+		if len(parts) == 1 {
+			conditions = append(conditions, parts[0])
+		} else {
+			conditions = append(conditions, "("+strings.Join(parts, " AND ")+")")
+		}
+	}
+	return clause.Expr{SQL: "(" + strings.Join(conditions, " OR ") + ")", Vars: vars}
+}
+
+// boundaryCursor builds the cursor reading from the record at index in the
+// given direction.
+func boundaryCursor(ctx context.Context, records reflect.Value, index int, keys []SortKey, fields []*schema.Field, backward bool) (string, error) {
+	token := cursorToken{Backward: backward}
+	record := reflect.Indirect(records.Index(index))
+	for i, key := range keys {
+		value, _ := fields[i].ValueOf(ctx, record)
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return "", err
+		}
+		token.Columns = append(token.Columns, key.Column)
+		token.Values = append(token.Values, raw)
+	}
+	return encodeCursor(token)
+}
+
+// findByCursor executes the keyset query of the cursor mode. One record more
+// than the page size is read to know whether the reading direction has more
+// records, backward pages are read in reverse order and flipped afterwards.
+//
+// Sort columns are expected to be non-nullable, NULL values are skipped by the
+// keyset comparison.
+func (p *Paginator) findByCursor(ctx context.Context) error {
+	if p.rawQuery != "" {
+		return ErrCursorUnsupported
+	}
+	p.clamp()
+
+	db := p.DB.WithContext(ctx)
+	keys, fields, err := p.sortFields(db)
+	if err != nil {
+		return err
+	}
+	if len(fields) == 0 || !fields[len(fields)-1].PrimaryKey {
+		return ErrCursorUnsupported
+	}
+
+	var backward bool
+	if p.cursor != "" {
+		token, err := decodeCursor(p.cursor)
+		if err != nil {
+			return err
+		}
+		values, err := decodeValues(token, keys, fields)
+		if err != nil {
+			return err
+		}
+		backward = token.Backward
+		db = db.Where(keysetCondition(db.Statement, keys, values, backward))
+	}
+
+	if p.countTotal {
+		if err := p.updateTotal(ctx); err != nil {
+			return err
+		}
+	}
+
+	if err := db.Scopes(orderScope(keys, backward)).Limit(p.PageSize + 1).Find(p.Records).Error; err != nil {
+		return err
+	}
+
+	records := reflect.ValueOf(p.Records).Elem()
+	hasMore := records.Len() > p.PageSize
+	if hasMore {
+		records.Set(records.Slice(0, p.PageSize))
+	}
+	if backward {
+		for i, j := 0, records.Len()-1; i < j; i, j = i+1, j-1 {
+			left, right := records.Index(i).Interface(), records.Index(j).Interface()
+			records.Index(i).Set(reflect.ValueOf(right))
+			records.Index(j).Set(reflect.ValueOf(left))
+		}
+	}
+
+	p.Cursors = Cursors{}
+	if count := records.Len(); count > 0 {
+		if hasMore || backward {
+			if p.Cursors.Next, err = boundaryCursor(ctx, records, count-1, keys, fields, false); err != nil {
+				return err
+			}
+		}
+		if (hasMore && backward) || (!backward && p.cursor != "") {
+			if p.Cursors.Prev, err = boundaryCursor(ctx, records, 0, keys, fields, true); err != nil {
+				return err
+			}
+		}
+	}
+
+	p.Links = Links{}
+	if p.Links.First, err = p.CursorLink(""); err != nil {
+		return err
+	}
+	if p.Cursors.Next != "" {
+		if p.Links.Next, err = p.CursorLink(p.Cursors.Next); err != nil {
+			return err
+		}
+	}
+	if p.Cursors.Prev != "" {
+		if p.Links.Prev, err = p.CursorLink(p.Cursors.Prev); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FindInBatches reads all the records of the query, in the order set with
+// OrderBy, by keyset pages of the page size. fn is called after each non-empty
+// page is read into the destination, the reading stops at the first error.
+func (p *Paginator) FindInBatches(ctx context.Context, fn func() error) error {
+	p.Cursor("")
+	for {
+		if err := p.Find(ctx); err != nil {
+			return err
+		}
+		if reflect.ValueOf(p.Records).Elem().Len() > 0 {
+			if err := fn(); err != nil {
+				return err
+			}
+		}
+		if p.Cursors.Next == "" {
+			return nil
+		}
+		p.Cursor(p.Cursors.Next)
+	}
+}
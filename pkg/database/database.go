@@ -15,8 +15,9 @@ var (
 )
 
 type DbConnection struct {
-	db     *gorm.DB
-	config Config
+	db       *gorm.DB
+	config   Config
+	replicas *replicaResolver
 }
 
 func New(opts ...ConfigOption) (*DbConnection, error) {
@@ -26,7 +27,18 @@ func New(opts ...ConfigOption) (*DbConnection, error) {
 			fn(&cfg)
 		}
 	}
-	if err := checkRequiredDatabaseConfig(cfg.Driver, cfg.Host, cfg.Port, cfg.Username, cfg.Password,
+	if err := cfg.applyURL(); err != nil {
+		return nil, err
+	}
+	if err := cfg.loadPasswordFile(); err != nil {
+		return nil, err
+	}
+	if cfg.Driver == SqliteDriver {
+		// SQLite only needs the database file name
+		if cfg.Name == "" {
+			return nil, ErrConfigDatabaseNameRequired
+		}
+	} else if err := checkRequiredDatabaseConfig(cfg.Driver, cfg.Host, cfg.Port, cfg.Username, cfg.Password,
 		cfg.Name); err != nil {
 		return nil, err
 	}
@@ -36,13 +48,40 @@ func New(opts ...ConfigOption) (*DbConnection, error) {
 		return nil, err
 	}
 
-	return &DbConnection{db: db}, nil
+	connection := &DbConnection{db: db, config: cfg}
+
+	if len(cfg.Replicas) > 0 {
+		replicas, err := cfg.connectReplicas()
+		if err != nil {
+			return nil, err
+		}
+		if err := db.Use(replicas); err != nil {
+			return nil, err
+		}
+		connection.replicas = replicas
+	}
+
+	return connection, nil
 }
 
 func (r *DbConnection) Conn() *gorm.DB {
 	return r.db
 }
 
+// Close stops the replica health check and closes the primary and replica pools.
+func (r *DbConnection) Close() error {
+	if r.replicas != nil {
+		if err := r.replicas.close(); err != nil {
+			return err
+		}
+	}
+	dbConn, err := r.db.DB()
+	if err != nil {
+		return err
+	}
+	return dbConn.Close()
+}
+
 func checkRequiredDatabaseConfig(driver, host, port, username, password, name string) error {
 	if driver == "" {
 		return ErrConfigDriverRequired
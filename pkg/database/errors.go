@@ -0,0 +1,70 @@
+package database
+
+import (
+	"errors"
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgconn"
+	mssql "github.com/microsoft/go-mssqldb"
+	"strings"
+)
+
+const (
+	postgresUniqueViolationCode     = "23505"
+	mysqlDuplicateEntryNumber       = 1062
+	sqlServerDuplicateKeyNumber     = 2601
+	sqlServerUniqueConstraintNumber = 2627
+	sqliteUniqueViolationPrefix     = "UNIQUE constraint failed: "
+)
+
+// UniqueViolation reports whether err is a unique constraint violation raised by
+// the database driver and returns the name of the violated constraint or index.
+// SQLite does not report the index name, the violated "table.column" is returned instead.
+func UniqueViolation(err error) (string, bool) {
+	var (
+		pgError    *pgconn.PgError
+		mysqlError *mysql.MySQLError
+		mssqlError mssql.Error
+	)
+
+	switch {
+	case errors.As(err, &pgError):
+		if pgError.Code == postgresUniqueViolationCode {
+			return pgError.ConstraintName, true
+		}
+	case errors.As(err, &mysqlError):
+		if mysqlError.Number == mysqlDuplicateEntryNumber {
+			// Duplicate entry 'value' for key 'table.index_name'
+			name := quotedNameAfter(mysqlError.Message, "for key ")
+			if i := strings.LastIndex(name, "."); i >= 0 {
+				name = name[i+1:]
+			}
+			return name, true
+		}
+	case errors.As(err, &mssqlError):
+		if mssqlError.Number == sqlServerDuplicateKeyNumber {
+			// Cannot insert duplicate key row in object 'table' with unique index 'index_name'.
+			return quotedNameAfter(mssqlError.Message, "unique index "), true
+		}
+		if mssqlError.Number == sqlServerUniqueConstraintNumber {
+			// Violation of UNIQUE KEY constraint 'constraint_name'.
+			return quotedNameAfter(mssqlError.Message, "constraint "), true
+		}
+	case err != nil && strings.Contains(err.Error(), sqliteUniqueViolationPrefix):
+		// UNIQUE constraint failed: table.column
+		message := err.Error()
+		return message[strings.Index(message, sqliteUniqueViolationPrefix)+len(sqliteUniqueViolationPrefix):], true
+	}
+	return "", false
+}
+
+func quotedNameAfter(message, prefix string) string {
+	i := strings.Index(message, prefix+"'")
+	if i < 0 {
+		return ""
+	}
+	name := message[i+len(prefix)+1:]
+	if j := strings.Index(name, "'"); j >= 0 {
+		name = name[:j]
+	}
+	return name
+}
@@ -0,0 +1,234 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+const (
+	FilterEqual          = "eq"
+	FilterNotEqual       = "ne"
+	FilterGreater        = "gt"
+	FilterGreaterOrEqual = "gte"
+	FilterLess           = "lt"
+	FilterLessOrEqual    = "lte"
+	FilterIn             = "in"
+	FilterLike           = "like"
+	FilterIsNull         = "is_null"
+
+	// filterTag struct tag listing the operators allowed on a field, e.g.
+	// qfilter:"eq,in,like". Fields without the tag can't be filtered.
+	filterTag = "qfilter"
+)
+
+const (
+	FilterReasonUnknownField    = "unknown_field"
+	FilterReasonUnknownOperator = "unknown_operator"
+	FilterReasonInvalidValue    = "invalid_value"
+)
+
+var ErrInvalidFilter = errors.New("filter is invalid")
+
+var filterKeyPattern = regexp.MustCompile(`^filter\[([^\[\]]+)\](?:\[([^\[\]]+)\])?$`)
+
+var filterComparisons = map[string]string{
+	FilterEqual:          "=",
+	FilterNotEqual:       "<>",
+	FilterGreater:        ">",
+	FilterGreaterOrEqual: ">=",
+	FilterLess:           "<",
+	FilterLessOrEqual:    "<=",
+}
+
+// Filter condition on a field parsed from a filter[field][operator]=value query
+// parameter.
+type Filter struct {
+	Field    string
+	Operator string
+	Values   []string
+}
+
+// FilterError reports a filter on a field or with an operator the resource
+// doesn't allow, or a value that can't be converted to the field type.
+type FilterError struct {
+	Field    string
+	Operator string
+	Reason   string
+}
+
+func (e *FilterError) Error() string {
+	return fmt.Sprintf("filter[%s][%s] is invalid: %s", e.Field, e.Operator, e.Reason)
+}
+
+func (e *FilterError) Is(target error) bool {
+	return target == ErrInvalidFilter
+}
+
+// ParseFilters reads the filters of a query string:
+//
+//	filter[email]=alice@test.com
+//	filter[created_at][gte]=2026-01-01
+//	filter[id][in]=1,2,3
+//	filter[mobile_phone][is_null]=true
+//
+// The operator defaults to eq. Parameters are returned sorted by key so the
+// generated SQL is deterministic.
+func ParseFilters(query url.Values) ([]Filter, error) {
+	var keys []string
+	for key := range query {
+		if strings.HasPrefix(key, "filter") {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	var filters []Filter
+	for _, key := range keys {
+		matches := filterKeyPattern.FindStringSubmatch(key)
+		if matches == nil {
+			return nil, &FilterError{Field: key, Reason: FilterReasonUnknownField}
+		}
+		filter := Filter{Field: matches[1], Operator: matches[2], Values: query[key]}
+		if filter.Operator == "" {
+			filter.Operator = FilterEqual
+		}
+		if filter.Operator == FilterIn {
+			var values []string
+			for _, value := range filter.Values {
+				values = append(values, strings.Split(value, ",")...)
+			}
+			filter.Values = values
+		}
+		filters = append(filters, filter)
+	}
+	return filters, nil
+}
+
+// ApplyFilters validates filters against the qfilter tags of model and adds
+// their conditions to db. A *FilterError is returned for unknown fields,
+// operators not listed in the tag and values not matching the field type.
+func ApplyFilters(db *gorm.DB, model interface{}, filters []Filter) (*gorm.DB, error) {
+	if len(filters) == 0 {
+		return db, nil
+	}
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return db, err
+	}
+
+	var expressions []clause.Expression
+	for _, filter := range filters {
+		field := stmt.Schema.LookUpField(filter.Field)
+		if field == nil || field.DBName == "" || field.Tag.Get(filterTag) == "" || field.Tag.Get(filterTag) == "-" {
+			return db, &FilterError{Field: filter.Field, Operator: filter.Operator, Reason: FilterReasonUnknownField}
+		}
+		if !isAllowedFilter(filter.Operator, strings.Split(field.Tag.Get(filterTag), ",")) {
+			return db, &FilterError{Field: filter.Field, Operator: filter.Operator, Reason: FilterReasonUnknownOperator}
+		}
+		expression, err := filterExpression(db, field, filter)
+		if err != nil {
+			return db, &FilterError{Field: filter.Field, Operator: filter.Operator, Reason: FilterReasonInvalidValue}
+		}
+		expressions = append(expressions, expression)
+	}
+	return db.Where(clause.And(expressions...)), nil
+}
+
+func isAllowedFilter(operator string, allowed []string) bool {
+	for _, item := range allowed {
+		if strings.TrimSpace(item) == operator {
+			return true
+		}
+	}
+	return false
+}
+
+func filterExpression(db *gorm.DB, field *schema.Field, filter Filter) (clause.Expression, error) {
+	column := db.Statement.Quote(field.DBName)
+	if len(filter.Values) == 0 {
+		return nil, ErrInvalidFilter
+	}
+
+	switch filter.Operator {
+	case FilterIsNull:
+		isNull, err := strconv.ParseBool(filter.Values[0])
+		if err != nil {
+			return nil, err
+		}
+		if isNull {
+			return clause.Expr{SQL: column + " IS NULL"}, nil
+		}
+		return clause.Expr{SQL: column + " IS NOT NULL"}, nil
+	case FilterLike:
+		if field.IndirectFieldType.Kind() != reflect.String {
+			return nil, ErrInvalidFilter
+		}
+		dialect := db.Dialector.Name()
+		operator, ok := searchOperators[dialect]
+		if !ok {
+			operator = defaultSearchOperator
+		}
+		term := filter.Values[0]
+		if operator.lowerCase {
+			term = strings.ToLower(term)
+		}
+		return clause.Expr{SQL: fmt.Sprintf(operator.format, column), Vars: []interface{}{"%" + EscapeLike(dialect, term) + "%"}}, nil
+	case FilterIn:
+		var values = make([]interface{}, len(filter.Values))
+		for i, value := range filter.Values {
+			converted, err := convertFilterValue(field, value)
+			if err != nil {
+				return nil, err
+			}
+			values[i] = converted
+		}
+		return clause.Expr{SQL: column + " IN ?", Vars: []interface{}{values}}, nil
+	default:
+		comparison, ok := filterComparisons[filter.Operator]
+		if !ok {
+			return nil, ErrInvalidFilter
+		}
+		value, err := convertFilterValue(field, filter.Values[0])
+		if err != nil {
+			return nil, err
+		}
+		return clause.Expr{SQL: column + " " + comparison + " ?", Vars: []interface{}{value}}, nil
+	}
+}
+
+// convertFilterValue converts a query string value to the type of field. Times
+// are accepted as RFC 3339 timestamps or dates.
+func convertFilterValue(field *schema.Field, value string) (interface{}, error) {
+	if field.IndirectFieldType == reflect.TypeOf(time.Time{}) {
+		if parsed, err := time.Parse(time.RFC3339, value); err == nil {
+			return parsed, nil
+		}
+		return time.Parse("2006-01-02", value)
+	}
+
+	switch field.IndirectFieldType.Kind() {
+	case reflect.String:
+		return value, nil
+	case reflect.Bool:
+		return strconv.ParseBool(value)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.ParseInt(value, 10, 64)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.ParseUint(value, 10, 64)
+	case reflect.Float32, reflect.Float64:
+		return strconv.ParseFloat(value, 64)
+	default:
+		return nil, ErrInvalidFilter
+	}
+}
@@ -0,0 +1,75 @@
+package database
+
+import (
+	"fmt"
+	"gorm.io/gorm"
+	"strings"
+)
+
+// PartialUniqueIndex unique index over the rows of Table matching Where, like
+// the rows not soft-deleted. MySQL has no partial index, the index is built
+// there over the generated column Generated in place of the last of Columns,
+// holding its value on the rows matching Where and NULL on the others, which a
+// unique index doesn't compare.
+type PartialUniqueIndex struct {
+	Table   string
+	Name    string
+	Columns []string
+	// Where condition of the indexed rows, written in SQL understood by every
+	// supported database
+	Where string
+	// Generated name of the column generated on MySQL
+	Generated string
+	// GeneratedType type of the column generated on MySQL, the type of the
+	// column it replaces
+	GeneratedType string
+}
+
+// Create creates the index when it doesn't exist yet.
+func (i PartialUniqueIndex) Create(db *gorm.DB) error {
+	migrator := db.Migrator()
+	if migrator.HasIndex(i.Table, i.Name) {
+		return nil
+	}
+
+	var columns = make([]string, len(i.Columns))
+	copy(columns, i.Columns)
+	if db.Dialector.Name() != "mysql" {
+		return db.Exec(fmt.Sprintf("CREATE UNIQUE INDEX %s ON %s (%s) WHERE %s",
+			db.Statement.Quote(i.Name), db.Statement.Quote(i.Table), quoteColumns(db, columns), i.Where)).Error
+	}
+
+	last := columns[len(columns)-1]
+	if !migrator.HasColumn(i.Table, i.Generated) {
+		err := db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s AS (CASE WHEN %s THEN %s END) STORED",
+			db.Statement.Quote(i.Table), db.Statement.Quote(i.Generated), i.GeneratedType, i.Where, db.Statement.Quote(last))).Error
+		if err != nil {
+			return err
+		}
+	}
+	columns[len(columns)-1] = i.Generated
+	return db.Exec(fmt.Sprintf("CREATE UNIQUE INDEX %s ON %s (%s)",
+		db.Statement.Quote(i.Name), db.Statement.Quote(i.Table), quoteColumns(db, columns))).Error
+}
+
+// DropIndexes drops the indexes of table among names which exist.
+func DropIndexes(db *gorm.DB, table string, names ...string) error {
+	migrator := db.Migrator()
+	for _, name := range names {
+		if !migrator.HasIndex(table, name) {
+			continue
+		}
+		if err := migrator.DropIndex(table, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func quoteColumns(db *gorm.DB, columns []string) string {
+	var quoted = make([]string, len(columns))
+	for k, column := range columns {
+		quoted[k] = db.Statement.Quote(column)
+	}
+	return strings.Join(quoted, ", ")
+}
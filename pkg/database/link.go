@@ -0,0 +1,64 @@
+package database
+
+import (
+	"net/url"
+	"strconv"
+)
+
+// LinkBuilder builds the links to the other pages of a Paginator, so the same
+// listing can be served over HTTP, gRPC or from a background job.
+type LinkBuilder interface {
+	PageLink(page int) (string, error)
+	CursorLink(cursor string) (string, error)
+}
+
+// URLLinkBuilder builds the links from the URL of the listing, keeping its other
+// query parameters such as search, orderBy and filters.
+type URLLinkBuilder struct {
+	URL *url.URL
+}
+
+func NewURLLinkBuilder(link *url.URL) URLLinkBuilder {
+	return URLLinkBuilder{URL: link}
+}
+
+// ParseURLLinkBuilder parses rawURL into a URLLinkBuilder.
+func ParseURLLinkBuilder(rawURL string) (URLLinkBuilder, error) {
+	link, err := url.Parse(rawURL)
+	if err != nil {
+		return URLLinkBuilder{}, err
+	}
+	return NewURLLinkBuilder(link), nil
+}
+
+func (b URLLinkBuilder) PageLink(page int) (string, error) {
+	values := b.URL.Query()
+	values.Del("cursor")
+	values.Set("page", strconv.Itoa(page))
+	return b.withQuery(values), nil
+}
+
+func (b URLLinkBuilder) CursorLink(cursor string) (string, error) {
+	values := b.URL.Query()
+	values.Del("page")
+	values.Set("cursor", cursor)
+	return b.withQuery(values), nil
+}
+
+func (b URLLinkBuilder) withQuery(values url.Values) string {
+	link := *b.URL
+	link.RawQuery = values.Encode()
+	return link.String()
+}
+
+// NopLinkBuilder returns empty links, for listings without an address such as
+// exports and background jobs.
+type NopLinkBuilder struct{}
+
+func (NopLinkBuilder) PageLink(int) (string, error) {
+	return "", nil
+}
+
+func (NopLinkBuilder) CursorLink(string) (string, error) {
+	return "", nil
+}
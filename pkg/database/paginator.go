@@ -4,18 +4,29 @@ import (
 	"context"
 	"gorm.io/gorm/clause"
 	"math"
-	"net/http"
-	"net/url"
 	"strconv"
 
 	"gorm.io/gorm"
 )
 
+const (
+	DefaultPageSize    = 10
+	DefaultMaxPageSize = 100
+)
+
+// maxOffset largest number of records skipped to read a page, the pages beyond
+// it are read as the page at it so the offset can't overflow.
+const maxOffset = math.MaxInt32
+
+// MaxPageSize upper bound of the page size of new Paginators, set it at startup
+// to change the default.
+var MaxPageSize = DefaultMaxPageSize
+
 // Paginator structure containing pagination information and result records.
 // Can be sent to the client directly.
 type Paginator struct {
-	DB      *gorm.DB      `json:"-"`
-	Request *http.Request `json:"-"`
+	DB          *gorm.DB    `json:"-"`
+	LinkBuilder LinkBuilder `json:"-"`
 
 	Records interface{} `json:"records"`
 
@@ -24,11 +35,19 @@ type Paginator struct {
 	rawCountQuery     string
 	rawCountQueryVars []interface{}
 
-	MaxPage        int64 `json:"max_page"`
-	Total          int64 `json:"total"`
-	PageSize       int   `json:"page_size"`
-	CurrentPage    int   `json:"current_page"`
-	Links          Links `json:"links"`
+	sortKeys   []SortKey
+	cursorMode bool
+	cursor     string
+	countTotal bool
+
+	maxPageSize int
+
+	MaxPage        int64   `json:"max_page"`
+	Total          int64   `json:"total"`
+	PageSize       int     `json:"page_size"`
+	CurrentPage    int     `json:"current_page"`
+	Links          Links   `json:"links"`
+	Cursors        Cursors `json:"cursors"`
 	loadedPageInfo bool
 }
 
@@ -51,21 +70,30 @@ func paginateScope(page, pageSize int) func(db *gorm.DB) *gorm.DB {
 // Given DB transaction can contain clauses already, such as WHERE, if you want to
 // filter results.
 //
+// Links to the other pages are built by links, use NopLinkBuilder when the
+// listing has no address. The page and page size are clamped when executing the
+// query: a page below 1 reads the first page, a page whose offset overflows
+// reads the last page reachable, a page size below 1 uses DefaultPageSize and a
+// page size above the maximum uses the maximum.
+//
 //  articles := []model.Article{}
-//  tx := database.Conn().Where("title LIKE ?", "%"+sqlutil.EscapeLike(search)+"%")
-//  paginator := database.NewPaginator(tx, page, pageSize, &articles)
-//  result := paginator.Find()
-//  if response.HandleDatabaseError(result) {
-//      response.JSON(http.StatusOK, paginator)
+//  tx := database.Conn().Scopes(database.Search(search, "title"))
+//  paginator := database.NewPaginator(tx, database.NewURLLinkBuilder(request.URL), page, pageSize, &articles)
+//  if err := paginator.Find(ctx); err != nil {
+//      return err
 //  }
 //
-func NewPaginator(db *gorm.DB, request *http.Request, page, pageSize int, dest interface{}) *Paginator {
+func NewPaginator(db *gorm.DB, links LinkBuilder, page, pageSize int, dest interface{}) *Paginator {
+	if links == nil {
+		links = NopLinkBuilder{}
+	}
 	return &Paginator{
 		DB:          db,
-		Request:     request,
+		LinkBuilder: links,
 		CurrentPage: page,
 		PageSize:    pageSize,
 		Records:     dest,
+		maxPageSize: MaxPageSize,
 	}
 }
 
@@ -77,12 +105,86 @@ func (p *Paginator) Raw(query string, vars []interface{}, countQuery string, cou
 	p.rawQuery = query
 	p.rawQueryVars = vars
 	p.rawCountQuery = countQuery
-	p.rawCountQueryVars = vars
+	p.rawCountQueryVars = countVars
+	return p
+}
+
+// MaxPageSize overrides the maximum page size of the Paginator.
+func (p *Paginator) MaxPageSize(size int) *Paginator {
+	p.maxPageSize = size
+	return p
+}
+
+// clamp replaces the out of range page and page size values.
+func (p *Paginator) clamp() {
+	if p.CurrentPage < 1 {
+		p.CurrentPage = 1
+	}
+	if p.PageSize < 1 {
+		p.PageSize = DefaultPageSize
+	}
+	if p.maxPageSize > 0 && p.PageSize > p.maxPageSize {
+		p.PageSize = p.maxPageSize
+	}
+	if maxPage := maxOffset/p.PageSize + 1; p.CurrentPage > maxPage {
+		p.CurrentPage = maxPage
+	}
+}
+
+// OrderBy set the columns the records are sorted by, the primary key is always
+// appended as the last sort column.
+func (p *Paginator) OrderBy(keys ...SortKey) *Paginator {
+	p.sortKeys = keys
+	return p
+}
+
+// Cursor switches the Paginator to keyset pagination, reading the page located by
+// the opaque cursor returned in Cursors. An empty cursor reads the first page.
+// Unlike OFFSET pagination the cost of a page doesn't grow with its position and
+// the count query is skipped unless enabled with CountTotal.
+func (p *Paginator) Cursor(cursor string) *Paginator {
+	p.cursorMode = true
+	p.cursor = cursor
+	return p
+}
+
+// CountTotal enables the count query in cursor mode.
+func (p *Paginator) CountTotal(enabled bool) *Paginator {
+	p.countTotal = enabled
 	return p
 }
 
+// IsCursorMode Returns true if the Paginator uses keyset pagination.
+func (p *Paginator) IsCursorMode() bool {
+	return p.cursorMode
+}
+
 // UpdatePageInfo executes count request to calculate the `Total` and `MaxPage`.
-func (p *Paginator) UpdatePageInfo(ctx context.Context) {
+func (p *Paginator) UpdatePageInfo(ctx context.Context) error {
+	p.clamp()
+	if err := p.updateTotal(ctx); err != nil {
+		return err
+	}
+
+	var err error
+	if p.Links.First, err = p.PageLinkFirst(); err != nil {
+		return err
+	}
+	if p.Links.Next, err = p.PageLinkNext(); err != nil {
+		return err
+	}
+	if p.Links.Prev, err = p.PageLinkPrev(); err != nil {
+		return err
+	}
+	if p.Links.Last, err = p.PageLinkLast(); err != nil {
+		return err
+	}
+
+	p.loadedPageInfo = true
+	return nil
+}
+
+func (p *Paginator) updateTotal(ctx context.Context) error {
 	count := int64(0)
 	db := p.DB.WithContext(ctx).Session(&gorm.Session{})
 	prevPreloads := db.Statement.Preloads
@@ -99,32 +201,36 @@ func (p *Paginator) UpdatePageInfo(ctx context.Context) {
 		err = db.Model(p.Records).Count(&count).Error
 	}
 	if err != nil {
-		panic(err)
+		return err
 	}
 	p.Total = count
 	p.MaxPage = int64(math.Ceil(float64(count) / float64(p.PageSize)))
 	if p.MaxPage == 0 {
 		p.MaxPage = 1
 	}
-	p.Links.First = p.PageLinkFirst()
-	p.Links.Next = p.PageLinkNext()
-	p.Links.Prev = p.PageLinkPrev()
-	p.Links.Last = p.PageLinkLast()
-
-	p.loadedPageInfo = true
+	return nil
 }
 
 // Find requests page information (total records and max page) and
 // executes the transaction. The Paginate struct is updated automatically, as
 // well as the destination slice given in NewPaginator().
-func (p *Paginator) Find(ctx context.Context) *gorm.DB {
+func (p *Paginator) Find(ctx context.Context) error {
+	if p.cursorMode {
+		return p.findByCursor(ctx)
+	}
 	if !p.loadedPageInfo {
-		p.UpdatePageInfo(ctx)
+		if err := p.UpdatePageInfo(ctx); err != nil {
+			return err
+		}
 	}
 	if p.rawQuery != "" {
-		return p.rawStatement(ctx).Scan(p.Records)
+		return p.rawStatement(ctx).Scan(p.Records).Error
+	}
+	keys, _, err := p.sortFields(p.DB)
+	if err != nil {
+		return err
 	}
-	return p.DB.Scopes(paginateScope(p.CurrentPage, p.PageSize)).Find(p.Records)
+	return p.DB.WithContext(ctx).Scopes(orderScope(keys, false), paginateScope(p.CurrentPage, p.PageSize)).Find(p.Records).Error
 }
 
 func (p *Paginator) rawStatement(ctx context.Context) *gorm.DB {
@@ -163,45 +269,39 @@ func (p *Paginator) rawStatement(ctx context.Context) *gorm.DB {
 	return db
 }
 
-func (p *Paginator) PageLink(page int) string {
-	link, err := url.ParseRequestURI(p.Request.URL.String())
-	if err != nil {
-		panic(err)
-	}
-	values := link.Query()
-	if page < 1 {
-		values.Set("page", strconv.Itoa(page))
-	} else {
-		values.Set("page", strconv.Itoa(page))
-	}
+// PageLink Returns URL to the given page.
+func (p *Paginator) PageLink(page int) (string, error) {
+	return p.LinkBuilder.PageLink(page)
+}
 
-	link.RawQuery = values.Encode()
-	return link.String()
+// CursorLink Returns URL to the page located by cursor.
+func (p *Paginator) CursorLink(cursor string) (string, error) {
+	return p.LinkBuilder.CursorLink(cursor)
 }
 
 // PageLinkPrev Returns URL to the previous page.
-func (p *Paginator) PageLinkPrev() (link string) {
+func (p *Paginator) PageLinkPrev() (string, error) {
 	if p.HasPrev() {
-		link = p.PageLink(p.CurrentPage - 1)
+		return p.PageLink(p.CurrentPage - 1)
 	}
-	return
+	return "", nil
 }
 
 // PageLinkNext Returns URL to the next page.
-func (p *Paginator) PageLinkNext() (link string) {
+func (p *Paginator) PageLinkNext() (string, error) {
 	if p.HasNext() {
-		link = p.PageLink(p.CurrentPage + 1)
+		return p.PageLink(p.CurrentPage + 1)
 	}
-	return
+	return "", nil
 }
 
 // PageLinkFirst Returns URL to the first page.
-func (p *Paginator) PageLinkFirst() (link string) {
+func (p *Paginator) PageLinkFirst() (string, error) {
 	return p.PageLink(1)
 }
 
 // PageLinkLast Returns URL to the last page.
-func (p *Paginator) PageLinkLast() (link string) {
+func (p *Paginator) PageLinkLast() (string, error) {
 	return p.PageLink(int(p.MaxPage))
 }
 
@@ -0,0 +1,151 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	replicaResolverName     = "database:replica_resolver"
+	replicaResolverCallback = "database:replica"
+)
+
+type primaryContextKey struct{}
+
+// WithPrimary returns a copy of ctx forcing every query executed with it to the
+// primary database, e.g. to read your own writes right after an update.
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryContextKey{}, true)
+}
+
+// UsePrimary reports whether the queries executed with ctx are forced to the
+// primary database by WithPrimary.
+func UsePrimary(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	forced, _ := ctx.Value(primaryContextKey{}).(bool)
+	return forced
+}
+
+type replica struct {
+	host    string
+	pool    *sql.DB
+	healthy int32
+}
+
+func (r *replica) isHealthy() bool {
+	return atomic.LoadInt32(&r.healthy) == 1
+}
+
+func (r *replica) setHealthy(healthy bool) {
+	var value int32
+	if healthy {
+		value = 1
+	}
+	atomic.StoreInt32(&r.healthy, value)
+}
+
+// replicaResolver is a gorm plugin routing read queries to the healthy replicas
+// in round-robin order. Writes, queries inside a transaction and queries forced
+// to the primary with WithPrimary keep using the primary connection.
+type replicaResolver struct {
+	replicas []*replica
+	next     uint32
+	interval time.Duration
+	stop     chan struct{}
+	once     sync.Once
+}
+
+func newReplicaResolver(replicas []*replica, interval time.Duration) *replicaResolver {
+	if interval <= 0 {
+		interval = DefaultReplicaHealthCheckInterval * time.Second
+	}
+	return &replicaResolver{
+		replicas: replicas,
+		interval: interval,
+		stop:     make(chan struct{}),
+	}
+}
+
+func (r *replicaResolver) Name() string {
+	return replicaResolverName
+}
+
+func (r *replicaResolver) Initialize(db *gorm.DB) error {
+	r.checkHealth()
+	go r.watchHealth()
+
+	if err := db.Callback().Query().Before("gorm:query").Register(replicaResolverCallback, r.route); err != nil {
+		return err
+	}
+	return db.Callback().Row().Before("gorm:row").Register(replicaResolverCallback, r.route)
+}
+
+func (r *replicaResolver) route(db *gorm.DB) {
+	if db.Error != nil || UsePrimary(db.Statement.Context) {
+		return
+	}
+	if _, inTransaction := db.Statement.ConnPool.(gorm.TxCommitter); inTransaction {
+		return
+	}
+	// raw statements are only routed when they are plain reads
+	if db.Statement.SQL.Len() > 0 && !strings.HasPrefix(strings.ToUpper(strings.TrimSpace(db.Statement.SQL.String())), "SELECT") {
+		return
+	}
+	if pool := r.pick(); pool != nil {
+		db.Statement.ConnPool = pool
+	}
+}
+
+// pick returns the next healthy replica, or nil when all replicas are ejected.
+func (r *replicaResolver) pick() *sql.DB {
+	for i := 0; i < len(r.replicas); i++ {
+		candidate := r.replicas[int(atomic.AddUint32(&r.next, 1)-1)%len(r.replicas)]
+		if candidate.isHealthy() {
+			return candidate.pool
+		}
+	}
+	return nil
+}
+
+func (r *replicaResolver) watchHealth() {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			r.checkHealth()
+		case <-r.stop:
+			return
+		}
+	}
+}
+
+// checkHealth ejects replicas failing to answer a ping and restores recovered ones.
+func (r *replicaResolver) checkHealth() {
+	for _, item := range r.replicas {
+		ctx, cancel := context.WithTimeout(context.Background(), r.interval)
+		item.setHealthy(item.pool.PingContext(ctx) == nil)
+		cancel()
+	}
+}
+
+func (r *replicaResolver) close() error {
+	var err error
+	r.once.Do(func() {
+		close(r.stop)
+		for _, item := range r.replicas {
+			if closeErr := item.pool.Close(); closeErr != nil {
+				err = closeErr
+			}
+		}
+	})
+	return err
+}
@@ -0,0 +1,98 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"testing"
+	"time"
+)
+
+type replicaItem struct {
+	ID   int
+	Name string
+}
+
+// newReplicaTestPool opens a private in-memory database holding a single item
+// named after it.
+func newReplicaTestPool(t *testing.T, name string) *sql.DB {
+	pool, err := sql.Open(sqlite.DriverName, ":memory:")
+	assert.NoError(t, err)
+	pool.SetMaxOpenConns(1)
+	_, err = pool.Exec("CREATE TABLE replica_items (id INTEGER PRIMARY KEY, name TEXT)")
+	assert.NoError(t, err)
+	_, err = pool.Exec("INSERT INTO replica_items (name) VALUES (?)", name)
+	assert.NoError(t, err)
+	return pool
+}
+
+func readItemName(t *testing.T, db *gorm.DB) string {
+	var item replicaItem
+	assert.NoError(t, db.Table("replica_items").Order("id").Take(&item).Error)
+	return item.Name
+}
+
+func TestReplicaResolver_InMemory(t *testing.T) {
+	primary, err := NewInMemory()
+	assert.NoError(t, err)
+	defer primary.Close()
+	db := primary.Conn()
+	assert.NoError(t, db.Exec("CREATE TABLE replica_items (id INTEGER PRIMARY KEY, name TEXT)").Error)
+	assert.NoError(t, db.Exec("INSERT INTO replica_items (name) VALUES (?)", "primary").Error)
+
+	first := &replica{host: "first", pool: newReplicaTestPool(t, "first")}
+	second := &replica{host: "second", pool: newReplicaTestPool(t, "second")}
+	resolver := newReplicaResolver([]*replica{first, second}, time.Hour)
+	assert.NoError(t, db.Use(resolver))
+	defer resolver.close()
+
+	ctx := context.TODO()
+
+	t.Run("round-robin", func(t *testing.T) {
+		assert.Equal(t, "first", readItemName(t, db))
+		assert.Equal(t, "second", readItemName(t, db))
+		assert.Equal(t, "first", readItemName(t, db))
+
+		var name string
+		assert.NoError(t, db.Raw("SELECT name FROM replica_items").Row().Scan(&name))
+		assert.Equal(t, "second", name)
+	})
+
+	t.Run("writes-to-primary", func(t *testing.T) {
+		assert.NoError(t, db.Exec("UPDATE replica_items SET name = ?", "updated").Error)
+
+		assert.Equal(t, "updated", readItemName(t, db.WithContext(WithPrimary(ctx))))
+		assert.Equal(t, "first", readItemName(t, db))
+	})
+
+	t.Run("transaction-on-primary", func(t *testing.T) {
+		err := NewTxManager(db).WithinTransaction(ctx, func(ctx context.Context) error {
+			assert.Equal(t, "updated", readItemName(t, FromContext(ctx, db)))
+			return nil
+		})
+		assert.NoError(t, err)
+	})
+
+	t.Run("eject-and-restore", func(t *testing.T) {
+		// a replica marked unhealthy answers the next ping and is restored
+		second.setHealthy(false)
+		assert.Equal(t, "first", readItemName(t, db))
+		assert.Equal(t, "first", readItemName(t, db))
+		resolver.checkHealth()
+		assert.True(t, second.isHealthy())
+
+		// a replica failing to answer the ping is ejected
+		assert.NoError(t, second.pool.Close())
+		resolver.checkHealth()
+		assert.False(t, second.isHealthy())
+		assert.True(t, first.isHealthy())
+		assert.Equal(t, "first", readItemName(t, db))
+		assert.Equal(t, "first", readItemName(t, db))
+
+		// the primary answers when every replica is ejected
+		first.setHealthy(false)
+		assert.Equal(t, "updated", readItemName(t, db))
+	})
+}
@@ -0,0 +1,71 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// likeEscape escape character of the LIKE patterns built by Search. A character
+// without special meaning in string literals keeps the ESCAPE clause portable.
+const likeEscape = "!"
+
+type searchOperator struct {
+	// format receives the quoted column
+	format    string
+	lowerCase bool
+}
+
+// searchOperators case-insensitive match per Dialector.Name().
+var searchOperators = map[string]searchOperator{
+	"postgres": {format: "%s ILIKE ? ESCAPE '" + likeEscape + "'"},
+	// default mysql collations are case-insensitive
+	"mysql":     {format: "%s LIKE ? ESCAPE '" + likeEscape + "'"},
+	"sqlserver": {format: "%s COLLATE Latin1_General_CI_AS LIKE ? ESCAPE '" + likeEscape + "'"},
+	// sqlite LIKE is case-insensitive for ASCII characters
+	"sqlite": {format: "%s LIKE ? ESCAPE '" + likeEscape + "'"},
+}
+
+var defaultSearchOperator = searchOperator{format: "LOWER(%s) LIKE ? ESCAPE '" + likeEscape + "'", lowerCase: true}
+
+// EscapeLike escapes the LIKE wildcards of value for the given dialect so user
+// input is matched literally. The result must be used with ESCAPE '!'.
+func EscapeLike(dialect, value string) string {
+	var replacements = []string{likeEscape, likeEscape + likeEscape, "%", likeEscape + "%", "_", likeEscape + "_"}
+	if dialect == "sqlserver" {
+		replacements = append(replacements, "[", likeEscape+"[")
+	}
+	return strings.NewReplacer(replacements...).Replace(value)
+}
+
+// Search returns a scope matching term case-insensitively against any of the
+// given columns. The conditions are grouped in a single WHERE expression so they
+// combine with the other clauses of the query using AND.
+//
+//	tx := database.Conn().Scopes(database.Search(search, "name", "email"))
+func Search(term string, columns ...string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if term == "" || len(columns) == 0 {
+			return db
+		}
+
+		dialect := db.Dialector.Name()
+		operator, ok := searchOperators[dialect]
+		if !ok {
+			operator = defaultSearchOperator
+		}
+		if operator.lowerCase {
+			term = strings.ToLower(term)
+		}
+		pattern := "%" + EscapeLike(dialect, term) + "%"
+
+		var conditions = make([]string, len(columns))
+		var vars = make([]interface{}, len(columns))
+		for i, column := range columns {
+			conditions[i] = fmt.Sprintf(operator.format, db.Statement.Quote(column))
+			vars[i] = pattern
+		}
+		return db.Where("("+strings.Join(conditions, " OR ")+")", vars...)
+	}
+}
@@ -0,0 +1,107 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+var ErrInvalidSort = errors.New("sort field is not allowed")
+
+// SortKey column of the ORDER BY clause of a Paginator.
+type SortKey struct {
+	Column string
+	Desc   bool
+}
+
+// SortError reports a sort field outside the allowed fields of the resource.
+type SortError struct {
+	Field   string
+	Allowed []string
+}
+
+func (e *SortError) Error() string {
+	return fmt.Sprintf("sort field %q is not allowed, allowed fields: %s", e.Field, strings.Join(e.Allowed, ", "))
+}
+
+func (e *SortError) Is(target error) bool {
+	return target == ErrInvalidSort
+}
+
+// ParseSort parses a comma separated list of sort fields, a field prefixed with
+// "-" is sorted in descending order:
+//
+//	-created_at,name
+//
+// Every field must be one of allowed, otherwise a *SortError is returned.
+func ParseSort(orderBy string, allowed []string) ([]SortKey, error) {
+	if strings.TrimSpace(orderBy) == "" {
+		return nil, nil
+	}
+	var keys []SortKey
+	var seen = map[string]bool{}
+	for _, item := range strings.Split(orderBy, ",") {
+		item = strings.TrimSpace(item)
+		key := SortKey{Column: strings.TrimPrefix(item, "+")}
+		if strings.HasPrefix(item, "-") {
+			key = SortKey{Column: item[1:], Desc: true}
+		}
+		if key.Column == "" || seen[key.Column] || !isAllowedSort(key.Column, allowed) {
+			return nil, &SortError{Field: item, Allowed: allowed}
+		}
+		seen[key.Column] = true
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+func isAllowedSort(column string, allowed []string) bool {
+	for _, item := range allowed {
+		if item != "" && item == column {
+			return true
+		}
+	}
+	return false
+}
+
+// sortFields resolves the sort keys to schema fields, the primary key is
+// appended as tie-breaker so every record has a distinct and stable position.
+func (p *Paginator) sortFields(db *gorm.DB) ([]SortKey, []*schema.Field, error) {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(p.Records); err != nil {
+		return nil, nil, err
+	}
+	primaryKey := stmt.Schema.PrioritizedPrimaryField
+
+	var keys []SortKey
+	var fields []*schema.Field
+	for _, key := range p.sortKeys {
+		field := stmt.Schema.LookUpField(key.Column)
+		if field == nil {
+			return nil, nil, &SortError{Field: key.Column}
+		}
+		keys = append(keys, SortKey{Column: field.DBName, Desc: key.Desc})
+		fields = append(fields, field)
+		if field == primaryKey {
+			return keys, fields, nil
+		}
+	}
+	if primaryKey == nil {
+		return keys, fields, nil
+	}
+	return append(keys, SortKey{Column: primaryKey.DBName}), append(fields, primaryKey), nil
+}
+
+// orderScope sorts by keys, reversing every direction when backward is set.
+func orderScope(keys []SortKey, backward bool) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		for _, key := range keys {
+			db = db.Order(clause.OrderByColumn{Column: clause.Column{Name: key.Column}, Desc: key.Desc != backward})
+		}
+		return db
+	}
+}
@@ -0,0 +1,29 @@
+package database
+
+// NewInMemory create a connection to a private in-memory SQLite database and
+// migrates the given models, so repositories and handlers can be tested against
+// a real database without any external service.
+//
+//	db, err := database.NewInMemory(&domain.Customer{})
+//	repository := pg.NewCustomerPgRepository(db.Conn())
+func NewInMemory(models ...interface{}) (*DbConnection, error) {
+	db, err := New(
+		ConfigDriverName(SqliteDriver),
+		ConfigName(":memory:"),
+		ConfigDebugEnabled(false),
+		// the database only lives as long as its connection
+		ConfigMaxOpenConnection(1),
+		ConfigMaxIdleConnection(1),
+		ConfigMaxLifeTimeConnection(0),
+		ConfigMaxIdleTimeConnection(0),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Conn().AutoMigrate(models...); err != nil {
+		// the caller never gets the connection back to close it
+		_ = db.Close()
+		return nil, err
+	}
+	return db, nil
+}
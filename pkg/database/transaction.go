@@ -0,0 +1,57 @@
+package database
+
+import (
+	"context"
+	"gorm.io/gorm"
+)
+
+type txContextKey struct{}
+
+// TxManager runs a unit of work inside a single database transaction.
+type TxManager interface {
+	// WithinTransaction executes fn inside a transaction. The transaction is stored
+	// in the context given to fn so repositories resolving their connection through
+	// FromContext transparently join it. When ctx already carries a transaction, fn
+	// joins the outer one instead of starting a new transaction.
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type gormTxManager struct {
+	db *gorm.DB
+}
+
+// NewTxManager create a new TxManager backed by the given connection.
+func NewTxManager(db *gorm.DB) TxManager {
+	return &gormTxManager{
+		db: db,
+	}
+}
+
+func (m gormTxManager) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := TxFromContext(ctx); ok {
+		return fn(ctx)
+	}
+	return m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(ContextWithTx(ctx, tx))
+	})
+}
+
+// ContextWithTx returns a copy of ctx carrying the given transaction.
+func ContextWithTx(ctx context.Context, tx *gorm.DB) context.Context {
+	return context.WithValue(ctx, txContextKey{}, tx)
+}
+
+// TxFromContext returns the transaction stored in ctx, if any.
+func TxFromContext(ctx context.Context) (*gorm.DB, bool) {
+	tx, ok := ctx.Value(txContextKey{}).(*gorm.DB)
+	return tx, ok
+}
+
+// FromContext returns the transaction stored in ctx or, when there is none,
+// the given connection bound to ctx.
+func FromContext(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := TxFromContext(ctx); ok {
+		return tx.WithContext(ctx)
+	}
+	return db.WithContext(ctx)
+}
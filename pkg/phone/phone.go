@@ -0,0 +1,118 @@
+package phone
+
+import (
+	"errors"
+	"strings"
+)
+
+// DefaultCountryCode country calling code of the numbers written without one.
+const DefaultCountryCode = "62"
+
+var (
+	ErrInvalidNumber  = errors.New("phone number is invalid")
+	ErrUnknownCarrier = errors.New("phone number prefix isn't a mobile carrier prefix")
+)
+
+// indonesianMobilePrefixes prefixes of the Indonesian mobile numbers, following
+// the country code, by carrier.
+var indonesianMobilePrefixes = map[string]string{
+	"811": "Telkomsel", "812": "Telkomsel", "813": "Telkomsel",
+	"821": "Telkomsel", "822": "Telkomsel", "823": "Telkomsel",
+	"851": "Telkomsel", "852": "Telkomsel", "853": "Telkomsel",
+	"814": "Indosat", "815": "Indosat", "816": "Indosat",
+	"855": "Indosat", "856": "Indosat", "857": "Indosat", "858": "Indosat",
+	"817": "XL", "818": "XL", "819": "XL", "859": "XL", "877": "XL", "878": "XL",
+	"831": "Axis", "832": "Axis", "833": "Axis", "838": "Axis",
+	"895": "Three", "896": "Three", "897": "Three", "898": "Three", "899": "Three",
+	"881": "Smartfren", "882": "Smartfren", "883": "Smartfren", "884": "Smartfren",
+	"885": "Smartfren", "886": "Smartfren", "887": "Smartfren", "888": "Smartfren",
+	"889": "Smartfren",
+}
+
+// Normalize returns the E.164 form of a mobile phone number, +6281234567890.
+// Spaces, dashes, dots and parentheses are ignored. Numbers starting with + or 00
+// carry their country code, numbers starting with 62 or without a 0 trunk
+// prefix are Indonesian, like the national numbers starting with 0.
+//
+// Indonesian numbers must be mobile numbers of a known carrier, ErrUnknownCarrier
+// is returned otherwise. Numbers of other countries are only checked for their
+// length.
+func Normalize(number string) (string, error) {
+	digits, international, err := parse(number)
+	if err != nil {
+		return "", err
+	}
+
+	switch {
+	case international:
+	case strings.HasPrefix(digits, "0"):
+		digits = DefaultCountryCode + digits[1:]
+	case !strings.HasPrefix(digits, DefaultCountryCode):
+		digits = DefaultCountryCode + digits
+	}
+
+	// E.164 numbers have at most 15 digits
+	if len(digits) < 8 || len(digits) > 15 || digits[0] == '0' {
+		return "", ErrInvalidNumber
+	}
+	if strings.HasPrefix(digits, "62") {
+		subscriber := strings.TrimPrefix(digits[2:], "0")
+		if len(subscriber) < 9 || len(subscriber) > 12 {
+			return "", ErrInvalidNumber
+		}
+		if _, ok := indonesianMobilePrefixes[subscriber[:3]]; !ok {
+			return "", ErrUnknownCarrier
+		}
+		digits = "62" + subscriber
+	}
+	return "+" + digits, nil
+}
+
+// Format returns the E.164 form of a mobile phone number, or the number as is
+// when it can't be normalized.
+func Format(number string) string {
+	if normalized, err := Normalize(number); err == nil {
+		return normalized
+	}
+	return number
+}
+
+// Carrier returns the carrier of an Indonesian mobile phone number, empty for
+// the numbers of other countries or invalid numbers.
+func Carrier(number string) string {
+	normalized, err := Normalize(number)
+	if err != nil || !strings.HasPrefix(normalized, "+62") {
+		return ""
+	}
+	return indonesianMobilePrefixes[normalized[3:6]]
+}
+
+// parse returns the digits of a number and whether it starts with its country
+// code, written with a + or 00 prefix.
+func parse(number string) (string, bool, error) {
+	number = strings.TrimSpace(number)
+	var international bool
+	if strings.HasPrefix(number, "+") {
+		number, international = number[1:], true
+	}
+
+	var digits strings.Builder
+	for _, r := range number {
+		switch {
+		case r >= '0' && r <= '9':
+			digits.WriteRune(r)
+		case r == ' ' || r == '-' || r == '.' || r == '(' || r == ')':
+		default:
+			return "", false, ErrInvalidNumber
+		}
+	}
+
+	result := digits.String()
+	if !international && strings.HasPrefix(result, "00") {
+		result, international = result[2:], true
+	}
+	if result == "" {
+		return "", false, ErrInvalidNumber
+	}
+	return result, international, nil
+}
@@ -0,0 +1,90 @@
+package phone
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name     string
+		number   string
+		expected string
+		err      error
+	}{
+		{name: "national", number: "081234567890", expected: "+6281234567890"},
+		{name: "country code", number: "6281234567890", expected: "+6281234567890"},
+		{name: "e164", number: "+6281234567890", expected: "+6281234567890"},
+		{name: "international prefix", number: "006281234567890", expected: "+6281234567890"},
+		{name: "without trunk prefix", number: "81234567890", expected: "+6281234567890"},
+		{name: "trunk prefix after country code", number: "+62081234567890", expected: "+6281234567890"},
+		{name: "dashes", number: "0812-3456-7890", expected: "+6281234567890"},
+		{name: "spaces", number: " +62 812 3456 7890 ", expected: "+6281234567890"},
+		{name: "dots and parentheses", number: "(0812) 3456.7890", expected: "+6281234567890"},
+		{name: "shortest", number: "0812345678", expected: "+62812345678"},
+		{name: "longest", number: "0812345678901", expected: "+62812345678901"},
+		{name: "other country", number: "+14155552671", expected: "+14155552671"},
+		{name: "too short", number: "081234567", err: ErrInvalidNumber},
+		{name: "too long", number: "08123456789012", err: ErrInvalidNumber},
+		{name: "other country too long", number: "+1415555267112345", err: ErrInvalidNumber},
+		{name: "landline", number: "0212345678", err: ErrUnknownCarrier},
+		{name: "toll free", number: "0800123456", err: ErrUnknownCarrier},
+		{name: "unknown mobile prefix", number: "+6286012345678", err: ErrUnknownCarrier},
+		{name: "letters", number: "0812a34567890", err: ErrInvalidNumber},
+		{name: "country code starting with 0", number: "+0812345678", err: ErrInvalidNumber},
+		{name: "plus only", number: "+", err: ErrInvalidNumber},
+		{name: "empty", number: "", err: ErrInvalidNumber},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			normalized, err := Normalize(test.number)
+			assert.ErrorIs(t, err, test.err)
+			assert.Equal(t, test.expected, normalized)
+		})
+	}
+}
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		number   string
+		expected string
+	}{
+		{number: "0812-3456-7890", expected: "+6281234567890"},
+		{number: "6281234567890", expected: "+6281234567890"},
+		{number: "+14155552671", expected: "+14155552671"},
+		{number: "0212345678", expected: "0212345678"},
+		{number: "not a number", expected: "not a number"},
+		{number: "", expected: ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.number, func(t *testing.T) {
+			assert.Equal(t, test.expected, Format(test.number))
+		})
+	}
+}
+
+func TestCarrier(t *testing.T) {
+	tests := []struct {
+		number   string
+		expected string
+	}{
+		{number: "081234567890", expected: "Telkomsel"},
+		{number: "+6285712345678", expected: "Indosat"},
+		{number: "6287712345678", expected: "XL"},
+		{number: "0838-1234-5678", expected: "Axis"},
+		{number: "0896 1234 5678", expected: "Three"},
+		{number: "0881234567890", expected: "Smartfren"},
+		{number: "+14155552671", expected: ""},
+		{number: "0212345678", expected: ""},
+		{number: "0812", expected: ""},
+		{number: "", expected: ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.number, func(t *testing.T) {
+			assert.Equal(t, test.expected, Carrier(test.number))
+		})
+	}
+}
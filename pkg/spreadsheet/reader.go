@@ -0,0 +1,171 @@
+package spreadsheet
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"github.com/xuri/excelize/v2"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+)
+
+var ErrUnsupportedFormat = errors.New("spreadsheet format is unsupported")
+
+// Reader reads the rows of a spreadsheet one at a time, io.EOF is returned
+// after the last row.
+type Reader interface {
+	Read() ([]string, error)
+	Close() error
+}
+
+// FormatFromFilename returns the format of a file from its extension.
+func FormatFromFilename(name string) string {
+	return strings.ToLower(strings.TrimPrefix(filepath.Ext(name), "."))
+}
+
+// NewReader returns the reader of a CSV file or of the first sheet of a XLSX
+// file.
+func NewReader(r io.Reader, format string) (Reader, error) {
+	switch format {
+	case FormatCSV:
+		reader := csv.NewReader(r)
+		reader.FieldsPerRecord = -1
+		reader.TrimLeadingSpace = true
+		return &csvReader{reader: reader}, nil
+	case FormatXLSX:
+		file, err := excelize.OpenReader(r)
+		if err != nil {
+			return nil, err
+		}
+		sheets := file.GetSheetList()
+		if len(sheets) == 0 {
+			_ = file.Close()
+			return nil, io.ErrUnexpectedEOF
+		}
+		rows, err := file.Rows(sheets[0])
+		if err != nil {
+			_ = file.Close()
+			return nil, err
+		}
+		return &xlsxReader{file: file, rows: rows}, nil
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+}
+
+type csvReader struct {
+	reader *csv.Reader
+	read   bool
+}
+
+func (r *csvReader) Read() ([]string, error) {
+	record, err := r.reader.Read()
+	if err != nil {
+		return nil, err
+	}
+	// spreadsheet applications prepend a byte order mark to UTF-8 files
+	if !r.read && len(record) > 0 {
+		record[0] = strings.TrimPrefix(record[0], "\ufeff")
+	}
+	r.read = true
+	return record, nil
+}
+
+func (r *csvReader) Close() error {
+	return nil
+}
+
+type xlsxReader struct {
+	file *excelize.File
+	rows *excelize.Rows
+}
+
+func (r *xlsxReader) Read() ([]string, error) {
+	if !r.rows.Next() {
+		if err := r.rows.Error(); err != nil {
+			return nil, err
+		}
+		return nil, io.EOF
+	}
+	return r.rows.Columns()
+}
+
+func (r *xlsxReader) Close() error {
+	if err := r.rows.Close(); err != nil {
+		_ = r.file.Close()
+		return err
+	}
+	return r.file.Close()
+}
+
+// MissingColumnError reports a required column absent from the header row.
+type MissingColumnError struct {
+	Column string
+}
+
+func (e *MissingColumnError) Error() string {
+	return fmt.Sprintf("column %q is missing", e.Column)
+}
+
+// Record row of a spreadsheet with its values by column name. Row is the 1-based
+// row number in the file, the header being row 1.
+type Record struct {
+	Row    int
+	Values map[string]string
+}
+
+// ReadRecords reads the rows following the header row. Header names are matched
+// case-insensitively, unknown columns are ignored and blank rows skipped. A
+// *MissingColumnError is returned when a required column isn't in the header.
+func ReadRecords(reader Reader, required ...string) ([]Record, error) {
+	header, err := reader.Read()
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	var columns = make(map[int]string, len(header))
+	for i, name := range header {
+		columns[i] = strings.ToLower(strings.TrimSpace(name))
+	}
+	for _, column := range required {
+		if !hasColumn(columns, column) {
+			return nil, &MissingColumnError{Column: column}
+		}
+	}
+
+	var records []Record
+	for row := 2; ; row++ {
+		values, err := reader.Read()
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		record := Record{Row: row, Values: make(map[string]string, len(columns))}
+		var blank = true
+		for i, value := range values {
+			if name, ok := columns[i]; ok && name != "" {
+				record.Values[name] = strings.TrimSpace(value)
+				blank = blank && record.Values[name] == ""
+			}
+		}
+		if !blank {
+			records = append(records, record)
+		}
+	}
+}
+
+func hasColumn(columns map[int]string, column string) bool {
+	for _, name := range columns {
+		if name == column {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,213 @@
+package spreadsheet
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"github.com/xuri/excelize/v2"
+	"io"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// FormatNDJSON newline delimited JSON, one object per row keyed by column.
+const FormatNDJSON = "ndjson"
+
+// formulaPrefixes first characters making spreadsheet applications read a cell
+// as a formula.
+const formulaPrefixes = "=+-@\t\r"
+
+var numberPattern = regexp.MustCompile(`^[+-]?[0-9]+(\.[0-9]+)?$`)
+
+var contentTypes = map[string]string{
+	FormatCSV:    "text/csv; charset=utf-8",
+	FormatXLSX:   "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	FormatNDJSON: "application/x-ndjson",
+}
+
+// ContentType returns the media type of a format.
+func ContentType(format string) string {
+	return contentTypes[format]
+}
+
+// Writer writes the rows of a spreadsheet one at a time. Flush sends the rows
+// written so far to the underlying writer where the format allows it, Close
+// completes the file.
+type Writer interface {
+	Write(values []interface{}) error
+	Flush() error
+	Close() error
+}
+
+// NewWriter returns the writer of a file with the given columns. CSV and XLSX
+// files start with a header row of the labels, NDJSON objects are keyed by the
+// columns.
+func NewWriter(w io.Writer, format string, columns, labels []string) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		writer := csv.NewWriter(w)
+		if err := writer.Write(labels); err != nil {
+			return nil, err
+		}
+		return &csvWriter{writer: writer}, nil
+	case FormatXLSX:
+		file := excelize.NewFile()
+		stream, err := file.NewStreamWriter(file.GetSheetName(0))
+		if err != nil {
+			_ = file.Close()
+			return nil, err
+		}
+		writer := &xlsxWriter{output: w, file: file, stream: stream}
+		var header = make([]interface{}, len(labels))
+		for i, label := range labels {
+			header[i] = label
+		}
+		if err := writer.Write(header); err != nil {
+			_ = file.Close()
+			return nil, err
+		}
+		return writer, nil
+	case FormatNDJSON:
+		return &ndjsonWriter{writer: bufio.NewWriter(w), columns: columns}, nil
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+}
+
+// cellValue dereferences pointers, nil pointers being empty cells.
+func cellValue(value interface{}) interface{} {
+	v := reflect.ValueOf(value)
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return nil
+	}
+	return v.Interface()
+}
+
+// escapeFormula prefixes with a quote the text a spreadsheet application would
+// evaluate as a formula, so an exported value can't run one. Numbers, like the
+// mobile phones in E.164 form, are left as they are.
+func escapeFormula(value string) string {
+	if value == "" || !strings.ContainsRune(formulaPrefixes, rune(value[0])) || numberPattern.MatchString(value) {
+		return value
+	}
+	return "'" + value
+}
+
+type csvWriter struct {
+	writer *csv.Writer
+}
+
+func (w *csvWriter) Write(values []interface{}) error {
+	var record = make([]string, len(values))
+	for i, value := range values {
+		switch value := cellValue(value).(type) {
+		case nil:
+		case string:
+			record[i] = escapeFormula(value)
+		case time.Time:
+			record[i] = value.Format(time.RFC3339)
+		default:
+			record[i] = fmt.Sprint(value)
+		}
+	}
+	return w.writer.Write(record)
+}
+
+func (w *csvWriter) Flush() error {
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+func (w *csvWriter) Close() error {
+	return w.Flush()
+}
+
+// xlsxWriter streams the rows to a temporary file, the workbook is written to the
+// output on Close.
+type xlsxWriter struct {
+	output io.Writer
+	file   *excelize.File
+	stream *excelize.StreamWriter
+	row    int
+}
+
+func (w *xlsxWriter) Write(values []interface{}) error {
+	w.row++
+	cell, err := excelize.CoordinatesToCellName(1, w.row)
+	if err != nil {
+		return err
+	}
+	var row = make([]interface{}, len(values))
+	for i, value := range values {
+		row[i] = cellValue(value)
+		if text, ok := row[i].(string); ok {
+			row[i] = escapeFormula(text)
+		}
+	}
+	return w.stream.SetRow(cell, row)
+}
+
+func (w *xlsxWriter) Flush() error {
+	return nil
+}
+
+func (w *xlsxWriter) Close() error {
+	defer w.file.Close()
+	if err := w.stream.Flush(); err != nil {
+		return err
+	}
+	_, err := w.file.WriteTo(w.output)
+	return err
+}
+
+type ndjsonWriter struct {
+	writer  *bufio.Writer
+	columns []string
+}
+
+func (w *ndjsonWriter) Write(values []interface{}) error {
+	if err := w.writer.WriteByte('{'); err != nil {
+		return err
+	}
+	for i, column := range w.columns {
+		if i > 0 {
+			if err := w.writer.WriteByte(','); err != nil {
+				return err
+			}
+		}
+		var value interface{}
+		if i < len(values) {
+			value = cellValue(values[i])
+		}
+		key, err := json.Marshal(column)
+		if err != nil {
+			return err
+		}
+		content, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		if _, err := w.writer.Write(append(append(key, ':'), content...)); err != nil {
+			return err
+		}
+	}
+	_, err := w.writer.WriteString("}\n")
+	return err
+}
+
+func (w *ndjsonWriter) Flush() error {
+	return w.writer.Flush()
+}
+
+func (w *ndjsonWriter) Close() error {
+	return w.Flush()
+}
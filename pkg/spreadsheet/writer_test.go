@@ -0,0 +1,36 @@
+package spreadsheet
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestWriter_EscapeFormula(t *testing.T) {
+	values := []interface{}{"=HYPERLINK(\"http://evil\")", "+SUM(A1)", "-2+3", "@cmd", "\tTAB", "+6281234567890", "-12.5", "Alice", ""}
+	expected := []string{"'=HYPERLINK(\"http://evil\")", "'+SUM(A1)", "'-2+3", "'@cmd", "'\tTAB", "+6281234567890", "-12.5", "Alice", ""}
+
+	for _, format := range []string{FormatCSV, FormatXLSX} {
+		t.Run(format, func(t *testing.T) {
+			var buffer bytes.Buffer
+			columns := make([]string, len(values))
+			writer, err := NewWriter(&buffer, format, columns, columns)
+			assert.NoError(t, err)
+			assert.NoError(t, writer.Write(values))
+			assert.NoError(t, writer.Close())
+
+			reader, err := NewReader(&buffer, format)
+			assert.NoError(t, err)
+			defer reader.Close()
+			_, err = reader.Read()
+			assert.NoError(t, err)
+			row, err := reader.Read()
+			assert.NoError(t, err)
+			// trailing empty cells aren't stored in a XLSX file
+			for len(row) < len(expected) {
+				row = append(row, "")
+			}
+			assert.Equal(t, expected, row)
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// AnyVersion version of the If-Match: * header, it matches any current version
+// of the resource.
+const AnyVersion = 0
+
+var errInvalidETag = errors.New("invalid entity tag")
+
+// BuildETag returns the strong entity tag for the given resource version.
+func BuildETag(version int) string {
+	return strconv.Quote(strconv.Itoa(version))
+}
+
+// BuildRepresentationETag returns the weak entity tag of a partial or expanded
+// representation of the given resource version. It carries the digest of the
+// representation, so the tags of different ?fields= and ?include= differ and
+// change with the included resources, which are updated without bumping the
+// version. ParseETag rejects it, only the tag of BuildETag is accepted in If-Match.
+func BuildRepresentationETag(version int, representation interface{}) (string, error) {
+	body, err := json.Marshal(representation)
+	if err != nil {
+		return "", err
+	}
+	digest := sha256.Sum256(body)
+	return "W/" + strconv.Quote(fmt.Sprintf("%d-%x", version, digest[:8])), nil
+}
+
+// ParseETag returns the resource version encoded in an entity tag built by BuildETag.
+// Weak tags (W/"1") are accepted and * is parsed as AnyVersion.
+func ParseETag(tag string) (int, error) {
+	tag = strings.TrimSpace(tag)
+	if tag == "*" {
+		return AnyVersion, nil
+	}
+	unquoted, err := strconv.Unquote(strings.TrimPrefix(tag, "W/"))
+	if err != nil {
+		return 0, err
+	}
+	version, err := strconv.Atoi(unquoted)
+	if err != nil {
+		return 0, err
+	}
+	if version < 1 {
+		return 0, errInvalidETag
+	}
+	return version, nil
+}
+
+// MatchETag reports whether the list of entity tags sent in an If-None-Match or
+// If-Match header contains etag, using weak comparison.
+func MatchETag(header, etag string) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
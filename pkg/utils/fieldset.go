@@ -0,0 +1,167 @@
+package utils
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/beego/beego/v2/server/web/context"
+)
+
+// FieldsetQuery sparse fieldset and related resources requested with
+// ?fields=id,name&include=addresses
+type FieldsetQuery struct {
+	Fields  []string `json:"fields,omitempty"`
+	Include []string `json:"include,omitempty"`
+}
+
+func (q *FieldsetQuery) SetFields(fieldsQuery string) {
+	q.Fields = splitList(fieldsQuery)
+}
+
+func (q *FieldsetQuery) SetInclude(includeQuery string) {
+	q.Include = splitList(includeQuery)
+}
+
+func (q *FieldsetQuery) GetFields() []string {
+	return q.Fields
+}
+
+func (q *FieldsetQuery) GetInclude() []string {
+	return q.Include
+}
+
+func GetFieldsetFromCtx(c *context.Context) FieldsetQuery {
+	var q FieldsetQuery
+	q.SetFields(c.Input.Query("fields"))
+	q.SetInclude(c.Input.Query("include"))
+	return q
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// FieldsetError reports a field or include outside the allowlist of a resource.
+type FieldsetError struct {
+	Param   string
+	Value   string
+	Allowed []string
+}
+
+func (e *FieldsetError) Error() string {
+	return fmt.Sprintf("%s %q is not allowed, allowed values: %s", e.Param, e.Value, strings.Join(e.Allowed, ", "))
+}
+
+// Fieldset allowlist of the fields and includes of a resource response. The
+// fields are the json names of the response struct, includes map the include
+// names to the associations preloaded for them.
+type Fieldset struct {
+	fields   []string
+	includes map[string]string
+}
+
+// NewFieldset builds the allowlist of the given response struct, the members of
+// the includes are left out of its fields.
+func NewFieldset(response interface{}, includes map[string]string) Fieldset {
+	var fields []string
+	t := reflect.TypeOf(response)
+	for i := 0; i < t.NumField(); i++ {
+		name := jsonName(t.Field(i))
+		if _, ok := includes[name]; name != "" && !ok {
+			fields = append(fields, name)
+		}
+	}
+	return Fieldset{fields: fields, includes: includes}
+}
+
+// Validate checks the requested fields and includes against the allowlist.
+func (f Fieldset) Validate(query FieldsetQuery) error {
+	for _, field := range query.Fields {
+		if !ItemExists(f.fields, field) {
+			return &FieldsetError{Param: "fields", Value: field, Allowed: f.fields}
+		}
+	}
+	for _, include := range query.Include {
+		if _, ok := f.includes[include]; !ok {
+			return &FieldsetError{Param: "include", Value: include, Allowed: f.includeNames()}
+		}
+	}
+	return nil
+}
+
+// Preloads returns the associations to preload for the requested includes.
+func (f Fieldset) Preloads(query FieldsetQuery) []string {
+	var preloads []string
+	for _, include := range query.Include {
+		if association, ok := f.includes[include]; ok {
+			preloads = append(preloads, association)
+		}
+	}
+	return preloads
+}
+
+// Select restricts response to the requested fields, included resources are
+// always kept. The response is returned unchanged when no fields are requested.
+func (f Fieldset) Select(response interface{}, query FieldsetQuery) interface{} {
+	if len(query.Fields) == 0 {
+		return response
+	}
+	v := reflect.Indirect(reflect.ValueOf(response))
+	var result = make(map[string]interface{}, len(query.Fields)+len(query.Include))
+	for i := 0; i < v.NumField(); i++ {
+		name := jsonName(v.Type().Field(i))
+		if ItemExists(query.Fields, name) || ItemExists(query.Include, name) {
+			result[name] = v.Field(i).Interface()
+		}
+	}
+	return result
+}
+
+// Fields returns the fields of the resource in the order of the response struct.
+func (f Fieldset) Fields() []string {
+	return f.fields
+}
+
+// Values returns the values of the given fields of response, in their order.
+func (f Fieldset) Values(response interface{}, fields []string) []interface{} {
+	v := reflect.Indirect(reflect.ValueOf(response))
+	var indexes = make(map[string]int, v.NumField())
+	for i := 0; i < v.NumField(); i++ {
+		indexes[jsonName(v.Type().Field(i))] = i
+	}
+	var values = make([]interface{}, len(fields))
+	for k, field := range fields {
+		if i, ok := indexes[field]; ok {
+			values[k] = v.Field(i).Interface()
+		}
+	}
+	return values
+}
+
+func (f Fieldset) includeNames() []string {
+	var names []string
+	for name := range f.includes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func jsonName(field reflect.StructField) string {
+	name := strings.Split(field.Tag.Get("json"), ",")[0]
+	if name == "-" || field.PkgPath != "" {
+		return ""
+	}
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
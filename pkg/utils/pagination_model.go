@@ -7,6 +7,8 @@ type Pagination struct {
 	CurrentPage int             `json:"current_page"`
 	HasMorePage bool            `json:"has_more_page"`
 	Links       PaginationLinks `json:"links"`
+	NextCursor  string          `json:"next_cursor,omitempty"`
+	PrevCursor  string          `json:"prev_cursor,omitempty"`
 }
 
 type PaginationLinks struct {
@@ -35,3 +37,17 @@ func BuildPaginationInfo(maxPage, total int64, pageSize, currentPage int, links
 		Links:       links,
 	}
 }
+
+// BuildCursorPaginationInfo pagination of a page read in cursor mode, total and
+// maxPage are zero unless the count was requested.
+func BuildCursorPaginationInfo(maxPage, total int64, pageSize int, nextCursor, prevCursor string, links PaginationLinks) Pagination {
+	return Pagination{
+		MaxPage:     maxPage,
+		Total:       total,
+		PageSize:    pageSize,
+		HasMorePage: nextCursor != "",
+		Links:       links,
+		NextCursor:  nextCursor,
+		PrevCursor:  prevCursor,
+	}
+}
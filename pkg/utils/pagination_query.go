@@ -2,8 +2,10 @@ package utils
 
 import (
 	"fmt"
+	"github.com/alpakih/point-of-sales/pkg/database"
 	"github.com/beego/beego/v2/server/web/context"
 	"math"
+	"net/url"
 	"strconv"
 )
 
@@ -12,10 +14,18 @@ const (
 )
 
 type PaginationQuery struct {
+	FieldsetQuery
 	Size    int    `json:"size,omitempty"`
 	Page    int    `json:"page,omitempty"`
 	OrderBy string `json:"orderBy,omitempty"`
 	Search  string `json:"search,omitempty"`
+	// Cursor opaque position of the page in cursor mode, empty for the first page
+	Cursor     string            `json:"cursor,omitempty"`
+	CursorMode bool              `json:"-"`
+	Count      bool              `json:"count,omitempty"`
+	Filters    []database.Filter `json:"-"`
+	// LinkBuilder builds the links of the other pages, nil for no links
+	LinkBuilder database.LinkBuilder `json:"-"`
 }
 
 func (q *PaginationQuery) SetSize(sizeQuery string) error {
@@ -34,7 +44,7 @@ func (q *PaginationQuery) SetSize(sizeQuery string) error {
 
 func (q *PaginationQuery) SetPage(pageQuery string) error {
 	if pageQuery == "" {
-		q.Size = 0
+		q.Page = 1
 		return nil
 	}
 	n, err := strconv.Atoi(pageQuery)
@@ -54,6 +64,42 @@ func (q *PaginationQuery) SetSearch(searchQuery string) {
 	q.Search = searchQuery
 }
 
+// SetCursor switches to cursor mode when the cursor query parameter is present,
+// even when empty.
+func (q *PaginationQuery) SetCursor(cursorQuery []string) {
+	if cursorQuery == nil {
+		return
+	}
+	q.CursorMode = true
+	if len(cursorQuery) > 0 {
+		q.Cursor = cursorQuery[0]
+	}
+}
+
+func (q *PaginationQuery) SetCount(countQuery string) error {
+	if countQuery == "" {
+		q.Count = false
+		return nil
+	}
+	count, err := strconv.ParseBool(countQuery)
+	if err != nil {
+		return err
+	}
+	q.Count = count
+
+	return nil
+}
+
+func (q *PaginationQuery) SetFilters(query url.Values) error {
+	filters, err := database.ParseFilters(query)
+	if err != nil {
+		return err
+	}
+	q.Filters = filters
+
+	return nil
+}
+
 func (q *PaginationQuery) GetOffset() int {
 	if q.Page == 0 {
 		return 0
@@ -81,6 +127,29 @@ func (q *PaginationQuery) GetSize() int {
 	return q.Size
 }
 
+func (q *PaginationQuery) GetCursor() string {
+	return q.Cursor
+}
+
+func (q *PaginationQuery) IsCursorMode() bool {
+	return q.CursorMode
+}
+
+func (q *PaginationQuery) GetCount() bool {
+	return q.Count
+}
+
+func (q *PaginationQuery) GetFilters() []database.Filter {
+	return q.Filters
+}
+
+func (q *PaginationQuery) GetLinkBuilder() database.LinkBuilder {
+	if q.LinkBuilder == nil {
+		return database.NopLinkBuilder{}
+	}
+	return q.LinkBuilder
+}
+
 func (q *PaginationQuery) GetQueryString() string {
 	return fmt.Sprintf("page=%v&size=%v&orderBy=%s&search=%s", q.GetPage(), q.GetSize(), q.GetOrderBy(), q.GetSearch())
 }
@@ -95,6 +164,15 @@ func GetPaginationFromCtx(c *context.Context) (*PaginationQuery, error) {
 	}
 	q.SetOrderBy(c.Input.Query("orderBy"))
 	q.SetSearch(c.Input.Query("search"))
+	q.SetCursor(c.Request.URL.Query()["cursor"])
+	if err := q.SetCount(c.Input.Query("count")); err != nil {
+		return nil, err
+	}
+	if err := q.SetFilters(c.Request.URL.Query()); err != nil {
+		return nil, err
+	}
+	q.LinkBuilder = database.NewURLLinkBuilder(c.Request.URL)
+	q.FieldsetQuery = GetFieldsetFromCtx(c)
 
 	return q, nil
 }
@@ -5,6 +5,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/alpakih/point-of-sales/pkg/phone"
 	validatorGo "github.com/go-playground/validator/v10"
 )
 
@@ -55,10 +56,12 @@ func ValidateEmail(fl validatorGo.FieldLevel) bool {
 	return true
 }
 
+// ValidateMobilePhone accepts the mobile phones phone.Normalize converts to E.164,
+// Indonesian numbers must have the prefix of a mobile carrier.
 func ValidateMobilePhone(fl validatorGo.FieldLevel) bool {
 	if fl.Field().String() != "" {
-		regex := regexp.MustCompile(`^08\d{7,12}$`)
-		return regex.MatchString(fl.Field().String())
+		_, err := phone.Normalize(fl.Field().String())
+		return err == nil
 	}
 	return true
 }
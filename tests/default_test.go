@@ -6,7 +6,6 @@ import (
 	"github.com/alpakih/point-of-sales/internal/customer"
 	cHandler "github.com/alpakih/point-of-sales/internal/customer/delivery/http"
 	"github.com/alpakih/point-of-sales/internal/customer/mocks"
-	"github.com/beego/i18n"
 	. "github.com/smartystreets/goconvey/convey"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -34,7 +33,7 @@ func TestCustomerStore(t *testing.T) {
 	mockDataCustomer := customer.StoreRequest{
 		Name:        "Test",
 		Email:       "email@test.com",
-		MobilePhone: "087666777656",
+		MobilePhone: "087766777656",
 		Password:    "123123",
 	}
 
@@ -57,7 +56,6 @@ func TestCustomerStore(t *testing.T) {
 		h := beego.NewControllerRegister()
 
 		handler := &cHandler.CustomerHandler{
-			Locale:          i18n.Locale{Lang: "id"},
 			CustomerUseCase: mockUCase,
 		}
 